@@ -6,10 +6,15 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
+	"maps"
 	"net/http"
+	"packer/internal/rest"
 	"packer/internal/rest/order"
 	"packer/internal/rest/order/pack"
 	"slices"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 )
@@ -18,6 +23,8 @@ const (
 	url             = "http://localhost:8080"
 	ordersUrl       = url + "/orders"
 	ordersConfigUrl = ordersUrl + "/config"
+	productsUrl     = url + "/products"
+	inventoryUrl    = url + "/inventory"
 )
 
 func TestValidSetConfigAndCreateOrders(t *testing.T) {
@@ -87,9 +94,789 @@ func TestValidSetConfigAndCreateOrders(t *testing.T) {
 	}
 }
 
+func TestCreateAndGetOrder(t *testing.T) {
+	httpClient := newHttpClient()
+
+	setConfigResp := doValidSetConfig(t, &httpClient, []int{250, 500})
+	assertValidSetConfig(t, setConfigResp, []int{250, 500})
+
+	createOrderResp := doValidCreateOrder(t, &httpClient, 501)
+	createdOrder := decodeOrder(t, createOrderResp)
+
+	req := newGetRequest(t, fmt.Sprintf("%s/%d", ordersUrl, createdOrder.ID))
+	getOrderResp, err := httpClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	foundOrder := decodeOrder(t, getOrderResp)
+
+	if foundOrder.ID != createdOrder.ID || foundOrder.Size != 501 || !foundOrder.CreatedAt.Equal(createdOrder.CreatedAt) {
+		t.Errorf("unexpected order: got '%+v' want '%+v'", foundOrder, createdOrder)
+	}
+	if !slices.Equal(foundOrder.Packs, createdOrder.Packs) {
+		t.Errorf("unexpected packs: got '%+v' want '%+v'", foundOrder.Packs, createdOrder.Packs)
+	}
+	if !slices.Equal(foundOrder.PackSizes, []int{250, 500}) {
+		t.Errorf("unexpected pack sizes: got '%+v' want '%+v'", foundOrder.PackSizes, []int{250, 500})
+	}
+}
+
+func TestListOrders_Pagination(t *testing.T) {
+	httpClient := newHttpClient()
+
+	setConfigResp := doValidSetConfig(t, &httpClient, []int{250, 500})
+	assertValidSetConfig(t, setConfigResp, []int{250, 500})
+
+	// a size range that no other test uses, so that only these orders are listed
+	orderSizes := []int{777001, 777002, 777003}
+	for _, size := range orderSizes {
+		decodeOrder(t, doValidCreateOrder(t, &httpClient, size))
+	}
+
+	var listedSizes []int
+	query := "?min_size=777001&max_size=777003&limit=2"
+	for {
+		page := doListOrders(t, &httpClient, query)
+		for _, o := range page.Orders {
+			listedSizes = append(listedSizes, o.Size)
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		query = "?min_size=777001&max_size=777003&limit=2&cursor=" + page.NextCursor
+	}
+
+	// newest first, and previous runs may have created older orders with the same sizes
+	slices.Reverse(orderSizes)
+	if len(listedSizes) < len(orderSizes) || !slices.Equal(listedSizes[:len(orderSizes)], orderSizes) {
+		t.Errorf("unexpected listed order sizes: got '%+v' want '%+v'", listedSizes, orderSizes)
+	}
+}
+
+func TestGetOrder_NotFound(t *testing.T) {
+	httpClient := newHttpClient()
+	req := newGetRequest(t, ordersUrl+"/9223372036854775807")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("unexpected status code: got '%d' want '%d'", resp.StatusCode, http.StatusNotFound)
+	}
+}
+
+func TestGetConfig(t *testing.T) {
+	httpClient := newHttpClient()
+
+	setConfigResp := doValidSetConfig(t, &httpClient, []int{250, 500, 1000})
+	assertValidSetConfig(t, setConfigResp, []int{250, 500, 1000})
+
+	resp, err := httpClient.Do(newGetRequest(t, ordersConfigUrl))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := decodeVersionedConfig(t, resp)
+	slices.Sort(cfg.PackSizes)
+	if !slices.Equal(cfg.PackSizes, []int{250, 500, 1000}) {
+		t.Errorf("unexpected pack sizes: got '%+v' want '%+v'", cfg.PackSizes, []int{250, 500, 1000})
+	}
+
+	etag := resp.Header.Get("ETag")
+	if etag != fmt.Sprintf(`"%d"`, cfg.Version) {
+		t.Errorf("unexpected etag: got '%s' want '\"%d\"'", etag, cfg.Version)
+	}
+
+	req := newGetRequest(t, ordersConfigUrl)
+	req.Header.Set("If-None-Match", etag)
+	notModifiedResp, err := httpClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer notModifiedResp.Body.Close()
+
+	if notModifiedResp.StatusCode != http.StatusNotModified {
+		t.Errorf("unexpected status code: got '%d' want '%d'", notModifiedResp.StatusCode, http.StatusNotModified)
+	}
+}
+
+func TestSetConfig_PreconditionRequired(t *testing.T) {
+	httpClient := newHttpClient()
+	req := newPutRequest(t, ordersConfigUrl, []byte(`{"pack_sizes": [250]}`))
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusPreconditionRequired {
+		t.Errorf("unexpected status code: got '%d' want '%d'", resp.StatusCode, http.StatusPreconditionRequired)
+	}
+}
+
+func TestSetConfig_ConcurrentWriters(t *testing.T) {
+	httpClient := newHttpClient()
+	etag := doGetConfigETag(t, &httpClient)
+
+	const writers = 10
+	statuses := make(chan int, writers)
+	errs := make(chan error, writers)
+	var wg sync.WaitGroup
+	for i := 0; i < writers; i++ {
+		req := newSetConfigRequestWithConfigAndIfMatch(t, order.Config{PackSizes: []int{250, 500 + i}}, etag)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := httpClient.Do(req)
+			if err != nil {
+				errs <- err
+				return
+			}
+			resp.Body.Close()
+			statuses <- resp.StatusCode
+		}()
+	}
+	wg.Wait()
+	close(statuses)
+	close(errs)
+
+	for err := range errs {
+		t.Fatal(err)
+	}
+
+	countByStatus := make(map[int]int)
+	for status := range statuses {
+		countByStatus[status]++
+	}
+	if countByStatus[http.StatusOK] != 1 || countByStatus[http.StatusPreconditionFailed] != writers-1 {
+		t.Errorf("unexpected statuses: got '%+v' want one '%d' and the rest '%d'",
+			countByStatus, http.StatusOK, http.StatusPreconditionFailed)
+	}
+}
+
+func TestConfigVersionsAndRestore(t *testing.T) {
+	httpClient := newHttpClient()
+
+	setConfigResp := doValidSetConfig(t, &httpClient, []int{250, 500})
+	previousCfg := decodeVersionedConfig(t, setConfigResp)
+
+	setConfigResp = doValidSetConfig(t, &httpClient, []int{300, 600})
+	currentCfg := decodeVersionedConfig(t, setConfigResp)
+
+	resp, err := httpClient.Do(newGetRequest(t, ordersConfigUrl+"/versions"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	versions := decodeConfigVersions(t, resp)
+	if len(versions.Versions) < 2 || versions.Versions[0].Version != currentCfg.Version ||
+		versions.Versions[1].Version != previousCfg.Version {
+		t.Fatalf("unexpected config versions: got '%+v'", versions.Versions)
+	}
+
+	restoreUrl := fmt.Sprintf("%s/versions/%d/restore", ordersConfigUrl, previousCfg.Version)
+	req := newPostRequest(t, restoreUrl, []byte(`{"author": "integration test"}`))
+	req.Header.Set("If-Match", fmt.Sprintf(`"%d"`, currentCfg.Version))
+	resp, err = httpClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	restoredCfg := decodeVersionedConfig(t, resp)
+
+	slices.Sort(restoredCfg.PackSizes)
+	if restoredCfg.Version != currentCfg.Version+1 || !slices.Equal(restoredCfg.PackSizes, []int{250, 500}) {
+		t.Errorf("unexpected restored config: got '%+v'", restoredCfg)
+	}
+
+	createdOrder := decodeOrder(t, doValidCreateOrder(t, &httpClient, 1))
+	if createdOrder.ConfigVersion != restoredCfg.Version {
+		t.Errorf("unexpected order config version: got '%d' want '%d'", createdOrder.ConfigVersion, restoredCfg.Version)
+	}
+}
+
+func TestScheduledConfigAndQuote(t *testing.T) {
+	httpClient := newHttpClient()
+
+	setConfigResp := doValidSetConfig(t, &httpClient, []int{250, 500})
+	currentCfg := decodeVersionedConfig(t, setConfigResp)
+
+	effectiveFrom := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+	cfg := order.Config{
+		PackSizes:     []int{300},
+		EffectiveFrom: &effectiveFrom,
+	}
+	resp, err := httpClient.Do(newSetConfigRequestWithConfigAndIfMatch(t, cfg, doGetConfigETag(t, &httpClient)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	scheduledCfg := decodeVersionedConfig(t, resp)
+	if !scheduledCfg.EffectiveFrom.Equal(effectiveFrom) {
+		t.Errorf("unexpected effective from: got '%s' want '%s'", scheduledCfg.EffectiveFrom, effectiveFrom)
+	}
+
+	// the current config stays in effect until the scheduled one takes effect
+	resp, err = httpClient.Do(newGetRequest(t, ordersConfigUrl))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertHeader(t, resp, "ETag", fmt.Sprintf(`"%d-%d"`, scheduledCfg.Version, currentCfg.Version))
+	if foundCfg := decodeVersionedConfig(t, resp); foundCfg.Version != currentCfg.Version {
+		t.Errorf("unexpected config version in effect: got '%d' want '%d'", foundCfg.Version, currentCfg.Version)
+	}
+
+	asOf := effectiveFrom.Add(time.Minute)
+	jsonBytes, err := json.Marshal(order.Request{Size: 1, AsOf: &asOf})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err = httpClient.Do(newPostRequest(t, ordersUrl, jsonBytes))
+	if err != nil {
+		t.Fatal(err)
+	}
+	quote := decodeOrder(t, resp)
+	if quote.ID != 0 || quote.ConfigVersion != scheduledCfg.Version || !slices.Equal(quote.PackSizes, []int{300}) {
+		t.Errorf("unexpected quote: got '%+v'", quote)
+	}
+}
+
+func TestProducts(t *testing.T) {
+	httpClient := newHttpClient()
+	sku := fmt.Sprintf("tea-%d", time.Now().UnixNano())
+
+	createdProduct := doCreateProduct(t, &httpClient, sku, []int{10, 50})
+	if createdProduct.SKU != sku || createdProduct.Name != "Tea" {
+		t.Errorf("unexpected product: got '%+v'", createdProduct)
+	}
+
+	resp, err := httpClient.Do(newGetRequest(t, productsUrl+"/"+sku+"/config"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := decodeVersionedConfig(t, resp)
+	slices.Sort(cfg.PackSizes)
+	if cfg.Version != 1 || !slices.Equal(cfg.PackSizes, []int{10, 50}) {
+		t.Errorf("unexpected product config: got '%+v'", cfg)
+	}
+
+	jsonBytes, err := json.Marshal(order.Request{Product: sku, Size: 61})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err = httpClient.Do(newPostRequest(t, ordersUrl, jsonBytes))
+	if err != nil {
+		t.Fatal(err)
+	}
+	createdOrder := decodeOrder(t, resp)
+	expectedPacks := []pack.Pack{{Size: 50, Quantity: 1}, {Size: 10, Quantity: 2}}
+	if createdOrder.Product != sku || createdOrder.ConfigVersion != 1 || !slices.Equal(createdOrder.Packs, expectedPacks) {
+		t.Errorf("unexpected order: got '%+v'", createdOrder)
+	}
+
+	// ordered products are kept
+	resp, err = httpClient.Do(newDeleteRequest(t, productsUrl+"/"+sku))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusConflict {
+		t.Errorf("unexpected status code: got '%d' want '%d'", resp.StatusCode, http.StatusConflict)
+	}
+
+	unorderedSku := sku + "-unordered"
+	doCreateProduct(t, &httpClient, unorderedSku, []int{10})
+	resp, err = httpClient.Do(newDeleteRequest(t, productsUrl+"/"+unorderedSku))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent {
+		t.Errorf("unexpected status code: got '%d' want '%d'", resp.StatusCode, http.StatusNoContent)
+	}
+}
+
+func TestCreateLinesOrder(t *testing.T) {
+	httpClient := newHttpClient()
+	sku := fmt.Sprintf("coffee-%d", time.Now().UnixNano())
+	doCreateProduct(t, &httpClient, sku, []int{10, 50})
+
+	setConfigResp := doValidSetConfig(t, &httpClient, []int{250, 500})
+	assertValidSetConfig(t, setConfigResp, []int{250, 500})
+
+	jsonBytes, err := json.Marshal(order.Request{
+		Lines: []order.LineRequest{{Product: sku, Quantity: 61}, {Quantity: 251}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := httpClient.Do(newPostRequest(t, ordersUrl, jsonBytes))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("unexpected status code: got '%d' want '%d'", resp.StatusCode, http.StatusOK)
+	}
+
+	var linesOrder order.LinesOrder
+	if err = json.NewDecoder(resp.Body).Decode(&linesOrder); err != nil {
+		t.Fatal(err)
+	}
+
+	expectedTotals := order.Totals{ItemsShipped: 570, Overshoot: 258, PackCount: 4}
+	if len(linesOrder.Lines) != 2 || linesOrder.Totals != expectedTotals {
+		t.Fatalf("unexpected order: got '%+v'", linesOrder)
+	}
+
+	for _, line := range linesOrder.Lines {
+		resp, err = httpClient.Do(newGetRequest(t, fmt.Sprintf("%s/%d", ordersUrl, line.ID)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		foundOrder := decodeOrder(t, resp)
+		if foundOrder.Product != line.Product || foundOrder.Size != line.Quantity {
+			t.Errorf("unexpected line order: got '%+v' want '%+v'", foundOrder, line)
+		}
+	}
+}
+
+func TestQuoteBatch(t *testing.T) {
+	httpClient := newHttpClient()
+	sku := fmt.Sprintf("sugar-%d", time.Now().UnixNano())
+	doCreateProduct(t, &httpClient, sku, []int{10, 50})
+
+	req := newPostRequest(t, ordersUrl+"/batch?product="+sku, []byte("61\n0\n100\n"))
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("unexpected status code: got '%d' want '%d'", resp.StatusCode, http.StatusOK)
+	}
+
+	var items []order.BatchItem
+	decoder := json.NewDecoder(resp.Body)
+	for decoder.More() {
+		var item order.BatchItem
+		if err = decoder.Decode(&item); err != nil {
+			t.Fatal(err)
+		}
+		items = append(items, item)
+	}
+
+	if len(items) != 3 {
+		t.Fatalf("unexpected number of items: got '%d' want '%d'", len(items), 3)
+	}
+	if items[0].Index != 0 || items[0].Totals == nil || items[0].Totals.ItemsShipped != 70 {
+		t.Errorf("unexpected first item: got '%+v'", items[0])
+	}
+	if items[1].Index != 1 || items[1].Code != "invalid_order_size" {
+		t.Errorf("unexpected second item: got '%+v'", items[1])
+	}
+	if items[2].Index != 2 || items[2].Totals == nil || items[2].Totals.PackCount != 2 {
+		t.Errorf("unexpected third item: got '%+v'", items[2])
+	}
+}
+
+func TestInventory(t *testing.T) {
+	httpClient := newHttpClient()
+	sku := fmt.Sprintf("flour-%d", time.Now().UnixNano())
+	doCreateProduct(t, &httpClient, sku, []int{10, 50})
+
+	doSetStock := func(stock string) {
+		resp, err := httpClient.Do(newPutRequest(t, inventoryUrl+"/"+sku, []byte(`{"stock": `+stock+`}`)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("unexpected status code: got '%d' want '%d'", resp.StatusCode, http.StatusOK)
+		}
+	}
+	doCreateOrder := func() *http.Response {
+		resp, err := httpClient.Do(newPostRequest(t, ordersUrl, []byte(`{"product": "`+sku+`", "size": 100}`)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp
+	}
+
+	doSetStock(`[{"pack_size": 50, "quantity": 1}]`)
+	createdOrder := decodeOrder(t, doCreateOrder())
+	expectedPacks := []pack.Pack{{Size: 50, Quantity: 1}, {Size: 10, Quantity: 5}}
+	if !slices.Equal(createdOrder.Packs, expectedPacks) {
+		t.Errorf("unexpected packs: got '%+v' want '%+v'", createdOrder.Packs, expectedPacks)
+	}
+	doOrderAction(t, &httpClient, createdOrder.ID, "confirm", http.StatusOK)
+
+	doSetStock(`[{"pack_size": 10, "quantity": 9}]`)
+	resp := doCreateOrder()
+	defer resp.Body.Close()
+	var errResp order.ErrorResponse
+	if err := json.NewDecoder(resp.Body).Decode(&errResp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusUnprocessableEntity || errResp.Code != "insufficient_stock" {
+		t.Errorf("unexpected response: got '%d' '%+v'", resp.StatusCode, errResp)
+	}
+
+	resp, err := httpClient.Do(newDeleteRequest(t, inventoryUrl+"/"+sku+"/50"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent {
+		t.Fatalf("unexpected status code: got '%d' want '%d'", resp.StatusCode, http.StatusNoContent)
+	}
+	createdOrder = decodeOrder(t, doCreateOrder())
+	expectedPacks = []pack.Pack{{Size: 50, Quantity: 2}}
+	if !slices.Equal(createdOrder.Packs, expectedPacks) {
+		t.Errorf("unexpected packs: got '%+v' want '%+v'", createdOrder.Packs, expectedPacks)
+	}
+}
+
+func TestReservations(t *testing.T) {
+	httpClient := newHttpClient()
+	sku := fmt.Sprintf("rice-%d", time.Now().UnixNano())
+	doCreateProduct(t, &httpClient, sku, []int{10})
+
+	resp, err := httpClient.Do(newPutRequest(t, inventoryUrl+"/"+sku, []byte(`{"stock": [{"pack_size": 10, "quantity": 3}]}`)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	doCreateOrder := func() *http.Response {
+		resp, err := httpClient.Do(newPostRequest(t, ordersUrl, []byte(`{"product": "`+sku+`", "size": 20}`)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp
+	}
+	doGetStockLevel := func() order.StockLevel {
+		resp, err := httpClient.Do(newGetRequest(t, inventoryUrl+"/"+sku))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		var inventory order.Inventory
+		if err = json.NewDecoder(resp.Body).Decode(&inventory); err != nil {
+			t.Fatal(err)
+		}
+		if len(inventory.Stock) != 1 {
+			t.Fatalf("unexpected stock: got '%+v'", inventory.Stock)
+		}
+		return inventory.Stock[0]
+	}
+
+	quote := decodeOrder(t, doCreateOrder())
+	if quote.Status != "quoted" || quote.ExpiresAt == nil {
+		t.Errorf("unexpected quote: got '%+v'", quote)
+	}
+	if level := doGetStockLevel(); level.Quantity != 3 || level.Reserved != 2 {
+		t.Errorf("unexpected stock level: got '%+v' want quantity 3 and reserved 2", level)
+	}
+
+	resp = doCreateOrder()
+	var errResp order.ErrorResponse
+	if err = json.NewDecoder(resp.Body).Decode(&errResp); err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnprocessableEntity || errResp.Code != "insufficient_stock" {
+		t.Errorf("unexpected response: got '%d' '%+v'", resp.StatusCode, errResp)
+	}
+
+	doOrderAction(t, &httpClient, quote.ID, "cancel", http.StatusOK)
+	doOrderAction(t, &httpClient, quote.ID, "cancel", http.StatusConflict)
+
+	quote = decodeOrder(t, doCreateOrder())
+	confirmed := doOrderAction(t, &httpClient, quote.ID, "confirm", http.StatusOK)
+	if confirmed.Status != "confirmed" {
+		t.Errorf("unexpected status: got '%s' want '%s'", confirmed.Status, "confirmed")
+	}
+	if level := doGetStockLevel(); level.Quantity != 1 || level.Reserved != 0 {
+		t.Errorf("unexpected stock level: got '%+v' want quantity 1 and reserved 0", level)
+	}
+}
+
+func TestCostObjective(t *testing.T) {
+	httpClient := newHttpClient()
+
+	cfg := order.Config{
+		PackSizes:   []int{250, 500, 1000, 2000, 5000},
+		PackCosts:   map[int]int{250: 300, 500: 150, 1000: 250, 2000: 450, 5000: 1000},
+		HandlingFee: 500,
+	}
+	resp, err := httpClient.Do(newSetConfigRequestWithConfig(t, cfg))
+	if err != nil {
+		t.Fatal(err)
+	}
+	savedCfg := decodeVersionedConfig(t, resp)
+	if !maps.Equal(savedCfg.PackCosts, cfg.PackCosts) || savedCfg.HandlingFee != cfg.HandlingFee {
+		t.Fatalf("unexpected config: got '%+v' want '%+v'", savedCfg, cfg)
+	}
+
+	jsonBytes, err := json.Marshal(order.Request{Size: 12001, Objective: "cost"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err = httpClient.Do(newPostRequest(t, ordersUrl, jsonBytes))
+	if err != nil {
+		t.Fatal(err)
+	}
+	createdOrder := decodeOrder(t, resp)
+
+	resp, err = httpClient.Do(newGetRequest(t, fmt.Sprintf("%s/%d", ordersUrl, createdOrder.ID)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	foundOrder := decodeOrder(t, resp)
+
+	expectedPacks := []pack.Pack{{Size: 5000, Quantity: 2}, {Size: 2000, Quantity: 1}, {Size: 500, Quantity: 1}}
+	if !slices.Equal(foundOrder.Packs, expectedPacks) {
+		t.Errorf("unexpected packs: got '%+v' want '%+v'", foundOrder.Packs, expectedPacks)
+	}
+	if foundOrder.Objective != "cost" || foundOrder.Cost == nil || *foundOrder.Cost != 3100 {
+		t.Errorf("unexpected order objective and cost: got '%+v'", foundOrder)
+	}
+
+	doOrderAction(t, &httpClient, createdOrder.ID, "cancel", http.StatusOK)
+}
+
+func TestBranchAndBoundSolver(t *testing.T) {
+	httpClient := newHttpClient()
+
+	resp, err := httpClient.Do(newSetConfigRequestWithConfig(t, order.Config{PackSizes: []int{250, 500, 1000, 2000, 5000}}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	decodeVersionedConfig(t, resp)
+
+	jsonBytes, err := json.Marshal(order.Request{Size: 12001})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err = httpClient.Do(newPostRequest(t, ordersUrl+"?solver=branch_and_bound", jsonBytes))
+	if err != nil {
+		t.Fatal(err)
+	}
+	createdOrder := decodeOrder(t, resp)
+
+	resp, err = httpClient.Do(newGetRequest(t, fmt.Sprintf("%s/%d", ordersUrl, createdOrder.ID)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	foundOrder := decodeOrder(t, resp)
+
+	expectedPacks := []pack.Pack{{Size: 5000, Quantity: 2}, {Size: 2000, Quantity: 1}, {Size: 250, Quantity: 1}}
+	if !slices.Equal(foundOrder.Packs, expectedPacks) {
+		t.Errorf("unexpected packs: got '%+v' want '%+v'", foundOrder.Packs, expectedPacks)
+	}
+	if foundOrder.Solver != pack.SolverBranchAndBound {
+		t.Errorf("unexpected order solver: got '%s' want '%s'", foundOrder.Solver, pack.SolverBranchAndBound)
+	}
+
+	doOrderAction(t, &httpClient, createdOrder.ID, "cancel", http.StatusOK)
+}
+
+func TestExplain(t *testing.T) {
+	httpClient := newHttpClient()
+
+	resp, err := httpClient.Do(newSetConfigRequestWithConfig(t, order.Config{PackSizes: []int{250, 500, 1000, 2000, 5000}}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	decodeVersionedConfig(t, resp)
+
+	jsonBytes, err := json.Marshal(order.Request{Size: 251})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err = httpClient.Do(newPostRequest(t, ordersUrl+"?explain=true", jsonBytes))
+	if err != nil {
+		t.Fatal(err)
+	}
+	createdOrder := decodeOrder(t, resp)
+
+	if createdOrder.Explanation == nil || createdOrder.Explanation.Overshoot != 249 ||
+		len(createdOrder.Explanation.RunnersUp) == 0 {
+		t.Fatalf("unexpected explanation: got '%+v'", createdOrder.Explanation)
+	}
+	runnerUp := createdOrder.Explanation.RunnersUp[0]
+	expectedPacks := []pack.Pack{{Size: 250, Quantity: 2}}
+	if !slices.Equal(runnerUp.Packs, expectedPacks) || runnerUp.Rule != pack.RuleFewestPacks {
+		t.Errorf("unexpected runner-up: got '%+v' want packs '%+v' eliminated by rule '%d'", runnerUp, expectedPacks,
+			pack.RuleFewestPacks)
+	}
+
+	doOrderAction(t, &httpClient, createdOrder.ID, "cancel", http.StatusOK)
+}
+
+func TestTies(t *testing.T) {
+	httpClient := newHttpClient()
+
+	resp, err := httpClient.Do(newSetConfigRequestWithConfig(t, order.Config{PackSizes: []int{2, 3, 4}}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	decodeVersionedConfig(t, resp)
+
+	jsonBytes, err := json.Marshal(order.Request{Size: 6})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err = httpClient.Do(newPostRequest(t, ordersUrl+"?ties=10&tie_breaker=fewer_sizes", jsonBytes))
+	if err != nil {
+		t.Fatal(err)
+	}
+	createdOrder := decodeOrder(t, resp)
+
+	expectedPacks := []pack.Pack{{Size: 3, Quantity: 2}}
+	if !slices.Equal(createdOrder.Packs, expectedPacks) {
+		t.Errorf("unexpected packs: got '%+v' want '%+v'", createdOrder.Packs, expectedPacks)
+	}
+	if len(createdOrder.Ties) != 2 || !slices.Equal(createdOrder.Ties[0], expectedPacks) {
+		t.Errorf("unexpected ties: got '%+v'", createdOrder.Ties)
+	}
+
+	doOrderAction(t, &httpClient, createdOrder.ID, "cancel", http.StatusOK)
+}
+
+func TestSortPacks(t *testing.T) {
+	httpClient := newHttpClient()
+
+	resp, err := httpClient.Do(newSetConfigRequestWithConfig(t, order.Config{PackSizes: []int{250, 500, 1000, 2000, 5000}}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	decodeVersionedConfig(t, resp)
+
+	jsonBytes, err := json.Marshal(order.Request{Size: 12001})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err = httpClient.Do(newPostRequest(t, ordersUrl+"?sort=size_asc", jsonBytes))
+	if err != nil {
+		t.Fatal(err)
+	}
+	createdOrder := decodeOrder(t, resp)
+
+	expectedPacks := []pack.Pack{{Size: 250, Quantity: 1}, {Size: 2000, Quantity: 1}, {Size: 5000, Quantity: 2}}
+	if !slices.Equal(createdOrder.Packs, expectedPacks) {
+		t.Errorf("unexpected packs: got '%+v' want '%+v'", createdOrder.Packs, expectedPacks)
+	}
+
+	doOrderAction(t, &httpClient, createdOrder.ID, "cancel", http.StatusOK)
+}
+
+func TestCache(t *testing.T) {
+	httpClient := newHttpClient()
+	doValidSetConfig(t, &httpClient, []int{23, 31, 53})
+
+	before := doGetCacheStats(t, &httpClient)
+	for i := 0; i < 2; i++ {
+		resp := doValidCreateOrder(t, &httpClient, 500000)
+		createdOrder := decodeOrder(t, resp)
+		expectedPacks := []pack.Pack{{Size: 53, Quantity: 9429}, {Size: 31, Quantity: 7}, {Size: 23, Quantity: 2}}
+		if !slices.Equal(createdOrder.Packs, expectedPacks) {
+			t.Errorf("unexpected packs: got '%+v' want '%+v'", createdOrder.Packs, expectedPacks)
+		}
+		doOrderAction(t, &httpClient, createdOrder.ID, "cancel", http.StatusOK)
+	}
+
+	after := doGetCacheStats(t, &httpClient)
+	if after.Tables.Hits <= before.Tables.Hits {
+		t.Errorf("unexpected solution table hits: got '%d' after '%d'", after.Tables.Hits, before.Tables.Hits)
+	}
+}
+
+func TestHealth(t *testing.T) {
+	httpClient := newHttpClient()
+
+	for _, path := range []string{"/healthz", "/readyz"} {
+		resp, err := httpClient.Do(newGetRequest(t, url+path))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var health rest.Health
+		if err = json.NewDecoder(resp.Body).Decode(&health); err != nil {
+			t.Fatal(err)
+		}
+		_ = resp.Body.Close()
+		if resp.StatusCode != http.StatusOK || health.Status != "ok" {
+			t.Errorf("unexpected health of %s: got '%d' '%+v'", path, resp.StatusCode, health)
+		}
+	}
+}
+
+func TestRequestID(t *testing.T) {
+	httpClient := newHttpClient()
+	req := newPostRequest(t, ordersUrl, []byte(`{"size": 0}`))
+	req.Header.Set("X-Request-ID", "integration-test")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if requestID := resp.Header.Get("X-Request-ID"); requestID != "integration-test" {
+		t.Errorf("unexpected request ID: got '%s' want '%s'", requestID, "integration-test")
+	}
+	var errResp order.ErrorResponse
+	if err = json.NewDecoder(resp.Body).Decode(&errResp); err != nil {
+		t.Fatal(err)
+	}
+	if errResp.RequestID != "integration-test" {
+		t.Errorf("unexpected request ID of the error: got '%s' want '%s'", errResp.RequestID, "integration-test")
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	httpClient := newHttpClient()
+	configResp, err := httpClient.Do(newGetRequest(t, ordersConfigUrl))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = configResp.Body.Close()
+
+	resp, err := httpClient.Do(newGetRequest(t, url+"/metrics"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, metric := range []string{
+		`packer_http_requests_total{method="GET",route="/orders",status="200"}`,
+		`packer_db_query_duration_seconds_count{outcome="ok",query="find_config"}`,
+		"packer_max_open_connections",
+		"go_goroutines",
+	} {
+		if !strings.Contains(string(body), metric) {
+			t.Errorf("unexpected metrics without '%s'", metric)
+		}
+	}
+}
+
 func TestSetConfig_InvalidPayload(t *testing.T) {
 	httpClient := newHttpClient()
 	req := newPutRequest(t, ordersConfigUrl, []byte("{pack_sizes}"))
+	req.Header.Set("If-Match", "*")
 
 	resp, err := httpClient.Do(req)
 	if err != nil {
@@ -205,6 +992,22 @@ func newPostRequest(t *testing.T, url string, payload []byte) *http.Request {
 	return req
 }
 
+func newGetRequest(t *testing.T, url string) *http.Request {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return req
+}
+
+func newDeleteRequest(t *testing.T, url string) *http.Request {
+	req, err := http.NewRequest(http.MethodDelete, url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return req
+}
+
 func newOptionsRequest(t *testing.T, url string) *http.Request {
 	req, err := http.NewRequest(http.MethodOptions, url, nil)
 	if err != nil {
@@ -214,12 +1017,18 @@ func newOptionsRequest(t *testing.T, url string) *http.Request {
 }
 
 func newSetConfigRequestWithConfig(t *testing.T, cfg order.Config) *http.Request {
+	return newSetConfigRequestWithConfigAndIfMatch(t, cfg, "*")
+}
+
+func newSetConfigRequestWithConfigAndIfMatch(t *testing.T, cfg order.Config, ifMatch string) *http.Request {
 	jsonBytes, err := json.Marshal(cfg)
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	return newPutRequest(t, ordersConfigUrl, jsonBytes)
+	req := newPutRequest(t, ordersConfigUrl, jsonBytes)
+	req.Header.Set("If-Match", ifMatch)
+	return req
 }
 
 func newCreateOrderRequestWithSize(t *testing.T, orderSize int) *http.Request {
@@ -238,7 +1047,7 @@ func doValidSetConfig(t *testing.T, httpClient *http.Client, packSizes []int) *h
 	cfg := order.Config{
 		PackSizes: packSizes,
 	}
-	req := newSetConfigRequestWithConfig(t, cfg)
+	req := newSetConfigRequestWithConfigAndIfMatch(t, cfg, doGetConfigETag(t, httpClient))
 
 	resp, err := httpClient.Do(req)
 	if err != nil {
@@ -265,6 +1074,17 @@ func assertValidSetConfig(t *testing.T, resp *http.Response, packSizes []int) {
 	assertHeader(t, resp, "Content-Type", "application/json")
 }
 
+func doGetConfigETag(t *testing.T, httpClient *http.Client) string {
+	resp, err := httpClient.Do(newGetRequest(t, ordersConfigUrl))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer resp.Body.Close()
+
+	return resp.Header.Get("ETag")
+}
+
 func doValidCreateOrder(t *testing.T, httpClient *http.Client, orderSize int) *http.Response {
 	req := newCreateOrderRequestWithSize(t, orderSize)
 	resp, err := httpClient.Do(req)
@@ -282,13 +1102,132 @@ func assertValidCreateOrder(t *testing.T, resp *http.Response, expectedPacks []p
 		t.Fatal(err)
 	}
 
-	if !pack.EqualSlice(orderResp.Packs, expectedPacks) {
+	if !slices.Equal(orderResp.Packs, expectedPacks) {
 		t.Errorf("unexpected packs: got '%+v' want '%+v'", orderResp.Packs, expectedPacks)
 	}
 
 	assertHeader(t, resp, "Content-Type", "application/json")
 }
 
+func doListOrders(t *testing.T, httpClient *http.Client, query string) order.OrderPage {
+	resp, err := httpClient.Do(newGetRequest(t, ordersUrl+query))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("unexpected status code: got '%d' want '%d'", resp.StatusCode, http.StatusOK)
+	}
+
+	var page order.OrderPage
+	if err = json.NewDecoder(resp.Body).Decode(&page); err != nil {
+		t.Fatal(err)
+	}
+	return page
+}
+
+func doGetCacheStats(t *testing.T, httpClient *http.Client) order.CacheStats {
+	resp, err := httpClient.Do(newGetRequest(t, ordersUrl+"/cache"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("unexpected status code: got '%d' want '%d'", resp.StatusCode, http.StatusOK)
+	}
+	var stats order.CacheStats
+	if err = json.NewDecoder(resp.Body).Decode(&stats); err != nil {
+		t.Fatal(err)
+	}
+	return stats
+}
+
+func decodeVersionedConfig(t *testing.T, resp *http.Response) order.VersionedConfig {
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("unexpected status code: got '%d' want '%d'", resp.StatusCode, http.StatusOK)
+	}
+
+	var cfg order.VersionedConfig
+	if err := json.NewDecoder(resp.Body).Decode(&cfg); err != nil {
+		t.Fatal(err)
+	}
+	return cfg
+}
+
+func decodeConfigVersions(t *testing.T, resp *http.Response) order.ConfigVersions {
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("unexpected status code: got '%d' want '%d'", resp.StatusCode, http.StatusOK)
+	}
+
+	var versions order.ConfigVersions
+	if err := json.NewDecoder(resp.Body).Decode(&versions); err != nil {
+		t.Fatal(err)
+	}
+	return versions
+}
+
+func doCreateProduct(t *testing.T, httpClient *http.Client, sku string, packSizes []int) order.Product {
+	jsonBytes, err := json.Marshal(order.CreateProductRequest{SKU: sku, Name: "Tea", PackSizes: packSizes})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := httpClient.Do(newPostRequest(t, productsUrl, jsonBytes))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("unexpected status code: got '%d' want '%d'", resp.StatusCode, http.StatusOK)
+	}
+
+	var product order.Product
+	if err = json.NewDecoder(resp.Body).Decode(&product); err != nil {
+		t.Fatal(err)
+	}
+	return product
+}
+
+// doOrderAction confirms or cancels the order and returns it, failing the test if the status code is unexpected.
+func doOrderAction(t *testing.T, httpClient *http.Client, id int64, action string, expectedStatus int) order.Order {
+	resp, err := httpClient.Do(newPostRequest(t, fmt.Sprintf("%s/%d/%s", ordersUrl, id, action), nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != expectedStatus {
+		resp.Body.Close()
+		t.Fatalf("unexpected status code: got '%d' want '%d'", resp.StatusCode, expectedStatus)
+	}
+	if expectedStatus != http.StatusOK {
+		resp.Body.Close()
+		return order.Order{}
+	}
+	return decodeOrder(t, resp)
+}
+
+func decodeOrder(t *testing.T, resp *http.Response) order.Order {
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("unexpected status code: got '%d' want '%d'", resp.StatusCode, http.StatusOK)
+	}
+
+	var orderResp order.Order
+	if err := json.NewDecoder(resp.Body).Decode(&orderResp); err != nil {
+		t.Fatal(err)
+	}
+	return orderResp
+}
+
 func assertInvalidPayloadResponse(t *testing.T, resp *http.Response) {
 	defer resp.Body.Close()
 
@@ -348,8 +1287,9 @@ func assertInvalidOrderSize(t *testing.T, resp *http.Response) {
 
 func assertCorsHeaders(t *testing.T, resp *http.Response) {
 	assertHeader(t, resp, "Access-Control-Allow-Origin", "*")
-	assertHeader(t, resp, "Access-Control-Allow-Methods", "POST, PUT")
+	assertHeader(t, resp, "Access-Control-Allow-Methods", "POST, PUT, DELETE")
 	assertHeader(t, resp, "Access-Control-Allow-Headers", "*")
+	assertHeader(t, resp, "Access-Control-Expose-Headers", "ETag, Last-Modified, X-Request-ID")
 }
 
 func assertHeader(t *testing.T, resp *http.Response, name, expected string) {
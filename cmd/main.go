@@ -1,58 +1,125 @@
 package main
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/stdlib"
-	"log"
+	"log/slog"
 	"os"
+	"packer/internal/logging"
 	"packer/internal/rest"
+	"packer/internal/rest/order"
+	"packer/internal/rest/order/pack"
 	"packer/internal/rest/order/repository"
+	"packer/internal/tracing"
 	"strconv"
 	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"go.opentelemetry.io/otel/trace"
 )
 
 const (
-	envDatabaseURL             = "DATABASE_URL"
-	envDatabaseConnMaxLifeTime = "DATABASE_CONNECTION_MAX_LIFE_TIME"
-	envReadTimeoutSeconds      = "READ_TIMEOUT_SECONDS"
-	envWriteTimeoutSeconds     = "WRITE_TIMEOUT_SECONDS"
-	envIdleTimeoutSeconds      = "IDLE_TIMEOUT_SECONDS"
-	envPort                    = "PORT"
+	envDatabaseURL               = "DATABASE_URL"
+	envDatabaseConnMaxLifeTime   = "DATABASE_CONNECTION_MAX_LIFE_TIME"
+	envReadTimeoutSeconds        = "READ_TIMEOUT_SECONDS"
+	envWriteTimeoutSeconds       = "WRITE_TIMEOUT_SECONDS"
+	envIdleTimeoutSeconds        = "IDLE_TIMEOUT_SECONDS"
+	envQuoteTTLSeconds           = "QUOTE_TTL_SECONDS"
+	envQuoteReaperSeconds        = "QUOTE_REAPER_INTERVAL_SECONDS"
+	envDefaultSolver             = "DEFAULT_SOLVER"
+	envShutdownDelaySeconds      = "SHUTDOWN_DELAY_SECONDS"
+	envShutdownTimeoutSeconds    = "SHUTDOWN_TIMEOUT_SECONDS"
+	envHealthCheckTimeoutSeconds = "HEALTH_CHECK_TIMEOUT_SECONDS"
+	envConfigCacheTTLSeconds     = "CONFIG_CACHE_TTL_SECONDS"
+	envTableCacheMegabytes       = "TABLE_CACHE_MEGABYTES"
+	envLogLevel                  = "LOG_LEVEL"
+	envLogFormat                 = "LOG_FORMAT"
+	envTracesExporter            = "TRACES_EXPORTER"
+	envPort                      = "PORT"
+
+	// tracesShutdownTimeout is how long the remaining spans have to be exported once the service is shut down.
+	tracesShutdownTimeout = 5 * time.Second
 )
 
 func main() {
+	setLogger()
+
 	db, err := sql.Open("pgx", newDbConnString())
 	if err != nil {
-		log.Fatalf("unable to connect to database: %v\n", err)
+		fatal("unable to connect to database", err)
 	}
 
-	defer func(db *sql.DB) {
-		closeErr := db.Close()
-		if closeErr != nil {
-			log.Fatalf("unable to close the database connection: %v\n", err)
-		}
-	}(db)
-
 	setDbConnMaxLifeTime(db)
 	pingDb(db)
 
+	tracerProvider, shutdownTracing := newTracerProvider()
+
 	repo := repository.NewDatabase(db)
-	svc := rest.NewApiService(newRestApiConfig(), &repo)
+	svc := rest.NewApiServiceWithTracerProvider(newRestApiConfig(), &repo, newMetricsRegistry(db), tracerProvider)
 
 	port := getEnvIntOrDefault(envPort, 8080)
-	svc.Serve(port)
+	serveErr := svc.Serve(context.Background(), port)
+
+	// closed once the in-flight requests are drained, so that none of them loses its connection
+	if err = db.Close(); err != nil {
+		slog.Error("unable to close the database connection", "error", err)
+	}
+	shutdownTracing()
+	if serveErr != nil {
+		fatal("the api service will be shutdown because an error ocurred", serveErr)
+	}
+	slog.Info("the api service has been shutdown")
+}
+
+// setLogger logs the records of the level and above in the format set by the environment, JSON records of the info
+// level and above by default. The records of the log package are logged at the info level.
+func setLogger() {
+	level := getEnvLogLevelOrDefault(envLogLevel, slog.LevelInfo)
+	format := getEnvLogFormatOrDefault(envLogFormat, logging.FormatJSON)
+	logger, err := logging.NewLogger(os.Stderr, format, level)
+	if err != nil {
+		fatal("unable to create the logger", err)
+	}
+	slog.SetDefault(logger)
+}
+
+// newTracerProvider creates the provider of the tracers whose spans are exported by the exporter set by the
+// environment, none by default. The returned function flushes the spans that are not exported yet.
+func newTracerProvider() (trace.TracerProvider, func()) {
+	exporter := getEnvTracesExporterOrDefault(envTracesExporter, tracing.ExporterNone)
+	provider, shutdown, err := tracing.NewTracerProvider(context.Background(), exporter, os.Stdout)
+	if err != nil {
+		fatal("unable to create the tracer provider", err)
+	}
+
+	return provider, func() {
+		ctx, cancel := context.WithTimeout(context.Background(), tracesShutdownTimeout)
+		defer cancel()
+		if err := shutdown(ctx); err != nil {
+			slog.Error("unable to export the remaining spans", "error", err)
+		}
+	}
+}
+
+// fatal logs the error and exits.
+func fatal(msg string, err error) {
+	slog.Error(msg, "error", err)
+	os.Exit(1)
 }
 
 func newDbConnString() string {
 	dbUrl := os.Getenv(envDatabaseURL)
 	if dbUrl == "" {
-		log.Fatal("database url has not been set")
+		fatal("unable to connect to database", errors.New("database url has not been set"))
 	}
 
 	connConfig, err := pgx.ParseConfig(dbUrl)
 	if err != nil {
-		log.Fatalf("unable to parse database config: %v\n", err)
+		fatal("unable to parse database config", err)
 	}
 
 	return stdlib.RegisterConnConfig(connConfig)
@@ -66,19 +133,103 @@ func setDbConnMaxLifeTime(db *sql.DB) {
 func pingDb(db *sql.DB) {
 	err := db.Ping()
 	if err != nil {
-		log.Fatalf("unable to ping the database: %v\n", err)
+		fatal("unable to ping the database", err)
 	}
 }
 
+// newMetricsRegistry creates the registry of the metrics, with the ones of the Go runtime, the process and the
+// database's connection pool.
+func newMetricsRegistry(db *sql.DB) *prometheus.Registry {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		collectors.NewDBStatsCollector(db, "packer"),
+	)
+	return registry
+}
+
 func newRestApiConfig() rest.Config {
 	readTimeout := getEnvIntOrDefault(envReadTimeoutSeconds, 30)
 	writeTimeout := getEnvIntOrDefault(envWriteTimeoutSeconds, 90)
 	idleTimeout := getEnvIntOrDefault(envIdleTimeoutSeconds, 120)
+	quoteTTL := getEnvIntOrDefault(envQuoteTTLSeconds, 900)
+	reaperInterval := getEnvIntOrDefault(envQuoteReaperSeconds, 60)
+	shutdownDelay := getEnvIntOrDefault(envShutdownDelaySeconds, 0)
+	shutdownTimeout := getEnvIntOrDefault(envShutdownTimeoutSeconds, 30)
+	healthCheckTimeout := getEnvIntOrDefault(envHealthCheckTimeoutSeconds, int(rest.DefaultHealthCheckTimeout/time.Second))
+	configCacheTTL := getEnvIntOrDefault(envConfigCacheTTLSeconds, int(order.DefaultConfigCacheTTL/time.Second))
+	tableCacheMegabytes := getEnvIntOrDefault(envTableCacheMegabytes, order.DefaultTableCacheBytes>>20)
 	return rest.Config{
-		ReadTimeout:  time.Duration(readTimeout) * time.Second,
-		WriteTimeout: time.Duration(writeTimeout) * time.Second,
-		IdleTimeout:  time.Duration(idleTimeout) * time.Second,
+		ReadTimeout:        time.Duration(readTimeout) * time.Second,
+		WriteTimeout:       time.Duration(writeTimeout) * time.Second,
+		IdleTimeout:        time.Duration(idleTimeout) * time.Second,
+		QuoteTTL:           time.Duration(quoteTTL) * time.Second,
+		ReaperInterval:     time.Duration(reaperInterval) * time.Second,
+		ShutdownDelay:      time.Duration(shutdownDelay) * time.Second,
+		ShutdownTimeout:    time.Duration(shutdownTimeout) * time.Second,
+		HealthCheckTimeout: time.Duration(healthCheckTimeout) * time.Second,
+		DefaultSolver:      getEnvSolverOrDefault(envDefaultSolver, pack.DefaultSolver),
+		ConfigCacheTTL:     time.Duration(configCacheTTL) * time.Second,
+		TableCacheBytes:    tableCacheMegabytes << 20,
+	}
+}
+
+func getEnvSolverOrDefault(key string, def string) string {
+	value := os.Getenv(key)
+	if value == "" {
+		return def
+	}
+
+	if !pack.SolverValid(value) {
+		slog.Warn("the environment variable should be the name of a solver, using the default value",
+			"key", key, "default", def)
+		return def
+	}
+	return value
+}
+
+func getEnvLogLevelOrDefault(key string, def slog.Level) slog.Level {
+	value := os.Getenv(key)
+	if value == "" {
+		return def
+	}
+
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(value)); err != nil {
+		slog.Warn("the environment variable should be a log level (debug, info, warn or error), using the default value",
+			"key", key, "default", def.String())
+		return def
+	}
+	return level
+}
+
+func getEnvLogFormatOrDefault(key string, def string) string {
+	value := os.Getenv(key)
+	if value == "" {
+		return def
+	}
+
+	if !logging.ValidFormat(value) {
+		slog.Warn("the environment variable should be a log format (json or text), using the default value",
+			"key", key, "default", def)
+		return def
+	}
+	return value
+}
+
+func getEnvTracesExporterOrDefault(key string, def string) string {
+	value := os.Getenv(key)
+	if value == "" {
+		return def
+	}
+
+	if !tracing.ValidExporter(value) {
+		slog.Warn("the environment variable should be a traces exporter (otlp, stdout or none), using the default value",
+			"key", key, "default", def)
+		return def
 	}
+	return value
 }
 
 func getEnvIntOrDefault(key string, def int) int {
@@ -89,8 +240,7 @@ func getEnvIntOrDefault(key string, def int) int {
 
 	atoi, err := strconv.Atoi(value)
 	if err != nil {
-		log.Printf("the following environment variable should be an int: %s\n", key)
-		log.Printf("using the default value: %d\n", def)
+		slog.Warn("the environment variable should be an int, using the default value", "key", key, "default", def)
 		return def
 	}
 	return atoi
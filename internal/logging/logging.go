@@ -0,0 +1,107 @@
+package logging
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log/slog"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// RequestIDHeader is the header of the ID that correlates the logs of a request, sent by the client or generated.
+	RequestIDHeader = "X-Request-ID"
+
+	FormatJSON = "json"
+	FormatText = "text"
+
+	requestIDKey  = "request_id"
+	traceIDKey    = "trace_id"
+	spanIDKey     = "span_id"
+	maxRequestID  = 128
+	requestIDSize = 16
+)
+
+type requestIDContextKey struct{}
+
+// WithRequestID returns a copy of the context with the request ID, which is added to the records logged with it.
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDContextKey{}, requestID)
+}
+
+// RequestID returns the request ID of the context, or false if it has none.
+func RequestID(ctx context.Context) (string, bool) {
+	requestID, ok := ctx.Value(requestIDContextKey{}).(string)
+	return requestID, ok
+}
+
+// NewRequestID generates a random request ID.
+func NewRequestID() string {
+	b := make([]byte, requestIDSize)
+	// crypto/rand does not fail on the supported platforms
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// ValidRequestID returns true if the request ID sent by a client can be used: it has up to 128 printable ASCII
+// characters, without spaces.
+func ValidRequestID(requestID string) bool {
+	if requestID == "" || len(requestID) > maxRequestID {
+		return false
+	}
+	for i := 0; i < len(requestID); i++ {
+		if requestID[i] <= ' ' || requestID[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// NewLogger creates a logger that writes the records of the level and above in the format (FormatJSON or FormatText),
+// with the request ID (see WithRequestID) and the trace and span IDs of their context if any.
+func NewLogger(w io.Writer, format string, level slog.Level) (*slog.Logger, error) {
+	opts := &slog.HandlerOptions{Level: level}
+
+	var handler slog.Handler
+	switch format {
+	case FormatJSON:
+		handler = slog.NewJSONHandler(w, opts)
+	case FormatText:
+		handler = slog.NewTextHandler(w, opts)
+	default:
+		return nil, fmt.Errorf("unknown log format: %s", format)
+	}
+	return slog.New(contextHandler{Handler: handler}), nil
+}
+
+// ValidFormat returns true if the format is FormatJSON or FormatText.
+func ValidFormat(format string) bool {
+	return format == FormatJSON || format == FormatText
+}
+
+// contextHandler adds the request ID and the trace and span IDs of the context to the records.
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if requestID, ok := RequestID(ctx); ok {
+		record.AddAttrs(slog.String(requestIDKey, requestID))
+	}
+	if spanCtx := trace.SpanContextFromContext(ctx); spanCtx.IsValid() {
+		record.AddAttrs(slog.String(traceIDKey, spanCtx.TraceID().String()),
+			slog.String(spanIDKey, spanCtx.SpanID().String()))
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{Handler: h.Handler.WithGroup(name)}
+}
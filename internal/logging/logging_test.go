@@ -0,0 +1,125 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestNewLogger_JSON(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := NewLogger(&buf, FormatJSON, slog.LevelInfo)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := WithRequestID(context.Background(), "abc-123")
+	logger.With("component", "test").InfoContext(ctx, "computed", "size", 251)
+	logger.DebugContext(ctx, "ignored")
+	logger.Info("without request")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("unexpected records: got '%d' want '%d'", len(lines), 2)
+	}
+	var record map[string]any
+	if err = json.Unmarshal([]byte(lines[0]), &record); err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]any{"level": "INFO", "msg": "computed", "component": "test", "size": 251.0, "request_id": "abc-123"}
+	for key, value := range expected {
+		if record[key] != value {
+			t.Errorf("unexpected %s: got '%v' want '%v'", key, record[key], value)
+		}
+	}
+	if strings.Contains(lines[1], "request_id") {
+		t.Errorf("unexpected request ID in the record without one: '%s'", lines[1])
+	}
+}
+
+func TestNewLogger_Text(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := NewLogger(&buf, FormatText, slog.LevelDebug)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	logger.DebugContext(WithRequestID(context.Background(), "abc-123"), "computed", "name", "dp")
+
+	for _, expected := range []string{"level=DEBUG", "msg=computed", "name=dp", "request_id=abc-123"} {
+		if !strings.Contains(buf.String(), expected) {
+			t.Errorf("unexpected record without '%s': '%s'", expected, buf.String())
+		}
+	}
+}
+
+func TestNewLogger_Trace(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := NewLogger(&buf, FormatText, slog.LevelInfo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	spanCtx := trace.NewSpanContext(trace.SpanContextConfig{TraceID: traceID, SpanID: spanID})
+
+	logger.InfoContext(trace.ContextWithSpanContext(context.Background(), spanCtx), "computed")
+	logger.Info("without trace")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	for _, expected := range []string{"trace_id=4bf92f3577b34da6a3ce929d0e0e4736", "span_id=00f067aa0ba902b7"} {
+		if !strings.Contains(lines[0], expected) {
+			t.Errorf("unexpected record without '%s': '%s'", expected, lines[0])
+		}
+	}
+	if strings.Contains(lines[1], "trace_id") {
+		t.Errorf("unexpected trace ID in the record without one: '%s'", lines[1])
+	}
+}
+
+func TestNewLogger_UnknownFormat(t *testing.T) {
+	if _, err := NewLogger(&bytes.Buffer{}, "xml", slog.LevelInfo); err == nil {
+		t.Error("unexpected logger of an unknown format")
+	}
+}
+
+func TestValidRequestID(t *testing.T) {
+	data := []struct {
+		requestID string
+		expected  bool
+	}{
+		{requestID: "abc-123", expected: true},
+		{requestID: "4bf92f3577b34da6a3ce929d0e0e4736", expected: true},
+		{requestID: strings.Repeat("a", 128), expected: true},
+		{requestID: "", expected: false},
+		{requestID: strings.Repeat("a", 129), expected: false},
+		{requestID: "abc 123", expected: false},
+		{requestID: "abc\n123", expected: false},
+		{requestID: "abc-é", expected: false},
+	}
+
+	for _, d := range data {
+		t.Run(fmt.Sprintf("with %q", d.requestID), func(t *testing.T) {
+			if valid := ValidRequestID(d.requestID); valid != d.expected {
+				t.Errorf("unexpected validity: got '%t' want '%t'", valid, d.expected)
+			}
+		})
+	}
+}
+
+func TestNewRequestID(t *testing.T) {
+	requestID := NewRequestID()
+
+	if len(requestID) != 32 || !ValidRequestID(requestID) {
+		t.Errorf("unexpected request ID: '%s'", requestID)
+	}
+	if other := NewRequestID(); other == requestID {
+		t.Errorf("unexpected same request IDs: '%s'", requestID)
+	}
+}
@@ -0,0 +1,34 @@
+package rest
+
+import (
+	"log/slog"
+	"net/http"
+	"packer/internal/logging"
+	"time"
+)
+
+// logRequests logs every request once it is served, with its status code and latency. The request's logs are
+// correlated by its request ID: the one of its X-Request-ID header if it is valid, a generated one otherwise. The ID is
+// added to the request's context, so that the records logged with it have it, and echoed in the response's header.
+func logRequests(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		requestID := r.Header.Get(logging.RequestIDHeader)
+		if !logging.ValidRequestID(requestID) {
+			requestID = logging.NewRequestID()
+		}
+		w.Header().Set(logging.RequestIDHeader, requestID)
+		ctx := logging.WithRequestID(r.Context(), requestID)
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+
+		handler.ServeHTTP(recorder, r.WithContext(ctx))
+
+		slog.LogAttrs(ctx, slog.LevelInfo, "request",
+			slog.String("method", r.Method),
+			slog.String("path", r.URL.Path),
+			slog.Int("status", recorder.status),
+			slog.Int64("duration_ms", time.Since(start).Milliseconds()),
+			slog.String("remote_addr", r.RemoteAddr),
+		)
+	})
+}
@@ -0,0 +1,118 @@
+package rest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"packer/internal/logging"
+	"packer/internal/rest/order"
+	"strings"
+	"testing"
+)
+
+func TestLogRequests_RequestID(t *testing.T) {
+	data := []struct {
+		description string
+		requestID   string
+		expectedNew bool
+	}{
+		{description: "request ID", requestID: "abc-123"},
+		{description: "no request ID", expectedNew: true},
+		{description: "invalid request ID", requestID: "abc 123", expectedNew: true},
+	}
+
+	for _, d := range data {
+		t.Run(fmt.Sprintf("with %s", d.description), func(t *testing.T) {
+			logs := captureLogs(t)
+			svc := NewApiService(Config{}, &TestRepository{})
+
+			rr := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, order.Path, strings.NewReader("{size}"))
+			req.Header.Set(logging.RequestIDHeader, d.requestID)
+			logRequests(svc.newServeMux()).ServeHTTP(rr, req)
+
+			requestID := rr.Header().Get(logging.RequestIDHeader)
+			if d.expectedNew && (requestID == d.requestID || !logging.ValidRequestID(requestID)) {
+				t.Errorf("unexpected request ID: got '%s' want a new one", requestID)
+			}
+			if !d.expectedNew && requestID != d.requestID {
+				t.Errorf("unexpected request ID: got '%s' want '%s'", requestID, d.requestID)
+			}
+
+			var errResp order.ErrorResponse
+			if err := json.NewDecoder(rr.Body).Decode(&errResp); err != nil {
+				t.Fatal(err)
+			}
+			if errResp.RequestID != requestID {
+				t.Errorf("unexpected request ID of the error: got '%s' want '%s'", errResp.RequestID, requestID)
+			}
+
+			assertLastLog(t, logs, map[string]any{
+				"msg":        "request",
+				"method":     http.MethodPost,
+				"path":       order.Path,
+				"status":     float64(http.StatusBadRequest),
+				"request_id": requestID,
+			})
+		})
+	}
+}
+
+func TestLogRequests_Status(t *testing.T) {
+	logs := captureLogs(t)
+	svc := NewApiService(Config{}, &TestRepository{})
+
+	rr := httptest.NewRecorder()
+	logRequests(svc.newServeMux()).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, livenessPath, nil))
+
+	assertStatus(t, rr, http.StatusOK)
+	assertLastLog(t, logs, map[string]any{
+		"msg":    "request",
+		"method": http.MethodGet,
+		"path":   livenessPath,
+		"status": float64(http.StatusOK),
+	})
+}
+
+// captureLogs logs the records of the test, of all levels, in the returned buffer.
+func captureLogs(t *testing.T) *bytes.Buffer {
+	var buf bytes.Buffer
+	logger, err := logging.NewLogger(&buf, logging.FormatJSON, slog.LevelDebug)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	previous := slog.Default()
+	slog.SetDefault(logger)
+	t.Cleanup(func() {
+		slog.SetDefault(previous)
+	})
+	return &buf
+}
+
+// assertLastLog asserts that the last record has the expected attributes and a duration.
+func assertLastLog(t *testing.T, logs *bytes.Buffer, expected map[string]any) {
+	lines := strings.Split(strings.TrimSpace(logs.String()), "\n")
+	var record map[string]any
+	if err := json.Unmarshal([]byte(lines[len(lines)-1]), &record); err != nil {
+		t.Fatal(err)
+	}
+
+	for key, value := range expected {
+		if record[key] != value {
+			t.Errorf("unexpected %s of the record: got '%v' want '%v'", key, record[key], value)
+		}
+	}
+	if _, ok := record["duration_ms"]; !ok {
+		t.Errorf("unexpected record without duration: '%v'", record)
+	}
+}
+
+func assertStatus(t *testing.T, rr *httptest.ResponseRecorder, expected int) {
+	if rr.Code != expected {
+		t.Errorf("unexpected status code: got '%d' want '%d'", rr.Code, expected)
+	}
+}
@@ -0,0 +1,132 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"packer/internal/rest/order/repository"
+	"time"
+)
+
+const (
+	livenessPath  = "/healthz"
+	readinessPath = "/readyz"
+
+	// DefaultHealthCheckTimeout is the default time the readiness checks have to complete.
+	DefaultHealthCheckTimeout = 2 * time.Second
+
+	healthStatusOK   = "ok"
+	healthStatusFail = "fail"
+)
+
+// errDraining is the error of the readiness while the service drains the in-flight requests before shutting down.
+var errDraining = errors.New("the service is shutting down")
+
+// Health is the status of the service, "ok" if all its checks are "ok" or "fail" otherwise, with the detail of every
+// check by name.
+type Health struct {
+	Status string                 `json:"status"`
+	Checks map[string]HealthCheck `json:"checks"`
+}
+
+// HealthCheck is the status of a check and how long it took. The error of a failed check is logged rather than
+// reported, so that the details of the database (e.g. its host) are not disclosed.
+type HealthCheck struct {
+	Status     string `json:"status"`
+	DurationMs int64  `json:"duration_ms"`
+}
+
+// healthCheck checks a part of the service, returning an error if it is not healthy.
+type healthCheck func(ctx context.Context) error
+
+// handleLiveness reports the service as alive as long as it serves requests: its dependencies are not checked, so that
+// an unavailable database does not restart it.
+func (svc *ApiService) handleLiveness(w http.ResponseWriter, r *http.Request) {
+	svc.writeHealth(w, r, map[string]healthCheck{
+		"server": func(context.Context) error { return nil },
+	})
+}
+
+// handleReadiness reports the service as ready if the database answers a ping, the config of the default product can
+// be loaded and the service is not draining the in-flight requests before shutting down (see ApiService.Serve).
+// The checks must complete within the health check timeout.
+func (svc *ApiService) handleReadiness(w http.ResponseWriter, r *http.Request) {
+	svc.writeHealth(w, r, map[string]healthCheck{
+		"database": svc.repo.Ping,
+		"config":   svc.checkConfig,
+		"draining": svc.checkDraining,
+	})
+}
+
+func (svc *ApiService) checkConfig(ctx context.Context) error {
+	_, err := svc.repo.FindConfig(ctx, repository.DefaultProduct, time.Now())
+	return err
+}
+
+func (svc *ApiService) checkDraining(context.Context) error {
+	if svc.draining.Load() {
+		return errDraining
+	}
+	return nil
+}
+
+// writeHealth runs the checks concurrently and writes their health, with a 503 status code if any of them failed.
+func (svc *ApiService) writeHealth(w http.ResponseWriter, r *http.Request, checks map[string]healthCheck) {
+	health := svc.checkHealth(r.Context(), checks)
+
+	jsonBytes, err := json.Marshal(health)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "unable to encode the health", "error", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	status := http.StatusOK
+	if health.Status != healthStatusOK {
+		status = http.StatusServiceUnavailable
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if _, err = w.Write(jsonBytes); err != nil {
+		slog.ErrorContext(r.Context(), "unable to write the health", "error", err)
+	}
+}
+
+func (svc *ApiService) checkHealth(ctx context.Context, checks map[string]healthCheck) Health {
+	timeout := svc.cfg.HealthCheckTimeout
+	if timeout <= 0 {
+		timeout = DefaultHealthCheckTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	type namedResult struct {
+		name   string
+		result HealthCheck
+	}
+	results := make(chan namedResult, len(checks))
+	for name, check := range checks {
+		go func(name string, check healthCheck) {
+			start := time.Now()
+			result := HealthCheck{Status: healthStatusOK}
+			if err := check(ctx); err != nil {
+				slog.WarnContext(ctx, "health check failed", "check", name, "error", err)
+				result = HealthCheck{Status: healthStatusFail}
+			}
+			result.DurationMs = time.Since(start).Milliseconds()
+			results <- namedResult{name: name, result: result}
+		}(name, check)
+	}
+
+	health := Health{Status: healthStatusOK, Checks: make(map[string]HealthCheck, len(checks))}
+	for range checks {
+		r := <-results
+		health.Checks[r.name] = r.result
+		if r.result.Status != healthStatusOK {
+			health.Status = healthStatusFail
+		}
+	}
+	return health
+}
@@ -0,0 +1,280 @@
+package rest
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"packer/internal/rest/order"
+	"packer/internal/rest/order/pack"
+	"packer/internal/rest/order/repository"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	metricsPath      = "/metrics"
+	metricsNamespace = "packer"
+
+	outcomeOK    = "ok"
+	outcomeError = "error"
+
+	// the operations of the solvers: the packs of an order, their candidates (see order.PacksExplainer) and their ties
+	// (see order.TiesComputer)
+	operationPacks      = "packs"
+	operationCandidates = "candidates"
+	operationTies       = "ties"
+)
+
+// Metrics are the Prometheus metrics of the requests, the solvers and the repository's queries.
+type Metrics struct {
+	requests        *prometheus.CounterVec
+	requestDuration *prometheus.HistogramVec
+	solverDuration  *prometheus.HistogramVec
+	orderSize       *prometheus.HistogramVec
+	packCount       *prometheus.HistogramVec
+	queryDuration   *prometheus.HistogramVec
+	queryErrors     *prometheus.CounterVec
+}
+
+// NewMetrics creates the metrics and registers them in the registerer.
+func NewMetrics(registerer prometheus.Registerer) *Metrics {
+	m := &Metrics{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "http_requests_total",
+			Help:      "The HTTP requests by route, method and status code.",
+		}, []string{"route", "method", "status"}),
+		requestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "The latency of the HTTP requests by route, method and status code.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"route", "method", "status"}),
+		solverDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Name:      "solver_duration_seconds",
+			Help:      "The time the solvers take to compute, by solver, operation (packs, candidates or ties) and outcome.",
+			Buckets:   prometheus.ExponentialBuckets(0.0001, 4, 10),
+		}, []string{"solver", "operation", "outcome"}),
+		orderSize: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Name:      "order_size",
+			Help:      "The sizes of the orders computed by the solvers, by solver.",
+			Buckets:   prometheus.ExponentialBuckets(1, 10, 10),
+		}, []string{"solver"}),
+		packCount: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Name:      "order_pack_count",
+			Help:      "The number of packs of the orders computed by the solvers, by solver.",
+			Buckets:   prometheus.ExponentialBuckets(1, 10, 10),
+		}, []string{"solver"}),
+		queryDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Name:      "db_query_duration_seconds",
+			Help:      "The latency of the database queries, by query and outcome.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"query", "outcome"}),
+		queryErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "db_query_errors_total",
+			Help:      "The database queries that failed, by query.",
+		}, []string{"query"}),
+	}
+	registerer.MustRegister(m.requests, m.requestDuration, m.solverDuration, m.orderSize, m.packCount, m.queryDuration,
+		m.queryErrors)
+	return m
+}
+
+// Instrument counts the requests served by the handler and observes their latency, labelled with the route.
+func (m *Metrics) Instrument(route string, handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+
+		handler.ServeHTTP(recorder, r)
+
+		labels := prometheus.Labels{"route": route, "method": r.Method, "status": strconv.Itoa(recorder.status)}
+		m.requests.With(labels).Inc()
+		m.requestDuration.With(labels).Observe(time.Since(start).Seconds())
+	})
+}
+
+// statusRecorder records the status code written to the response writer.
+type statusRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+func (w *statusRecorder) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.status, w.wroteHeader = status, true
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *statusRecorder) Write(b []byte) (int, error) {
+	w.wroteHeader = true
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush flushes the response writer if it can, so that the streamed responses (e.g. the batch quotes) are not buffered.
+func (w *statusRecorder) Flush() {
+	w.wroteHeader = true
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Unwrap returns the response writer, so that http.ResponseController reaches it.
+func (w *statusRecorder) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// InstrumentPacksComputer observes the duration, the order size and the pack count of the packs computed by the named
+// solver, and the duration of their candidates and ties if the computer computes them, and logs every computation at
+// the debug level with the context's request ID (see logging.WithRequestID).
+// The instrumented computer is an order.PacksExplainer or an order.TiesComputer if the computer is one.
+func (m *Metrics) InstrumentPacksComputer(solver string, computer order.PacksComputer) order.PacksComputer {
+	return withSolverMethodsOf(&instrumentedPacksComputer{metrics: m, solver: solver, computer: computer}, computer)
+}
+
+type instrumentedPacksComputer struct {
+	metrics  *Metrics
+	solver   string
+	computer order.PacksComputer
+}
+
+func (c *instrumentedPacksComputer) ComputePacksWithObjective(ctx context.Context, packSizes []int, stock map[int]int, orderSize int, objective pack.Objective) ([]pack.Pack, error) {
+	start := time.Now()
+	packs, err := c.computer.ComputePacksWithObjective(ctx, packSizes, stock, orderSize, objective)
+	c.observe(ctx, operationPacks, start, err, "order_size", orderSize)
+	if err != nil {
+		return nil, err
+	}
+
+	c.metrics.orderSize.WithLabelValues(c.solver).Observe(float64(orderSize))
+	c.metrics.packCount.WithLabelValues(c.solver).Observe(float64(countPacks(packs)))
+	return packs, nil
+}
+
+// ComputeCandidates must only be called if the computer is an order.PacksExplainer (see withSolverMethodsOf).
+func (c *instrumentedPacksComputer) ComputeCandidates(ctx context.Context, packSizes []int, stock map[int]int, orderSize int, limit int) ([]pack.Solution, error) {
+	start := time.Now()
+	candidates, err := c.computer.(order.PacksExplainer).ComputeCandidates(ctx, packSizes, stock, orderSize, limit)
+	c.observe(ctx, operationCandidates, start, err, "order_size", orderSize, "limit", limit)
+	return candidates, err
+}
+
+// ComputeTies must only be called if the computer is an order.TiesComputer (see withSolverMethodsOf).
+func (c *instrumentedPacksComputer) ComputeTies(ctx context.Context, packSizes []int, stock map[int]int, packs []pack.Pack, objective pack.Objective, limit int) ([][]pack.Pack, error) {
+	start := time.Now()
+	ties, err := c.computer.(order.TiesComputer).ComputeTies(ctx, packSizes, stock, packs, objective, limit)
+	c.observe(ctx, operationTies, start, err, "limit", limit)
+	return ties, err
+}
+
+// observe logs the operation of the solver with the attributes and observes its duration.
+func (c *instrumentedPacksComputer) observe(ctx context.Context, operation string, start time.Time, err error, attrs ...any) {
+	duration := time.Since(start)
+	attrs = append(attrs, "solver", c.solver, "operation", operation, "duration_ms", duration.Milliseconds())
+	if err != nil {
+		slog.DebugContext(ctx, "unable to compute with the solver", append(attrs, "error", err)...)
+		c.metrics.solverDuration.WithLabelValues(c.solver, operation, outcomeError).Observe(duration.Seconds())
+		return
+	}
+
+	slog.DebugContext(ctx, "computed with the solver", attrs...)
+	c.metrics.solverDuration.WithLabelValues(c.solver, operation, outcomeOK).Observe(duration.Seconds())
+}
+
+// countPacks returns the number of packs of every size.
+func countPacks(packs []pack.Pack) int {
+	count := 0
+	for _, p := range packs {
+		count += p.Quantity
+	}
+	return count
+}
+
+// InstrumentRepository observes the latency and counts the errors of the queries that find and set the configs, and logs
+// every query at the debug level with the context's request ID (see logging.WithRequestID).
+// The errors expected by the callers (repository.ErrNotFound and repository.ErrVersionConflict) are not counted.
+func (m *Metrics) InstrumentRepository(repo Repository) Repository {
+	return &instrumentedRepository{Repository: repo, metrics: m}
+}
+
+type instrumentedRepository struct {
+	Repository
+	metrics *Metrics
+}
+
+func (repo *instrumentedRepository) FindConfig(ctx context.Context, product string, at time.Time) (repository.Config, error) {
+	start := time.Now()
+	cfg, err := repo.Repository.FindConfig(ctx, product, at)
+	repo.metrics.observeQuery(ctx, "find_config", start, err)
+	return cfg, err
+}
+
+func (repo *instrumentedRepository) SetConfig(ctx context.Context, cfg repository.Config, versions []int64) (repository.Config, error) {
+	start := time.Now()
+	savedCfg, err := repo.Repository.SetConfig(ctx, cfg, versions)
+	repo.metrics.observeQuery(ctx, "set_config", start, err)
+	return savedCfg, err
+}
+
+func (m *Metrics) observeQuery(ctx context.Context, query string, start time.Time, err error) {
+	duration := time.Since(start)
+	slog.DebugContext(ctx, "queried the database", "query", query, "duration_ms", duration.Milliseconds(), "error", err)
+
+	outcome := outcomeOK
+	if err != nil && !errors.Is(err, repository.ErrNotFound) && !errors.Is(err, repository.ErrVersionConflict) {
+		outcome = outcomeError
+		m.queryErrors.WithLabelValues(query).Inc()
+	}
+	m.queryDuration.WithLabelValues(query, outcome).Observe(duration.Seconds())
+}
+
+// cacheCollector collects the statistics of the order's cache (see order.Cache) when the metrics are scraped.
+type cacheCollector struct {
+	cache     *order.Cache
+	hits      *prometheus.Desc
+	misses    *prometheus.Desc
+	entries   *prometheus.Desc
+	bytes     *prometheus.Desc
+	evictions *prometheus.Desc
+}
+
+func newCacheCollector(cache *order.Cache) *cacheCollector {
+	return &cacheCollector{
+		cache: cache,
+		hits: prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "cache", "hits_total"),
+			"The lookups that found their entry in the cache, by cache.", []string{"cache"}, nil),
+		misses: prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "cache", "misses_total"),
+			"The lookups that did not find their entry in the cache, by cache.", []string{"cache"}, nil),
+		entries: prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "cache", "entries"),
+			"The entries in the cache, by cache.", []string{"cache"}, nil),
+		bytes: prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "cache", "table_bytes"),
+			"The memory used by the cached solution tables.", nil, nil),
+		evictions: prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "cache", "table_evictions_total"),
+			"The solution tables evicted to stay within the memory limit.", nil, nil),
+	}
+}
+
+func (c *cacheCollector) Describe(descs chan<- *prometheus.Desc) {
+	prometheus.DescribeByCollect(c, descs)
+}
+
+func (c *cacheCollector) Collect(metrics chan<- prometheus.Metric) {
+	stats := c.cache.Stats()
+	for name, counters := range map[string]order.CacheCounters{"configs": stats.Configs, "tables": stats.Tables.CacheCounters} {
+		metrics <- prometheus.MustNewConstMetric(c.hits, prometheus.CounterValue, float64(counters.Hits), name)
+		metrics <- prometheus.MustNewConstMetric(c.misses, prometheus.CounterValue, float64(counters.Misses), name)
+		metrics <- prometheus.MustNewConstMetric(c.entries, prometheus.GaugeValue, float64(counters.Entries), name)
+	}
+	metrics <- prometheus.MustNewConstMetric(c.bytes, prometheus.GaugeValue, float64(stats.Tables.Bytes))
+	metrics <- prometheus.MustNewConstMetric(c.evictions, prometheus.CounterValue, float64(stats.Tables.Evictions))
+}
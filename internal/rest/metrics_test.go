@@ -0,0 +1,208 @@
+package rest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"packer/internal/logging"
+	"packer/internal/rest/order"
+	"packer/internal/rest/order/pack"
+	"packer/internal/rest/order/repository"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// TestConfigRepository returns the result and the error of its configs. Its other methods are not implemented.
+type TestConfigRepository struct {
+	Repository
+	err error
+}
+
+func (repo *TestConfigRepository) FindConfig(context.Context, string, time.Time) (repository.Config, error) {
+	return repository.Config{}, repo.err
+}
+
+func (repo *TestConfigRepository) SetConfig(_ context.Context, cfg repository.Config, _ []int64) (repository.Config, error) {
+	return cfg, repo.err
+}
+
+// TestPacksComputer returns the result and the error of the packs.
+type TestPacksComputer struct {
+	result []pack.Pack
+	err    error
+}
+
+func (comp *TestPacksComputer) ComputePacksWithObjective(context.Context, []int, map[int]int, int, pack.Objective) ([]pack.Pack, error) {
+	return comp.result, comp.err
+}
+
+func TestMetrics_Instrument(t *testing.T) {
+	svc := NewApiService(Config{}, &TestRepository{findConfigErr: repository.ErrNotFound})
+	mux := svc.newServeMux()
+
+	for _, path := range []string{livenessPath, livenessPath, readinessPath, order.Path + "/cache"} {
+		mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	assertMetrics(t, mux,
+		`packer_http_requests_total{method="GET",route="/healthz",status="200"} 2`,
+		`packer_http_requests_total{method="GET",route="/readyz",status="503"} 1`,
+		`packer_http_requests_total{method="GET",route="/orders",status="200"} 1`,
+		`packer_http_request_duration_seconds_count{method="GET",route="/healthz",status="200"} 2`,
+	)
+}
+
+func TestMetrics_InstrumentPacksComputer(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	metrics := NewMetrics(registry)
+	packs := []pack.Pack{{Size: 500, Quantity: 2}, {Size: 250, Quantity: 1}}
+	computer := metrics.InstrumentPacksComputer(pack.SolverGreedy, &TestPacksComputer{result: packs})
+	failing := metrics.InstrumentPacksComputer(pack.SolverDP, &TestPacksComputer{err: pack.ErrCancelled})
+
+	if _, err := computer.ComputePacksWithObjective(context.Background(), []int{250, 500}, nil, 1001, nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := failing.ComputePacksWithObjective(context.Background(), []int{250, 500}, nil, 1001, nil); !errors.Is(err, pack.ErrCancelled) {
+		t.Errorf("unexpected error: got '%v' want '%v'", err, pack.ErrCancelled)
+	}
+
+	assertMetrics(t, promhttp.HandlerFor(registry, promhttp.HandlerOpts{}),
+		`packer_solver_duration_seconds_count{operation="packs",outcome="ok",solver="greedy"} 1`,
+		`packer_solver_duration_seconds_count{operation="packs",outcome="error",solver="dp"} 1`,
+		`packer_order_size_sum{solver="greedy"} 1001`,
+		`packer_order_pack_count_sum{solver="greedy"} 3`,
+	)
+}
+
+func TestMetrics_InstrumentPacksComputer_CandidatesAndTies(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	metrics := NewMetrics(registry)
+	computer := pack.NewComputer()
+	instrumented := metrics.InstrumentPacksComputer(pack.SolverDP, &computer)
+	packs := []pack.Pack{{Size: 3, Quantity: 2}}
+
+	if _, err := instrumented.(order.PacksExplainer).ComputeCandidates(context.Background(), []int{2, 3}, nil, 6, 3); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := instrumented.(order.TiesComputer).ComputeTies(context.Background(), []int{2, 3}, nil, packs, pack.PacksThenItems{}, 3); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := instrumented.(order.TiesComputer).ComputeTies(context.Background(), []int{0}, nil, packs, pack.PacksThenItems{}, 3); !errors.Is(err, pack.ErrInvalidInput) {
+		t.Errorf("unexpected error: got '%v' want '%v'", err, pack.ErrInvalidInput)
+	}
+
+	assertMetrics(t, promhttp.HandlerFor(registry, promhttp.HandlerOpts{}),
+		`packer_solver_duration_seconds_count{operation="candidates",outcome="ok",solver="dp"} 1`,
+		`packer_solver_duration_seconds_count{operation="ties",outcome="ok",solver="dp"} 1`,
+		`packer_solver_duration_seconds_count{operation="ties",outcome="error",solver="dp"} 1`,
+	)
+}
+
+func TestMetrics_InstrumentPacksComputer_Explainer(t *testing.T) {
+	metrics := NewMetrics(prometheus.NewRegistry())
+	computer := pack.NewComputer()
+	branchAndBound := pack.NewBranchAndBound()
+	greedy := pack.NewGreedy()
+	data := []struct {
+		solver               string
+		computer             order.PacksComputer
+		expectedExplainer    bool
+		expectedTiesComputer bool
+	}{
+		{solver: pack.SolverDP, computer: &computer, expectedExplainer: true, expectedTiesComputer: true},
+		{solver: pack.SolverBranchAndBound, computer: &branchAndBound, expectedTiesComputer: true},
+		{solver: pack.SolverGreedy, computer: &greedy},
+	}
+
+	for _, d := range data {
+		t.Run(fmt.Sprintf("with solver %s", d.solver), func(t *testing.T) {
+			instrumented := metrics.InstrumentPacksComputer(d.solver, d.computer)
+
+			if _, ok := instrumented.(order.PacksExplainer); ok != d.expectedExplainer {
+				t.Errorf("unexpected explainer: got '%t' want '%t'", ok, d.expectedExplainer)
+			}
+			if _, ok := instrumented.(order.TiesComputer); ok != d.expectedTiesComputer {
+				t.Errorf("unexpected ties computer: got '%t' want '%t'", ok, d.expectedTiesComputer)
+			}
+		})
+	}
+}
+
+func TestMetrics_InstrumentRepository(t *testing.T) {
+	data := []struct {
+		err             error
+		expectedOutcome string
+		expectedErrors  int
+	}{
+		{err: nil, expectedOutcome: "ok"},
+		{err: repository.ErrNotFound, expectedOutcome: "ok"},
+		{err: repository.ErrVersionConflict, expectedOutcome: "ok"},
+		{err: errors.New("connection refused"), expectedOutcome: "error", expectedErrors: 1},
+	}
+
+	for _, d := range data {
+		t.Run(fmt.Sprintf("with error %v", d.err), func(t *testing.T) {
+			registry := prometheus.NewRegistry()
+			repo := NewMetrics(registry).InstrumentRepository(&TestConfigRepository{err: d.err})
+
+			_, _ = repo.FindConfig(context.Background(), repository.DefaultProduct, time.Now())
+			_, _ = repo.SetConfig(context.Background(), repository.Config{}, nil)
+
+			expected := []string{
+				fmt.Sprintf(`packer_db_query_duration_seconds_count{outcome="%s",query="find_config"} 1`, d.expectedOutcome),
+				fmt.Sprintf(`packer_db_query_duration_seconds_count{outcome="%s",query="set_config"} 1`, d.expectedOutcome),
+			}
+			if d.expectedErrors > 0 {
+				expected = append(expected,
+					fmt.Sprintf(`packer_db_query_errors_total{query="find_config"} %d`, d.expectedErrors),
+					fmt.Sprintf(`packer_db_query_errors_total{query="set_config"} %d`, d.expectedErrors))
+			}
+			assertMetrics(t, promhttp.HandlerFor(registry, promhttp.HandlerOpts{}), expected...)
+		})
+	}
+}
+
+func TestMetrics_InstrumentRepository_LogsRequestID(t *testing.T) {
+	logs := captureLogs(t)
+	repo := NewMetrics(prometheus.NewRegistry()).InstrumentRepository(&TestConfigRepository{})
+	ctx := logging.WithRequestID(context.Background(), "abc-123")
+
+	_, _ = repo.FindConfig(ctx, repository.DefaultProduct, time.Now())
+
+	assertLastLog(t, logs, map[string]any{"msg": "queried the database", "query": "find_config", "request_id": "abc-123"})
+}
+
+func TestMetrics_Cache(t *testing.T) {
+	svc := NewApiService(Config{TableCacheBytes: order.DefaultTableCacheBytes}, &TestRepository{})
+
+	assertMetrics(t, svc.newServeMux(),
+		`packer_cache_hits_total{cache="configs"} 0`,
+		`packer_cache_misses_total{cache="tables"} 0`,
+		`packer_cache_entries{cache="tables"} 0`,
+		`packer_cache_table_bytes 0`,
+		`packer_cache_table_evictions_total 0`,
+	)
+}
+
+// assertMetrics asserts that the metrics served by the handler at /metrics have the expected lines.
+func assertMetrics(t *testing.T, handler http.Handler, expected ...string) {
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, metricsPath, nil))
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("unexpected status code: got '%d' want '%d'", rr.Code, http.StatusOK)
+	}
+	lines := strings.Split(rr.Body.String(), "\n")
+	for _, e := range expected {
+		if !slices.Contains(lines, e) {
+			t.Errorf("unexpected metrics without the line '%s'", e)
+		}
+	}
+}
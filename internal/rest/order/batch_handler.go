@@ -0,0 +1,313 @@
+package order
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"net/url"
+	"packer/internal/rest/order/pack"
+	"packer/internal/rest/order/repository"
+	"strings"
+	"time"
+)
+
+const (
+	batchPath         = "/batch"
+	maxBatchSize      = 10_000
+	ndjsonContentType = "application/x-ndjson"
+)
+
+var (
+	// errBatchSize is returned when a batch has more than maxBatchSize order sizes, or a JSON array has none.
+	errBatchSize = errors.New("invalid batch size")
+	// errBatchArray is returned by readBatchArray when the payload is not a JSON array.
+	errBatchArray = errors.New("invalid batch array")
+)
+
+// batchSource returns the next order size of a batch as read (i.e. not validated yet), io.EOF after the last one or
+// an error if the batch cannot be read further.
+type batchSource func() (json.RawMessage, error)
+
+// batchJob is an order size of a batch being quoted. Its result is sent to the buffered result channel.
+type batchJob struct {
+	index  int
+	size   json.RawMessage
+	err    error
+	result chan BatchItem
+}
+
+func isBatchPath(path string) bool {
+	return strings.TrimSuffix(path, "/") == batchPath
+}
+
+// handleQuoteBatch quotes a batch of order sizes, sent as a JSON array or as an NDJSON stream (one size per line), with
+// the product's config in effect at the time of the request (or at the as_of query parameter) and its current stock.
+// Every size is quoted with the whole stock. The quotes are not saved.
+// The config is loaded once and the sizes are computed on a pool of workers. The results are streamed back as NDJSON
+// in input order, one BatchItem per size, with the error of the sizes that cannot be computed instead of failing the
+// whole batch.
+func (h *Handler) handleQuoteBatch(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	product, at, err := parseBatchQuery(r.URL.Query())
+	if err != nil {
+		h.writeBadRequestResponse(w, errRespInvalidAsOf)
+		return
+	}
+
+	var opts batchOptions
+	var ok bool
+	if opts.solver, ok = h.solvers.resolve(r.URL.Query().Get("solver")); !ok {
+		h.writeBadRequestResponse(w, errRespInvalidSolver)
+		return
+	}
+	if opts.packSort, ok = parsePackSort(r.URL.Query()); !ok {
+		h.writeBadRequestResponse(w, errRespInvalidSort)
+		return
+	}
+
+	var next batchSource
+	if isNDJSON(r.Header.Get("Content-Type")) {
+		// the results are written while the stream is still being read
+		_ = http.NewResponseController(w).EnableFullDuplex()
+		next = newNDJSONBatchSource(r.Body)
+	} else {
+		sizes, err := readBatchArray(r.Body)
+		if errors.Is(err, errBatchSize) {
+			h.writeBadRequestResponse(w, errRespInvalidBatchSize)
+			return
+		}
+		if err != nil {
+			h.writeBadRequestResponse(w, errRespInvalidPayload)
+			return
+		}
+		next = newSliceBatchSource(sizes)
+	}
+
+	cfg, err := h.findConfig(ctx, product, at)
+	if errors.Is(err, repository.ErrNotFound) {
+		h.writeErrorResponse(w, errRespNoConfigInEffect, http.StatusUnprocessableEntity)
+		return
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "unable to find the config", "error", err)
+		h.writeInternalServerErrorResponse(w)
+		return
+	}
+
+	levels, err := h.repository.FindStockLevels(ctx, []string{product})
+	if err != nil {
+		slog.ErrorContext(ctx, "unable to find the stock levels", "error", err)
+		h.writeInternalServerErrorResponse(w)
+		return
+	}
+
+	w.Header().Set("Content-Type", ndjsonContentType)
+	w.WriteHeader(http.StatusOK)
+	h.quoteBatch(ctx, cfg, stockByProduct(levels)[product], opts, next, w)
+}
+
+// batchOptions are the name of the solver that computes the packs of a batch and the order of the packs (see
+// pack.SortPacks).
+type batchOptions struct {
+	solver   string
+	packSort string
+}
+
+// parseBatchQuery parses the product, which defaults to repository.DefaultProduct, and the as_of time, which defaults
+// to now.
+func parseBatchQuery(query url.Values) (string, time.Time, error) {
+	product := query.Get("product")
+	if product == "" {
+		product = repository.DefaultProduct
+	}
+
+	if !query.Has("as_of") {
+		return product, time.Now(), nil
+	}
+	at, err := time.Parse(time.RFC3339, query.Get("as_of"))
+	return product, at, err
+}
+
+func isNDJSON(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	return err == nil && mediaType == ndjsonContentType
+}
+
+// readBatchArray reads the sizes of a JSON array one by one, so that an array of more than maxBatchSize sizes is not
+// read past them. It returns errBatchSize if the array is empty or too large.
+func readBatchArray(r io.Reader) ([]json.RawMessage, error) {
+	decoder := json.NewDecoder(r)
+	if token, err := decoder.Token(); err != nil || token != json.Delim('[') {
+		return nil, errBatchArray
+	}
+
+	var sizes []json.RawMessage
+	for decoder.More() {
+		if len(sizes) == maxBatchSize {
+			return nil, errBatchSize
+		}
+		var size json.RawMessage
+		if err := decoder.Decode(&size); err != nil {
+			return nil, err
+		}
+		sizes = append(sizes, size)
+	}
+	if _, err := decoder.Token(); err != nil {
+		return nil, err
+	}
+	if len(sizes) == 0 {
+		return nil, errBatchSize
+	}
+	return sizes, nil
+}
+
+func newSliceBatchSource(sizes []json.RawMessage) batchSource {
+	i := 0
+	return func() (json.RawMessage, error) {
+		if i == len(sizes) {
+			return nil, io.EOF
+		}
+		i++
+		return sizes[i-1], nil
+	}
+}
+
+// newNDJSONBatchSource reads a size per line, skipping blank lines. It returns errBatchSize after maxBatchSize sizes.
+func newNDJSONBatchSource(r io.Reader) batchSource {
+	scanner := bufio.NewScanner(r)
+	count := 0
+	return func() (json.RawMessage, error) {
+		for scanner.Scan() {
+			line := bytes.TrimSpace(scanner.Bytes())
+			if len(line) == 0 {
+				continue
+			}
+			if count == maxBatchSize {
+				return nil, errBatchSize
+			}
+			count++
+			return bytes.Clone(line), nil
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+		return nil, io.EOF
+	}
+}
+
+// quoteBatch reads the sizes of the source and quotes them on a pool of h.batchWorkers workers, writing the results
+// in input order as soon as they are available.
+// At most 2*h.batchWorkers sizes are in flight, so a slow size holds back the reading of the source rather than
+// buffering the results after it. A source error is written as the error of its item and ends the batch.
+func (h *Handler) quoteBatch(ctx context.Context, cfg repository.Config, stock map[int]int, opts batchOptions, next batchSource, w io.Writer) {
+	jobs := make(chan batchJob)
+	pending := make(chan chan BatchItem, 2*h.batchWorkers)
+
+	for i := 0; i < h.batchWorkers; i++ {
+		go func() {
+			for job := range jobs {
+				job.result <- h.quoteBatchItem(ctx, cfg, stock, opts, job)
+			}
+		}()
+	}
+
+	go func() {
+		defer close(pending)
+		defer close(jobs)
+
+		for index := 0; ctx.Err() == nil; index++ {
+			size, err := next()
+			if errors.Is(err, io.EOF) {
+				return
+			}
+
+			job := batchJob{index: index, size: size, err: err, result: make(chan BatchItem, 1)}
+			pending <- job.result
+			jobs <- job
+
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	h.writeBatchItems(ctx, w, pending)
+}
+
+// writeBatchItems writes the results in the order they are pending, flushing them whenever there is no other result
+// pending. Write errors are logged once and the remaining results are drained.
+func (h *Handler) writeBatchItems(ctx context.Context, w io.Writer, pending <-chan chan BatchItem) {
+	bw := bufio.NewWriter(w)
+	encoder := json.NewEncoder(bw)
+	var writeErr error
+
+	for result := range pending {
+		item := <-result
+		if writeErr != nil {
+			continue
+		}
+
+		writeErr = encoder.Encode(item)
+		if writeErr == nil && len(pending) == 0 {
+			writeErr = bw.Flush()
+			if flusher, ok := w.(http.Flusher); ok && writeErr == nil {
+				flusher.Flush()
+			}
+		}
+		if writeErr != nil {
+			slog.ErrorContext(ctx, "unable to write the batch quotes", "error", writeErr)
+		}
+	}
+
+	if writeErr == nil {
+		if err := bw.Flush(); err != nil {
+			slog.ErrorContext(ctx, "unable to write the batch quotes", "error", err)
+		}
+	}
+}
+
+// quoteBatchItem computes the packs of the job's size with the options, or returns its error.
+func (h *Handler) quoteBatchItem(ctx context.Context, cfg repository.Config, stock map[int]int, opts batchOptions, job batchJob) BatchItem {
+	item := BatchItem{Index: job.index}
+
+	switch {
+	case errors.Is(job.err, errBatchSize):
+		return item.withError(errRespInvalidBatchSize)
+	case job.err != nil:
+		slog.ErrorContext(ctx, "unable to read the batch", "error", job.err)
+		return item.withError(errRespInvalidPayload)
+	case json.Unmarshal(job.size, &item.Size) != nil || item.Size <= 0:
+		return item.withError(errRespOrderSize)
+	}
+
+	packs, err := h.solvePacks(ctx, cfg, stock, item.Size, opts.solver, pack.ItemsThenPacks{})
+	if err != nil {
+		errResp, _, ok := createOrderErrorResponse(err)
+		if !ok {
+			slog.ErrorContext(ctx, "unable to compute the packs", "error", err)
+			errResp = errRespInternalServerError
+		}
+		return item.withError(errResp)
+	}
+
+	pack.SortPacks(packs, opts.packSort)
+	totals := newTotals(item.Size, packs)
+	item.Packs = packs
+	item.ConfigVersion = cfg.Version
+	item.Solver = opts.solver
+	item.Totals = &totals
+	return item
+}
+
+func (item BatchItem) withError(errResp ErrorResponse) BatchItem {
+	item.Code = errResp.Code
+	item.Message = errResp.Message
+	return item
+}
@@ -0,0 +1,199 @@
+package order
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"packer/internal/rest/order/pack"
+	"packer/internal/rest/order/repository"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestDelayedPackComputer computes a pack of the order size, waiting longer for smaller order sizes.
+type TestDelayedPackComputer struct{}
+
+func (comp *TestDelayedPackComputer) ComputePacksWithObjective(_ context.Context, _ []int, _ map[int]int, orderSize int, _ pack.Objective) ([]pack.Pack, error) {
+	time.Sleep(time.Duration(10-min(orderSize, 10)) * time.Millisecond)
+	return []pack.Pack{{Size: orderSize, Quantity: 1}}, nil
+}
+
+func TestServeHTTP_HandleQuoteBatch_Success(t *testing.T) {
+	comp := TestPackComputerBySize{
+		results: map[int][]pack.Pack{
+			1:   {{Size: 250, Quantity: 1}},
+			251: {{Size: 500, Quantity: 1}},
+		},
+		errs: map[int]error{
+			2: pack.ErrInsufficientStock,
+		},
+	}
+	repo := TestSuccessRepository{result: repository.Config{PackSizes: []int{250, 500}, Version: 3}}
+	handler := NewHandler(&comp, &repo)
+
+	rr := httptest.NewRecorder()
+	req := newQuoteBatchRequest(t, "", "", `[1, -1, 2, "a", 251]`)
+
+	handler.ServeHTTP(rr, req)
+
+	assertStatusOk(t, rr)
+	assertHeader(t, rr, "Content-Type", ndjsonContentType)
+	assertBody(t, rr, `{"index":0,"size":1,"packs":[{"size":250,"quantity":1}],"config_version":3,"solver":"dp",`+
+		`"items_shipped":250,"overshoot":249,"pack_count":1}`+"\n"+
+		`{"index":1,"size":-1,"error_code":"invalid_order_size","error_message":"Order sizes must be greater than zero."}`+"\n"+
+		`{"index":2,"size":2,"error_code":"insufficient_stock","error_message":"`+errRespInsufficientStock.Message+`"}`+"\n"+
+		`{"index":3,"error_code":"invalid_order_size","error_message":"Order sizes must be greater than zero."}`+"\n"+
+		`{"index":4,"size":251,"packs":[{"size":500,"quantity":1}],"config_version":3,"solver":"dp",`+
+		`"items_shipped":500,"overshoot":249,"pack_count":1}`+"\n")
+	if repo.passedProduct != repository.DefaultProduct {
+		t.Errorf("unexpected product passed to repository: got '%s' want '%s'", repo.passedProduct, repository.DefaultProduct)
+	}
+}
+
+func TestServeHTTP_HandleQuoteBatch_NDJSON(t *testing.T) {
+	handler := NewHandler(&TestDelayedPackComputer{}, &TestSuccessRepository{})
+	handler.batchWorkers = 4
+
+	var payload, expected strings.Builder
+	for i := 0; i < 20; i++ {
+		size := i%10 + 1
+		payload.WriteString(fmt.Sprintf("%d\n\n", size))
+		expected.WriteString(fmt.Sprintf(`{"index":%d,"size":%d,"packs":[{"size":%d,"quantity":1}],"solver":"dp",`+
+			`"items_shipped":%d,"overshoot":0,"pack_count":1}`+"\n", i, size, size, size))
+	}
+
+	rr := httptest.NewRecorder()
+	req := newQuoteBatchRequest(t, ndjsonContentType, "", payload.String())
+
+	handler.ServeHTTP(rr, req)
+
+	assertStatusOk(t, rr)
+	assertBody(t, rr, expected.String())
+}
+
+func TestServeHTTP_HandleQuoteBatch_NDJSONTooLarge(t *testing.T) {
+	handler := NewHandler(&TestPackComputer{}, &TestSuccessRepository{})
+
+	rr := httptest.NewRecorder()
+	req := newQuoteBatchRequest(t, ndjsonContentType, "", strings.Repeat("-1\n", maxBatchSize+2))
+
+	handler.ServeHTTP(rr, req)
+
+	assertStatusOk(t, rr)
+	lines := strings.Split(strings.TrimSuffix(rr.Body.String(), "\n"), "\n")
+	if len(lines) != maxBatchSize+1 {
+		t.Fatalf("unexpected number of items: got '%d' want '%d'", len(lines), maxBatchSize+1)
+	}
+	expected := fmt.Sprintf(`{"index":%d,"error_code":"invalid_batch_size","error_message":"%s"}`,
+		maxBatchSize, errRespInvalidBatchSize.Message)
+	if lines[maxBatchSize] != expected {
+		t.Errorf("unexpected last item: got '%s' want '%s'", lines[maxBatchSize], expected)
+	}
+}
+
+func TestServeHTTP_HandleQuoteBatch_ProductAndAsOf(t *testing.T) {
+	repo := TestSuccessRepository{}
+	handler := NewHandler(&TestPackComputer{}, &repo)
+
+	rr := httptest.NewRecorder()
+	req := newQuoteBatchRequest(t, "", "?product=tea&as_of=2030-01-01T00:00:00Z", `[1]`)
+
+	handler.ServeHTTP(rr, req)
+
+	assertStatusOk(t, rr)
+	if repo.passedProduct != "tea" {
+		t.Errorf("unexpected product passed to repository: got '%s' want '%s'", repo.passedProduct, "tea")
+	}
+	asOf := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	if !repo.passedAt.Equal(asOf) {
+		t.Errorf("unexpected time passed to repository: got '%v' want '%v'", repo.passedAt, asOf)
+	}
+}
+
+func TestServeHTTP_HandleQuoteBatch_BadRequest(t *testing.T) {
+	data := []struct {
+		query           string
+		payload         string
+		expectedErrCode string
+	}{
+		{
+			payload:         `{"size": 1}`,
+			expectedErrCode: "invalid_payload",
+		},
+		{
+			payload:         `[]`,
+			expectedErrCode: "invalid_batch_size",
+		},
+		{
+			payload:         `[` + strings.Repeat(`1,`, maxBatchSize) + `1]`,
+			expectedErrCode: "invalid_batch_size",
+		},
+		{
+			// the sizes past the maximum are not read
+			payload:         `[` + strings.Repeat(`1,`, maxBatchSize+1) + `}`,
+			expectedErrCode: "invalid_batch_size",
+		},
+		{
+			payload:         `[1,}`,
+			expectedErrCode: "invalid_payload",
+		},
+		{
+			payload:         `[1`,
+			expectedErrCode: "invalid_payload",
+		},
+		{
+			query:           "?as_of=tomorrow",
+			payload:         `[1]`,
+			expectedErrCode: "invalid_as_of",
+		},
+	}
+
+	for _, d := range data {
+		t.Run(fmt.Sprintf("with error code: %s", d.expectedErrCode), func(t *testing.T) {
+			handler := NewHandler(&TestPackComputer{}, &TestSuccessRepository{})
+
+			rr := httptest.NewRecorder()
+			req := newQuoteBatchRequest(t, "", d.query, d.payload)
+
+			handler.ServeHTTP(rr, req)
+
+			assertErrorResponse(t, rr, http.StatusBadRequest, d.expectedErrCode)
+		})
+	}
+}
+
+func TestServeHTTP_HandleQuoteBatch_NoConfigInEffect(t *testing.T) {
+	handler := NewHandler(&TestPackComputer{}, &TestSuccessRepository{findConfigErr: repository.ErrNotFound})
+
+	rr := httptest.NewRecorder()
+	req := newQuoteBatchRequest(t, "", "", `[1]`)
+
+	handler.ServeHTTP(rr, req)
+
+	assertErrorResponse(t, rr, http.StatusUnprocessableEntity, "no_config_in_effect")
+}
+
+func TestServeHTTP_HandleQuoteBatch_InternalServerError(t *testing.T) {
+	handler := NewHandler(&TestPackComputer{}, &TestErrRepository{})
+
+	rr := httptest.NewRecorder()
+	req := newQuoteBatchRequest(t, "", "", `[1]`)
+
+	handler.ServeHTTP(rr, req)
+
+	assertInternalServerErrorResponse(t, rr)
+}
+
+func newQuoteBatchRequest(t *testing.T, contentType, query, payload string) *http.Request {
+	req, err := http.NewRequest(http.MethodPost, batchPath+query, bytes.NewReader([]byte(payload)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	return req
+}
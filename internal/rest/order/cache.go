@@ -0,0 +1,306 @@
+package order
+
+import (
+	"container/list"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"packer/internal/rest/order/pack"
+	"packer/internal/rest/order/repository"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	cachePath = "/cache"
+
+	// DefaultConfigCacheTTL is how long a config found in effect is assumed to stay in effect, i.e. how long the other
+	// instances may use a config after it is set (see Cache).
+	DefaultConfigCacheTTL = 5 * time.Second
+	// DefaultTableCacheBytes is the default memory limit of the solution tables.
+	DefaultTableCacheBytes = 64 << 20
+)
+
+func isCachePath(path string) bool {
+	return strings.TrimSuffix(path, "/") == cachePath
+}
+
+// Cache keeps the configs in effect of the products for a while and a solution table of every set of pack sizes (see
+// pack.Table), so that the orders are computed without finding the config in the repository and without solving them
+// once the table is built.
+// A config found in effect at some time is assumed to stay in effect for the config TTL, unless the product's config is
+// set in the meantime on this instance. The configs with a later version, which may be scheduled to take effect within
+// the TTL, are not cached. The cache is not shared between instances: a config set or deleted on another
+// instance is only picked up once the TTL expires, so the orders may be computed with the previous config version until
+// then. The tables are kept by their sorted pack sizes, which are all they depend on, so they are shared by the configs
+// with the same pack sizes and never used for other ones, even if a product is deleted and its config versions reused.
+// The tables use at most the given bytes of memory, the least recently used ones are evicted first.
+type Cache struct {
+	computer   pack.Computer
+	configTTL  time.Duration
+	tableBytes int
+
+	mu      sync.Mutex
+	configs map[string]cachedConfig
+	// generation changes with every invalidation, so that the configs found before are not cached after it.
+	generation   uint64
+	configHits   int64
+	configMisses int64
+	tables       map[tableKey]*list.Element
+	// recentTables has the built tables, the most recently used first.
+	recentTables   *list.List
+	usedTableBytes int
+	tableHits      int64
+	tableMisses    int64
+	tableEvictions int64
+}
+
+// cachedConfig is a config found in effect at some time.
+type cachedConfig struct {
+	cfg repository.Config
+	at  time.Time
+}
+
+// tableKey is the pack sizes of a solution table, without duplicates and sorted from the smallest.
+type tableKey string
+
+// newTableKey returns the key of the solution table of the pack sizes, which does not depend on their order.
+func newTableKey(packSizes []int) tableKey {
+	return tableKey(fmt.Sprint(pack.RemoveDuplicateSizes(packSizes)))
+}
+
+// cachedTable is a solution table, which is ready once it is built or fails to be built.
+type cachedTable struct {
+	key   tableKey
+	table *pack.Table
+	err   error
+	ready chan struct{}
+}
+
+// NewCache creates a cache of the configs for the TTL and of their solution tables for up to tableBytes of memory.
+// A TTL of zero disables the configs' cache and a tableBytes of zero the solution tables.
+func NewCache(configTTL time.Duration, tableBytes int) *Cache {
+	return &Cache{
+		computer:     pack.NewComputer(),
+		configTTL:    configTTL,
+		tableBytes:   tableBytes,
+		configs:      make(map[string]cachedConfig),
+		tables:       make(map[tableKey]*list.Element),
+		recentTables: list.New(),
+	}
+}
+
+// findConfig returns the config of the product in effect at the given time from the cache, or finds it in the
+// repository and caches it if it is in effect now and is the latest version.
+func (c *Cache) findConfig(ctx context.Context, repo Repository, product string, at time.Time) (repository.Config, error) {
+	c.mu.Lock()
+	cached, ok := c.configs[product]
+	if ok && !at.Before(cached.at) && at.Before(cached.at.Add(c.configTTL)) {
+		c.configHits++
+		c.mu.Unlock()
+		return cached.cfg, nil
+	}
+	c.configMisses++
+	generation := c.generation
+	c.mu.Unlock()
+
+	cfg, err := repo.FindConfig(ctx, product, at)
+	if err != nil {
+		return repository.Config{}, err
+	}
+
+	now := time.Now()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	// a config in effect in the future or too long ago may not be the one in effect now, and a later version may take
+	// effect before the TTL expires
+	if c.configTTL > 0 && c.generation == generation && !at.After(now) && now.Before(at.Add(c.configTTL)) &&
+		cfg.LatestVersion <= cfg.Version {
+		c.configs[product] = cachedConfig{cfg: cfg, at: at}
+	}
+	return cfg, nil
+}
+
+// tablePacks returns the packs of the order size from the solution table of the config's pack sizes, building the table
+// if it is not cached yet.
+// It returns false if the table would use more memory than the cache allows, or pack.ErrCancelled if the context is
+// done before the table is built.
+func (c *Cache) tablePacks(ctx context.Context, cfg repository.Config, orderSize int) ([]pack.Pack, bool, error) {
+	entry, err := c.findTable(cfg)
+	if entry == nil || err != nil {
+		return nil, false, err
+	}
+
+	select {
+	case <-entry.ready:
+	case <-ctx.Done():
+		return nil, false, fmt.Errorf("%w: %w", pack.ErrCancelled, ctx.Err())
+	}
+
+	if entry.err != nil {
+		return nil, false, entry.err
+	}
+
+	packs, err := entry.table.Packs(orderSize)
+	if err != nil {
+		return nil, false, err
+	}
+	return packs, true, nil
+}
+
+// findTable returns the cached table of the config's pack sizes, or starts building it. It returns nil if the table would
+// use more memory than the cache allows.
+// The table is built in the background, so that the requests that wait for it can be cancelled without wasting it.
+func (c *Cache) findTable(cfg repository.Config) (*cachedTable, error) {
+	key := newTableKey(cfg.PackSizes)
+
+	c.mu.Lock()
+	if element, ok := c.tables[key]; ok {
+		c.tableHits++
+		c.recentTables.MoveToFront(element)
+		c.mu.Unlock()
+		return element.Value.(*cachedTable), nil
+	}
+	c.tableMisses++
+	c.mu.Unlock()
+
+	bytes, err := c.computer.TableBytes(cfg.PackSizes)
+	if errors.Is(err, pack.ErrInputTooLarge) || err == nil && bytes > c.tableBytes {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	if element, ok := c.tables[key]; ok {
+		c.mu.Unlock()
+		return element.Value.(*cachedTable), nil
+	}
+	entry := &cachedTable{key: key, ready: make(chan struct{})}
+	c.tables[key] = c.recentTables.PushFront(entry)
+	c.mu.Unlock()
+
+	go c.buildTable(entry, cfg.PackSizes)
+	return entry, nil
+}
+
+// buildTable builds the table of the entry and makes room for it by evicting the least recently used tables.
+// The entry is dropped if it fails to be built or was invalidated in the meantime.
+func (c *Cache) buildTable(entry *cachedTable, packSizes []int) {
+	entry.table, entry.err = c.computer.ComputeTable(context.Background(), packSizes)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	defer close(entry.ready)
+
+	element, ok := c.tables[entry.key]
+	if !ok || element.Value != entry {
+		return
+	}
+	if entry.err != nil {
+		c.removeTable(element)
+		return
+	}
+
+	c.usedTableBytes += entry.table.Bytes()
+	for c.usedTableBytes > c.tableBytes {
+		oldest := c.recentTables.Back()
+		for oldest != nil && (oldest == element || oldest.Value.(*cachedTable).table == nil) {
+			oldest = oldest.Prev()
+		}
+		if oldest == nil {
+			break
+		}
+		c.removeTable(oldest)
+		c.tableEvictions++
+	}
+}
+
+// removeTable removes the table of the element, which must be locked, from the cache.
+func (c *Cache) removeTable(element *list.Element) {
+	entry := element.Value.(*cachedTable)
+	delete(c.tables, entry.key)
+	c.recentTables.Remove(element)
+	if entry.table != nil && entry.err == nil {
+		c.usedTableBytes -= entry.table.Bytes()
+	}
+}
+
+// invalidate removes the config of the product from the cache. Its solution tables are kept, since they only depend on
+// the pack sizes.
+func (c *Cache) invalidate(product string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.generation++
+	delete(c.configs, product)
+}
+
+// Stats returns the entries and the hits and misses of the configs and the solution tables.
+func (c *Cache) Stats() CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return CacheStats{
+		Configs: newCacheCounters(len(c.configs), c.configHits, c.configMisses),
+		Tables: TableCacheStats{
+			CacheCounters: newCacheCounters(len(c.tables), c.tableHits, c.tableMisses),
+			Bytes:         c.usedTableBytes,
+			MaxBytes:      c.tableBytes,
+			Evictions:     c.tableEvictions,
+		},
+	}
+}
+
+// findConfig finds the config of the product in effect at the given time, in the cache if the handler has one.
+func (h *Handler) findConfig(ctx context.Context, product string, at time.Time) (repository.Config, error) {
+	if h.cache == nil {
+		return h.repository.FindConfig(ctx, product, at)
+	}
+	return h.cache.findConfig(ctx, h.repository, product, at)
+}
+
+// solvePacks computes the packs of the order size with the solver and the objective, or looks them up in the solution
+// table of the config if the handler has a cache and they are the packs pack.SolverDP computes for pack.ItemsThenPacks
+// within the stock.
+func (h *Handler) solvePacks(ctx context.Context, cfg repository.Config, stock map[int]int, orderSize int, solver string, objective pack.Objective) ([]pack.Pack, error) {
+	if _, ok := objective.(pack.ItemsThenPacks); ok && h.cache != nil && solver == pack.SolverDP {
+		packs, ok, err := h.cache.tablePacks(ctx, cfg, orderSize)
+		if err != nil {
+			return nil, err
+		}
+		if ok && pack.InStock(packs, stock) {
+			return packs, nil
+		}
+	}
+	return h.solvers.get(solver).ComputePacksWithObjective(ctx, cfg.PackSizes, stock, orderSize, objective)
+}
+
+// invalidateCache removes the config of the product from the cache if the handler has one.
+func (h *Handler) invalidateCache(product string) {
+	if h.cache != nil {
+		h.cache.invalidate(product)
+	}
+}
+
+// handleGetCacheStats writes the statistics of the cache, which are all zero if the handler has none.
+func (h *Handler) handleGetCacheStats(w http.ResponseWriter, r *http.Request) {
+	var stats CacheStats
+	if h.cache != nil {
+		stats = h.cache.Stats()
+	}
+
+	jsonBytes, err := json.Marshal(stats)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "unable to encode the response", "error", err)
+		h.writeInternalServerErrorResponse(w)
+		return
+	}
+
+	h.writeMessageWithStatusResponse(w, jsonBytes, http.StatusOK)
+}
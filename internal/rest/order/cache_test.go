@@ -0,0 +1,346 @@
+package order
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"packer/internal/rest/order/pack"
+	"packer/internal/rest/order/repository"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// TestCountingRepository counts the configs found in the repository.
+type TestCountingRepository struct {
+	TestSuccessRepository
+	findConfigCalls int
+}
+
+func (repo *TestCountingRepository) FindConfig(ctx context.Context, product string, at time.Time) (repository.Config, error) {
+	repo.findConfigCalls++
+	return repo.TestSuccessRepository.FindConfig(ctx, product, at)
+}
+
+func TestServeHTTP_HandleCreateOrder_Cache(t *testing.T) {
+	comp := TestPackComputer{result: []pack.Pack{{Size: 250, Quantity: 49}}}
+	repo := TestCountingRepository{
+		TestSuccessRepository: TestSuccessRepository{
+			result: repository.Config{Version: 1, PackSizes: []int{250, 500, 1000, 2000, 5000}},
+		},
+	}
+	handler := NewHandlerWithCache(newTestSolvers(&comp), &repo, DefaultQuoteTTL, NewCache(time.Minute, DefaultTableCacheBytes))
+
+	for i := 0; i < 2; i++ {
+		order := doCreateOrder(t, &handler, "", `{"size": 12001}`)
+
+		expectedPacks := []pack.Pack{{Size: 5000, Quantity: 2}, {Size: 2000, Quantity: 1}, {Size: 250, Quantity: 1}}
+		if !reflect.DeepEqual(order.Packs, expectedPacks) {
+			t.Errorf("unexpected packs: got '%+v' want '%+v'", order.Packs, expectedPacks)
+		}
+	}
+
+	if comp.passedOrderSize != 0 {
+		t.Errorf("unexpected packs computation of order size: '%d'", comp.passedOrderSize)
+	}
+	if repo.findConfigCalls != 1 {
+		t.Errorf("unexpected configs found: got '%d' want '%d'", repo.findConfigCalls, 1)
+	}
+	expectedStats := CacheStats{
+		Configs: CacheCounters{Entries: 1, Hits: 1, Misses: 1, HitRatio: 0.5},
+		Tables: TableCacheStats{
+			CacheCounters: CacheCounters{Entries: 1, Hits: 1, Misses: 1, HitRatio: 0.5},
+			Bytes:         400 * strconv.IntSize / 8,
+			MaxBytes:      DefaultTableCacheBytes,
+		},
+	}
+	assertCacheStats(t, &handler, expectedStats)
+}
+
+func TestServeHTTP_HandleCreateOrder_CacheBypassed(t *testing.T) {
+	data := []struct {
+		description string
+		query       string
+		payload     string
+		stock       []repository.StockLevel
+		tableBytes  int
+	}{
+		{
+			description: "cost objective",
+			payload:     `{"size": 12001, "objective": "cost"}`,
+			tableBytes:  DefaultTableCacheBytes,
+		},
+		{
+			description: "other solver",
+			query:       "?solver=greedy",
+			payload:     `{"size": 12001}`,
+			tableBytes:  DefaultTableCacheBytes,
+		},
+		{
+			description: "packs out of stock",
+			payload:     `{"size": 12001}`,
+			stock:       []repository.StockLevel{{Product: repository.DefaultProduct, PackSize: 5000, Quantity: 1}},
+			tableBytes:  DefaultTableCacheBytes,
+		},
+		{
+			description: "table over the memory limit",
+			payload:     `{"size": 12001}`,
+			tableBytes:  100,
+		},
+	}
+
+	for _, d := range data {
+		t.Run(fmt.Sprintf("with %s", d.description), func(t *testing.T) {
+			comp := TestPackComputer{result: []pack.Pack{{Size: 250, Quantity: 49}}}
+			solvers := NewSolvers(map[string]PacksComputer{pack.SolverDP: &comp, pack.SolverGreedy: &comp}, pack.SolverDP)
+			repo := TestSuccessRepository{
+				result: repository.Config{
+					PackSizes: []int{250, 500, 1000, 2000, 5000},
+					PackCosts: map[int]int{250: 1, 500: 1, 1000: 1, 2000: 1, 5000: 1},
+				},
+				stockResult: d.stock,
+			}
+			handler := NewHandlerWithCache(solvers, &repo, DefaultQuoteTTL, NewCache(time.Minute, d.tableBytes))
+
+			order := doCreateOrder(t, &handler, d.query, d.payload)
+
+			if !reflect.DeepEqual(order.Packs, comp.result) {
+				t.Errorf("unexpected packs: got '%+v' want '%+v'", order.Packs, comp.result)
+			}
+			if comp.passedOrderSize != 12001 {
+				t.Errorf("unexpected order size: got '%d' want '%d'", comp.passedOrderSize, 12001)
+			}
+		})
+	}
+}
+
+func TestServeHTTP_HandleSetConfig_InvalidatesCache(t *testing.T) {
+	comp := TestPackComputer{}
+	repo := TestCountingRepository{
+		TestSuccessRepository: TestSuccessRepository{result: repository.Config{Version: 1, PackSizes: []int{250, 500}}},
+	}
+	handler := NewHandlerWithCache(newTestSolvers(&comp), &repo, DefaultQuoteTTL, NewCache(time.Minute, DefaultTableCacheBytes))
+	doCreateOrder(t, &handler, "", `{"size": 251}`)
+
+	rr := httptest.NewRecorder()
+	req, err := http.NewRequest(http.MethodPut, configPath, bytes.NewReader([]byte(`{"pack_sizes": [300]}`)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("If-Match", "*")
+	handler.ServeHTTP(rr, req)
+	assertStatusOk(t, rr)
+
+	repo.result = repository.Config{Version: 2, PackSizes: []int{300}}
+	order := doCreateOrder(t, &handler, "", `{"size": 251}`)
+
+	expectedPacks := []pack.Pack{{Size: 300, Quantity: 1}}
+	if !reflect.DeepEqual(order.Packs, expectedPacks) {
+		t.Errorf("unexpected packs: got '%+v' want '%+v'", order.Packs, expectedPacks)
+	}
+	if repo.findConfigCalls != 2 {
+		t.Errorf("unexpected configs found: got '%d' want '%d'", repo.findConfigCalls, 2)
+	}
+	expectedStats := CacheStats{
+		Configs: CacheCounters{Entries: 1, Misses: 2},
+		Tables: TableCacheStats{
+			CacheCounters: CacheCounters{Entries: 2, Misses: 2},
+			Bytes:         5 * strconv.IntSize / 8,
+			MaxBytes:      DefaultTableCacheBytes,
+		},
+	}
+	assertCacheStats(t, &handler, expectedStats)
+}
+
+func TestServeHTTP_HandleCreateOrder_CacheEvictsLeastRecentlyUsedTables(t *testing.T) {
+	comp := TestPackComputer{}
+	repo := TestSuccessRepository{}
+	tableBytes := 2809 * strconv.IntSize / 8
+	handler := NewHandlerWithCache(newTestSolvers(&comp), &repo, DefaultQuoteTTL, NewCache(0, tableBytes))
+
+	for version, packSizes := range [][]int{{23, 31, 53}, {23, 31, 53}, {23, 31, 52}} {
+		repo.result = repository.Config{Version: int64(version), PackSizes: packSizes}
+		doCreateOrder(t, &handler, "", `{"size": 500000}`)
+	}
+
+	expectedStats := CacheStats{
+		Configs: CacheCounters{Misses: 3},
+		Tables: TableCacheStats{
+			CacheCounters: CacheCounters{Entries: 1, Hits: 1, Misses: 2, HitRatio: float64(1) / 3},
+			Bytes:         52 * 52 * strconv.IntSize / 8,
+			MaxBytes:      tableBytes,
+			Evictions:     1,
+		},
+	}
+	assertCacheStats(t, &handler, expectedStats)
+}
+
+func TestServeHTTP_HandleCreateOrder_CacheKeysTablesByPackSizes(t *testing.T) {
+	comp := TestPackComputer{}
+	repo := TestSuccessRepository{}
+	handler := NewHandlerWithCache(newTestSolvers(&comp), &repo, DefaultQuoteTTL, NewCache(0, DefaultTableCacheBytes))
+
+	// the product is deleted and created again, so its first config version is reused for other pack sizes
+	data := []struct {
+		cfg           repository.Config
+		expectedPacks []pack.Pack
+	}{
+		{cfg: repository.Config{Version: 1, PackSizes: []int{250, 500}}, expectedPacks: []pack.Pack{{Size: 500, Quantity: 1}}},
+		{cfg: repository.Config{Version: 1, PackSizes: []int{300}}, expectedPacks: []pack.Pack{{Size: 300, Quantity: 1}}},
+		{cfg: repository.Config{Version: 2, PackSizes: []int{500, 250}}, expectedPacks: []pack.Pack{{Size: 500, Quantity: 1}}},
+	}
+	for _, d := range data {
+		repo.result = d.cfg
+		order := doCreateOrder(t, &handler, "", `{"size": 251}`)
+
+		if !reflect.DeepEqual(order.Packs, d.expectedPacks) {
+			t.Errorf("unexpected packs of %v: got '%+v' want '%+v'", d.cfg.PackSizes, order.Packs, d.expectedPacks)
+		}
+	}
+
+	expectedStats := CacheStats{
+		Configs: CacheCounters{Misses: 3},
+		Tables: TableCacheStats{
+			CacheCounters: CacheCounters{Entries: 2, Hits: 1, Misses: 2, HitRatio: float64(1) / 3},
+			Bytes:         5 * strconv.IntSize / 8,
+			MaxBytes:      DefaultTableCacheBytes,
+		},
+	}
+	assertCacheStats(t, &handler, expectedStats)
+}
+
+func TestServeHTTP_HandleCreateOrder_CacheSkipsPastConfigs(t *testing.T) {
+	comp := TestPackComputer{}
+	repo := TestCountingRepository{
+		TestSuccessRepository: TestSuccessRepository{result: repository.Config{Version: 1, PackSizes: []int{250, 500}}},
+	}
+	handler := NewHandlerWithCache(newTestSolvers(&comp), &repo, DefaultQuoteTTL, NewCache(time.Minute, DefaultTableCacheBytes))
+
+	for i := 0; i < 2; i++ {
+		doCreateOrder(t, &handler, "", `{"size": 251, "as_of": "2024-01-01T00:00:00Z"}`)
+	}
+
+	if repo.findConfigCalls != 2 {
+		t.Errorf("unexpected configs found: got '%d' want '%d'", repo.findConfigCalls, 2)
+	}
+}
+
+// TestScheduledRepository finds the scheduled config once it takes effect, and the config in effect before.
+type TestScheduledRepository struct {
+	TestCountingRepository
+	scheduled repository.Config
+}
+
+func (repo *TestScheduledRepository) FindConfig(ctx context.Context, product string, at time.Time) (repository.Config, error) {
+	cfg, err := repo.TestCountingRepository.FindConfig(ctx, product, at)
+	if !at.Before(repo.scheduled.EffectiveFrom) {
+		return repo.scheduled, err
+	}
+	cfg.LatestVersion = repo.scheduled.Version
+	return cfg, err
+}
+
+func TestServeHTTP_HandleCreateOrder_CacheSkipsConfigsWithScheduledVersions(t *testing.T) {
+	comp := TestPackComputer{}
+	repo := TestScheduledRepository{
+		TestCountingRepository: TestCountingRepository{
+			TestSuccessRepository: TestSuccessRepository{result: repository.Config{Version: 1, LatestVersion: 1, PackSizes: []int{250, 500}}},
+		},
+		scheduled: repository.Config{Version: 2, LatestVersion: 2, PackSizes: []int{300}, EffectiveFrom: time.Now().Add(50 * time.Millisecond)},
+	}
+	handler := NewHandlerWithCache(newTestSolvers(&comp), &repo, DefaultQuoteTTL, NewCache(time.Minute, DefaultTableCacheBytes))
+
+	order := doCreateOrder(t, &handler, "", `{"size": 251}`)
+	if expectedPacks := []pack.Pack{{Size: 500, Quantity: 1}}; !reflect.DeepEqual(order.Packs, expectedPacks) {
+		t.Errorf("unexpected packs before the scheduled version: got '%+v' want '%+v'", order.Packs, expectedPacks)
+	}
+
+	time.Sleep(time.Until(repo.scheduled.EffectiveFrom))
+	for i := 0; i < 2; i++ {
+		order = doCreateOrder(t, &handler, "", `{"size": 251}`)
+		if expectedPacks := []pack.Pack{{Size: 300, Quantity: 1}}; !reflect.DeepEqual(order.Packs, expectedPacks) {
+			t.Errorf("unexpected packs of the scheduled version: got '%+v' want '%+v'", order.Packs, expectedPacks)
+		}
+	}
+
+	if repo.findConfigCalls != 2 {
+		t.Errorf("unexpected configs found: got '%d' want '%d'", repo.findConfigCalls, 2)
+	}
+}
+
+func TestServeHTTP_HandleQuoteBatch_Cache(t *testing.T) {
+	comp := TestPackComputer{}
+	repo := TestCountingRepository{
+		TestSuccessRepository: TestSuccessRepository{result: repository.Config{Version: 1, PackSizes: []int{23, 31, 53}}},
+	}
+	handler := NewHandlerWithCache(newTestSolvers(&comp), &repo, DefaultQuoteTTL, NewCache(time.Minute, DefaultTableCacheBytes))
+
+	rr := httptest.NewRecorder()
+	req, err := http.NewRequest(http.MethodPost, batchPath, bytes.NewReader([]byte(`[24, 500000]`)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	handler.ServeHTTP(rr, req)
+
+	assertStatusOk(t, rr)
+	if comp.passedOrderSize != 0 {
+		t.Errorf("unexpected packs computation of order size: '%d'", comp.passedOrderSize)
+	}
+	assertBody(t, rr, `{"index":0,"size":24,"packs":[{"size":31,"quantity":1}],"config_version":1,"solver":"dp",`+
+		`"items_shipped":31,"overshoot":7,"pack_count":1}`+"\n"+
+		`{"index":1,"size":500000,"packs":[{"size":53,"quantity":9429},{"size":31,"quantity":7},{"size":23,"quantity":2}],`+
+		`"config_version":1,"solver":"dp","items_shipped":500000,"overshoot":0,"pack_count":9438}`+"\n")
+}
+
+func TestServeHTTP_HandleGetCacheStats_NoCache(t *testing.T) {
+	comp := TestPackComputer{}
+	repo := TestSuccessRepository{}
+	handler := NewHandler(&comp, &repo)
+
+	assertCacheStats(t, &handler, CacheStats{})
+}
+
+func newTestSolvers(comp PacksComputer) Solvers {
+	return NewSolvers(map[string]PacksComputer{pack.SolverDP: comp}, pack.SolverDP)
+}
+
+func doCreateOrder(t *testing.T, handler *Handler, query, payload string) Order {
+	rr := httptest.NewRecorder()
+	req, err := http.NewRequest(http.MethodPost, Path+query, bytes.NewReader([]byte(payload)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	handler.ServeHTTP(rr, req)
+
+	assertStatusOk(t, rr)
+	var order Order
+	if err = json.NewDecoder(rr.Body).Decode(&order); err != nil {
+		t.Fatal(err)
+	}
+	return order
+}
+
+func assertCacheStats(t *testing.T, handler *Handler, expected CacheStats) {
+	rr := httptest.NewRecorder()
+	req, err := http.NewRequest(http.MethodGet, cachePath, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	handler.ServeHTTP(rr, req)
+
+	assertStatusOk(t, rr)
+	var stats CacheStats
+	if err = json.NewDecoder(rr.Body).Decode(&stats); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(stats, expected) {
+		t.Errorf("unexpected cache stats: got '%+v' want '%+v'", stats, expected)
+	}
+}
@@ -0,0 +1,279 @@
+package order
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"net/http"
+	"packer/internal/rest/order/pack"
+	"packer/internal/rest/order/repository"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// defaultConfigAuthor is the author kept in the config history when none is given.
+	defaultConfigAuthor = "anonymous"
+	// maxPackCost bounds the pack costs and the handling fee, so that the cost of an order cannot overflow.
+	maxPackCost = math.MaxInt32
+)
+
+func isConfigPath(path string) bool {
+	return strings.HasSuffix(strings.TrimSuffix(path, "/"), configPath)
+}
+
+func isConfigVersionsPath(path string) bool {
+	return strings.HasSuffix(strings.TrimSuffix(path, "/"), configVersionsPath)
+}
+
+func isRestoreConfigVersionPath(path string) bool {
+	_, ok := parseRestoreConfigVersion(path)
+	return ok
+}
+
+// parseRestoreConfigVersion parses the config version from a restore path (e.g. 3 for
+// "/orders/config/versions/3/restore").
+func parseRestoreConfigVersion(path string) (int64, bool) {
+	versionPath, ok := strings.CutSuffix(path, restoreConfigSuffix)
+	if !ok {
+		return 0, false
+	}
+
+	i := strings.LastIndex(versionPath, "/")
+	if i < 0 || !strings.HasSuffix(versionPath[:i], configVersionsPath) {
+		return 0, false
+	}
+
+	version, err := strconv.ParseInt(versionPath[i+1:], 10, 64)
+	if err != nil || version <= 0 {
+		return 0, false
+	}
+	return version, true
+}
+
+// handleGetConfig writes the config in effect with its version as ETag, or 304 Not Modified if the If-None-Match header
+// matches the version.
+func (h *Handler) handleGetConfig(w http.ResponseWriter, r *http.Request, product string) {
+	ctx := r.Context()
+
+	cfg, err := h.repository.FindConfig(ctx, product, time.Now())
+	if errors.Is(err, repository.ErrNotFound) {
+		h.writeErrorResponse(w, errRespNoConfigInEffect, http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "unable to find the config", "error", err)
+		h.writeInternalServerErrorResponse(w)
+		return
+	}
+
+	setConfigVersionHeaders(w, cfg)
+
+	etag := formatETag(cfg.LatestVersion, cfg.Version)
+	if ifNoneMatch := r.Header.Get("If-None-Match"); ifNoneMatch != "" && etagMatches(ifNoneMatch, etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	jsonBytes, err := json.Marshal(newVersionedConfig(cfg))
+	if err != nil {
+		slog.ErrorContext(ctx, "unable to encode the response", "error", err)
+		h.writeInternalServerErrorResponse(w)
+		return
+	}
+
+	h.writeMessageWithStatusResponse(w, jsonBytes, http.StatusOK)
+}
+
+// handleSetConfig sets the config if the If-Match header matches its current version (or is "*").
+// It writes 428 Precondition Required without the header and 412 Precondition Failed if the version does not match.
+func (h *Handler) handleSetConfig(w http.ResponseWriter, r *http.Request, product string) {
+	ifMatch := r.Header.Get("If-Match")
+	if ifMatch == "" {
+		h.writeErrorResponse(w, errRespPreconditionRequired, http.StatusPreconditionRequired)
+		return
+	}
+
+	var cfg Config
+	if err := json.NewDecoder(r.Body).Decode(&cfg); err != nil {
+		h.writeBadRequestResponse(w, errRespInvalidPayload)
+		return
+	}
+
+	if !pack.SizesValid(cfg.PackSizes) {
+		h.writeBadRequestResponse(w, errRespInvalidPackSizes)
+		return
+	}
+
+	if !packCostsValid(cfg.PackSizes, cfg.PackCosts, cfg.HandlingFee) {
+		h.writeBadRequestResponse(w, errRespInvalidPackCosts)
+		return
+	}
+
+	if !effectiveFromValid(cfg.EffectiveFrom) {
+		h.writeBadRequestResponse(w, errRespInvalidEffectiveFrom)
+		return
+	}
+
+	h.saveConfigIfMatch(w, r, ifMatch, product, cfg)
+}
+
+// saveConfigIfMatch saves the config if the If-Match header matches its current version and writes the saved config.
+func (h *Handler) saveConfigIfMatch(w http.ResponseWriter, r *http.Request, ifMatch, product string, cfg Config) {
+	ctx := r.Context()
+
+	versions, anyVersion := parseIfMatch(ifMatch)
+	if !anyVersion && len(versions) == 0 {
+		h.writeErrorResponse(w, errRespPreconditionFailed, http.StatusPreconditionFailed)
+		return
+	}
+
+	savedCfg, err := h.saveConfig(ctx, product, cfg, versions)
+	if errors.Is(err, repository.ErrVersionConflict) {
+		h.writeErrorResponse(w, errRespPreconditionFailed, http.StatusPreconditionFailed)
+		return
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "unable to set the config", "error", err)
+		h.writeInternalServerErrorResponse(w)
+		return
+	}
+	h.invalidateCache(product)
+
+	jsonBytes, err := json.Marshal(newVersionedConfig(savedCfg))
+	if err != nil {
+		slog.ErrorContext(ctx, "unable to encode the response", "error", err)
+		h.writeInternalServerErrorResponse(w)
+		return
+	}
+
+	setConfigVersionHeaders(w, savedCfg)
+	h.writeMessageWithStatusResponse(w, jsonBytes, http.StatusOK)
+}
+
+func (h *Handler) saveConfig(ctx context.Context, product string, cfg Config, versions []int64) (repository.Config, error) {
+	repoCfg := repository.Config{
+		Product:     product,
+		PackSizes:   pack.RemoveDuplicateSizes(cfg.PackSizes),
+		PackCosts:   cfg.PackCosts,
+		HandlingFee: cfg.HandlingFee,
+		Author:      cfg.Author,
+		Reason:      cfg.Reason,
+	}
+	if len(repoCfg.PackCosts) == 0 {
+		repoCfg.PackCosts = nil
+	}
+	if cfg.EffectiveFrom != nil {
+		repoCfg.EffectiveFrom = *cfg.EffectiveFrom
+	}
+	if repoCfg.Author == "" {
+		repoCfg.Author = defaultConfigAuthor
+	}
+	return h.repository.SetConfig(ctx, repoCfg, versions)
+}
+
+// packCostsValid returns true if there are no pack costs, or a cost of every pack size and no other, and the costs and
+// the handling fee are between zero and maxPackCost.
+func packCostsValid(packSizes []int, packCosts map[int]int, handlingFee int) bool {
+	if handlingFee < 0 || handlingFee > maxPackCost {
+		return false
+	}
+	if len(packCosts) == 0 {
+		return true
+	}
+
+	packSizes = pack.RemoveDuplicateSizes(packSizes)
+	if len(packCosts) != len(packSizes) {
+		return false
+	}
+	for _, packSize := range packSizes {
+		if cost, ok := packCosts[packSize]; !ok || cost < 0 || cost > maxPackCost {
+			return false
+		}
+	}
+	return true
+}
+
+// effectiveFromValid returns true if the config takes effect immediately (nil) or in the future.
+func effectiveFromValid(effectiveFrom *time.Time) bool {
+	return effectiveFrom == nil || effectiveFrom.After(time.Now())
+}
+
+func setConfigVersionHeaders(w http.ResponseWriter, cfg repository.Config) {
+	w.Header().Set("ETag", formatETag(cfg.LatestVersion, cfg.Version))
+	w.Header().Set("Last-Modified", cfg.UpdatedAt.UTC().Format(http.TimeFormat))
+}
+
+func (h *Handler) handleListConfigVersions(w http.ResponseWriter, r *http.Request, product string) {
+	ctx := r.Context()
+
+	cfgs, err := h.repository.FindConfigVersions(ctx, product)
+	if err != nil {
+		slog.ErrorContext(ctx, "unable to find the config versions", "error", err)
+		h.writeInternalServerErrorResponse(w)
+		return
+	}
+
+	jsonBytes, err := json.Marshal(newConfigVersions(cfgs))
+	if err != nil {
+		slog.ErrorContext(ctx, "unable to encode the response", "error", err)
+		h.writeInternalServerErrorResponse(w)
+		return
+	}
+
+	h.writeMessageWithStatusResponse(w, jsonBytes, http.StatusOK)
+}
+
+// handleRestoreConfigVersion sets the pack sizes and costs of a previous config version as a new version.
+// Like handleSetConfig, it requires the If-Match header with the current config version.
+func (h *Handler) handleRestoreConfigVersion(w http.ResponseWriter, r *http.Request, product string) {
+	ctx := r.Context()
+
+	ifMatch := r.Header.Get("If-Match")
+	if ifMatch == "" {
+		h.writeErrorResponse(w, errRespPreconditionRequired, http.StatusPreconditionRequired)
+		return
+	}
+
+	// the payload is optional
+	var restoreReq RestoreConfigRequest
+	if err := json.NewDecoder(r.Body).Decode(&restoreReq); err != nil && !errors.Is(err, io.EOF) {
+		h.writeBadRequestResponse(w, errRespInvalidPayload)
+		return
+	}
+
+	if !effectiveFromValid(restoreReq.EffectiveFrom) {
+		h.writeBadRequestResponse(w, errRespInvalidEffectiveFrom)
+		return
+	}
+
+	version, _ := parseRestoreConfigVersion(r.URL.Path)
+	restoredCfg, err := h.repository.FindConfigVersion(ctx, product, version)
+	if errors.Is(err, repository.ErrNotFound) {
+		h.writeErrorResponse(w, errRespConfigVersionNotFound, http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "unable to restore the config version", "error", err)
+		h.writeInternalServerErrorResponse(w)
+		return
+	}
+
+	cfg := Config{
+		PackSizes:     restoredCfg.PackSizes,
+		PackCosts:     restoredCfg.PackCosts,
+		HandlingFee:   restoredCfg.HandlingFee,
+		EffectiveFrom: restoreReq.EffectiveFrom,
+		Author:        restoreReq.Author,
+		Reason:        restoreReq.Reason,
+	}
+	if cfg.Reason == "" {
+		cfg.Reason = fmt.Sprintf("Restored version %d.", version)
+	}
+	h.saveConfigIfMatch(w, r, ifMatch, product, cfg)
+}
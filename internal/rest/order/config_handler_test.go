@@ -0,0 +1,672 @@
+package order
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"packer/internal/rest/order/repository"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestServeHTTP_HandleGetConfig_Success(t *testing.T) {
+	comp := TestPackComputer{}
+	repo := TestSuccessRepository{
+		result: repository.Config{
+			PackSizes:     []int{250, 500},
+			Version:       3,
+			LatestVersion: 3,
+			UpdatedAt:     time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			EffectiveFrom: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		},
+	}
+	handler := NewHandler(&comp, &repo)
+
+	rr := httptest.NewRecorder()
+	req := newGetConfigRequest(t, "")
+
+	handler.ServeHTTP(rr, req)
+
+	assertStatusOk(t, rr)
+	assertHeader(t, rr, "ETag", `"3"`)
+	assertHeader(t, rr, "Last-Modified", "Mon, 01 Jan 2024 00:00:00 GMT")
+	assertBody(t, rr, `{"pack_sizes":[250,500],"version":3,"last_modified":"2024-01-01T00:00:00Z",`+
+		`"effective_from":"2024-01-01T00:00:00Z"}`)
+	if time.Since(repo.passedAt) > time.Minute {
+		t.Errorf("unexpected time passed to repository: got '%s' want now", repo.passedAt)
+	}
+}
+
+func TestServeHTTP_HandleGetConfig_ScheduledVersion(t *testing.T) {
+	comp := TestPackComputer{}
+	repo := TestSuccessRepository{
+		result: repository.Config{
+			PackSizes:     []int{250, 500},
+			Version:       3,
+			LatestVersion: 4,
+		},
+	}
+	handler := NewHandler(&comp, &repo)
+
+	rr := httptest.NewRecorder()
+	req := newGetConfigRequest(t, `"4"`)
+
+	handler.ServeHTTP(rr, req)
+
+	// the entity tag changes when the scheduled version takes effect
+	assertStatusOk(t, rr)
+	assertHeader(t, rr, "ETag", `"4-3"`)
+}
+
+func TestServeHTTP_HandleGetConfig_NoConfigInEffect(t *testing.T) {
+	comp := TestPackComputer{}
+	repo := TestSuccessRepository{findConfigErr: repository.ErrNotFound}
+	handler := NewHandler(&comp, &repo)
+
+	rr := httptest.NewRecorder()
+	req := newGetConfigRequest(t, "")
+
+	handler.ServeHTTP(rr, req)
+
+	assertErrorResponse(t, rr, http.StatusNotFound, "no_config_in_effect")
+}
+
+func TestServeHTTP_HandleGetConfig_IfNoneMatch(t *testing.T) {
+	data := []struct {
+		ifNoneMatch    string
+		expectedStatus int
+	}{
+		{
+			ifNoneMatch:    `"3"`,
+			expectedStatus: http.StatusNotModified,
+		},
+		{
+			ifNoneMatch:    `"1", W/"3"`,
+			expectedStatus: http.StatusNotModified,
+		},
+		{
+			ifNoneMatch:    "*",
+			expectedStatus: http.StatusNotModified,
+		},
+		{
+			ifNoneMatch:    `"2"`,
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, d := range data {
+		t.Run(fmt.Sprintf("with If-None-Match: %s", d.ifNoneMatch), func(t *testing.T) {
+			comp := TestPackComputer{}
+			repo := TestSuccessRepository{
+				result: repository.Config{
+					PackSizes:     []int{250, 500},
+					Version:       3,
+					LatestVersion: 3,
+				},
+			}
+			handler := NewHandler(&comp, &repo)
+
+			rr := httptest.NewRecorder()
+			req := newGetConfigRequest(t, d.ifNoneMatch)
+
+			handler.ServeHTTP(rr, req)
+
+			if rr.Code != d.expectedStatus {
+				t.Errorf("unexpected status code: got '%d' want '%d'", rr.Code, d.expectedStatus)
+			}
+			assertHeader(t, rr, "ETag", `"3"`)
+			if d.expectedStatus == http.StatusNotModified && rr.Body.Len() != 0 {
+				t.Errorf("unexpected body: got '%s' want none", rr.Body.String())
+			}
+		})
+	}
+}
+
+func TestServeHTTP_HandleGetConfig_InternalServerError(t *testing.T) {
+	comp := TestPackComputer{}
+	repo := TestErrRepository{}
+	handler := NewHandler(&comp, &repo)
+
+	rr := httptest.NewRecorder()
+	req := newGetConfigRequest(t, "")
+
+	handler.ServeHTTP(rr, req)
+
+	assertInternalServerErrorResponse(t, rr)
+}
+
+func TestServeHTTP_HandleSetConfig_Success(t *testing.T) {
+	comp := TestPackComputer{}
+	repo := TestSuccessRepository{}
+	handler := NewHandler(&comp, &repo)
+
+	rr := httptest.NewRecorder()
+	req := newCreateConfigRequestWithPayload(t, `{"pack_sizes": [100, 200]}`)
+
+	handler.ServeHTTP(rr, req)
+
+	assertStatusOk(t, rr)
+
+	cfg := repository.Config{
+		PackSizes: []int{100, 200},
+	}
+	assertRepositoryReceivedConfig(t, repo, cfg)
+	if !slices.Equal(repo.passedVersions, []int64{1}) {
+		t.Errorf("unexpected versions passed to repository: got '%+v' want '%+v'", repo.passedVersions, []int64{1})
+	}
+	assertHeader(t, rr, "ETag", `"2"`)
+
+	var cfgResp VersionedConfig
+	if err := json.NewDecoder(rr.Body).Decode(&cfgResp); err != nil {
+		t.Fatal(err)
+	}
+	if cfgResp.Version != 2 {
+		t.Errorf("unexpected config version: got '%d' want '%d'", cfgResp.Version, 2)
+	}
+}
+
+func TestServeHTTP_HandleSetConfig_IfMatchAnyVersion(t *testing.T) {
+	comp := TestPackComputer{}
+	repo := TestSuccessRepository{}
+	handler := NewHandler(&comp, &repo)
+
+	rr := httptest.NewRecorder()
+	req := newCreateConfigRequestWithPayloadAndIfMatch(t, `{"pack_sizes": [100, 200]}`, "*")
+
+	handler.ServeHTTP(rr, req)
+
+	assertStatusOk(t, rr)
+	if repo.passedVersions != nil {
+		t.Errorf("unexpected versions passed to repository: got '%+v' want none", repo.passedVersions)
+	}
+}
+
+func TestServeHTTP_HandleSetConfig_IfMatchScheduledVersion(t *testing.T) {
+	comp := TestPackComputer{}
+	repo := TestSuccessRepository{}
+	handler := NewHandler(&comp, &repo)
+
+	rr := httptest.NewRecorder()
+	req := newCreateConfigRequestWithPayloadAndIfMatch(t, `{"pack_sizes": [100, 200]}`, `"4-3"`)
+
+	handler.ServeHTTP(rr, req)
+
+	assertStatusOk(t, rr)
+	if !slices.Equal(repo.passedVersions, []int64{4}) {
+		t.Errorf("unexpected versions passed to repository: got '%+v' want '%+v'", repo.passedVersions, []int64{4})
+	}
+}
+
+func TestServeHTTP_HandleSetConfig_PreconditionRequired(t *testing.T) {
+	comp := TestPackComputer{}
+	repo := TestSuccessRepository{}
+	handler := NewHandler(&comp, &repo)
+
+	rr := httptest.NewRecorder()
+	req := newCreateConfigRequestWithPayloadAndIfMatch(t, `{"pack_sizes": [100, 200]}`, "")
+
+	handler.ServeHTTP(rr, req)
+
+	assertErrorResponse(t, rr, http.StatusPreconditionRequired, "precondition_required")
+}
+
+func TestServeHTTP_HandleSetConfig_PreconditionFailed(t *testing.T) {
+	ifMatches := []string{`"1"`, `W/"2"`, "2"}
+
+	for _, ifMatch := range ifMatches {
+		t.Run(fmt.Sprintf("with If-Match: %s", ifMatch), func(t *testing.T) {
+			comp := TestPackComputer{}
+			repo := TestVersionedRepository{version: 2}
+			handler := NewHandler(&comp, &repo)
+
+			rr := httptest.NewRecorder()
+			req := newCreateConfigRequestWithPayloadAndIfMatch(t, `{"pack_sizes": [100, 200]}`, ifMatch)
+
+			handler.ServeHTTP(rr, req)
+
+			assertErrorResponse(t, rr, http.StatusPreconditionFailed, "precondition_failed")
+		})
+	}
+}
+
+func TestServeHTTP_HandleSetConfig_ConcurrentWriters(t *testing.T) {
+	comp := TestPackComputer{}
+	repo := TestVersionedRepository{version: 1}
+	handler := NewHandler(&comp, &repo)
+
+	const writers = 20
+	statuses := make(chan int, writers)
+	var wg sync.WaitGroup
+	for i := 0; i < writers; i++ {
+		req := newCreateConfigRequestWithPayloadAndIfMatch(t, fmt.Sprintf(`{"pack_sizes": [%d]}`, i+1), `"1"`)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+			statuses <- rr.Code
+		}()
+	}
+	wg.Wait()
+	close(statuses)
+
+	countByStatus := make(map[int]int)
+	for status := range statuses {
+		countByStatus[status]++
+	}
+	if countByStatus[http.StatusOK] != 1 || countByStatus[http.StatusPreconditionFailed] != writers-1 {
+		t.Errorf("unexpected statuses: got '%+v' want one '%d' and the rest '%d'",
+			countByStatus, http.StatusOK, http.StatusPreconditionFailed)
+	}
+}
+
+func TestServeHTTP_HandleSetConfig_InvalidPayload(t *testing.T) {
+	comp := TestPackComputer{}
+	repo := TestSuccessRepository{}
+	handler := NewHandler(&comp, &repo)
+
+	rr := httptest.NewRecorder()
+	req := newCreateConfigRequestWithPayload(t, "{pack_sizes}")
+
+	handler.ServeHTTP(rr, req)
+
+	assertBadRequestResponse(t, rr, "invalid_payload", "Invalid payload.")
+}
+
+func TestServeHTTP_HandleSetConfig_InvalidPackSizes(t *testing.T) {
+	payloads := []string{"{}", `{"pack_sizes": []}`, `{"pack_sizes": [-1, 100]}`, `{"pack_sizes": [0, 100]}`}
+
+	for _, payload := range payloads {
+		t.Run(fmt.Sprintf("with payload: '%s'", payload), func(t *testing.T) {
+			comp := TestPackComputer{}
+			repo := TestSuccessRepository{}
+			handler := NewHandler(&comp, &repo)
+
+			rr := httptest.NewRecorder()
+			req := newCreateConfigRequestWithPayload(t, payload)
+
+			handler.ServeHTTP(rr, req)
+
+			assertBadRequestResponse(t, rr, "invalid_pack_sizes", "Pack sizes should have at least one size and all sizes should be greater than zero.")
+		})
+	}
+}
+
+func TestServeHTTP_HandleSetConfig_RemoveDuplicates(t *testing.T) {
+	comp := TestPackComputer{}
+	repo := TestSuccessRepository{}
+	handler := NewHandler(&comp, &repo)
+
+	rr := httptest.NewRecorder()
+	req := newCreateConfigRequestWithPayload(t, `{"pack_sizes": [100, 100, 200, 200]}`)
+
+	handler.ServeHTTP(rr, req)
+
+	cfg := repository.Config{
+		PackSizes: []int{100, 200},
+	}
+	assertRepositoryReceivedConfig(t, repo, cfg)
+}
+
+func TestServeHTTP_HandleSetConfig_PackCosts(t *testing.T) {
+	comp := TestPackComputer{}
+	repo := TestSuccessRepository{}
+	handler := NewHandler(&comp, &repo)
+
+	rr := httptest.NewRecorder()
+	req := newCreateConfigRequestWithPayload(t, `{"pack_sizes": [100], "pack_costs": {"100": 3}, "handling_fee": 7}`)
+
+	handler.ServeHTTP(rr, req)
+
+	assertStatusOk(t, rr)
+	expectedCosts := map[int]int{100: 3}
+	if !maps.Equal(repo.passedCfg.PackCosts, expectedCosts) {
+		t.Errorf("unexpected pack costs passed to repository: got '%+v' want '%+v'", repo.passedCfg.PackCosts, expectedCosts)
+	}
+	if repo.passedCfg.HandlingFee != 7 {
+		t.Errorf("unexpected handling fee passed to repository: got '%d' want '%d'", repo.passedCfg.HandlingFee, 7)
+	}
+	assertBody(t, rr, `{"pack_sizes":[100],"pack_costs":{"100":3},"handling_fee":7,"version":2,`+
+		`"last_modified":"2024-01-01T00:00:00Z","effective_from":"0001-01-01T00:00:00Z"}`)
+}
+
+func TestServeHTTP_HandleSetConfig_InvalidPackCosts(t *testing.T) {
+	payloads := []string{
+		`{"pack_sizes": [100, 200], "pack_costs": {"100": 3}}`,
+		`{"pack_sizes": [100, 200], "pack_costs": {"100": 3, "200": 5, "300": 7}}`,
+		`{"pack_sizes": [100, 200], "pack_costs": {"100": 3, "300": 5}}`,
+		`{"pack_sizes": [100, 200], "pack_costs": {"100": -3, "200": 5}}`,
+		`{"pack_sizes": [100, 200], "pack_costs": {"100": 3, "200": 5}, "handling_fee": -1}`,
+		`{"pack_sizes": [100, 200], "handling_fee": 2147483648}`,
+	}
+
+	for _, payload := range payloads {
+		t.Run(fmt.Sprintf("with payload: '%s'", payload), func(t *testing.T) {
+			comp := TestPackComputer{}
+			repo := TestSuccessRepository{}
+			handler := NewHandler(&comp, &repo)
+
+			rr := httptest.NewRecorder()
+			req := newCreateConfigRequestWithPayload(t, payload)
+
+			handler.ServeHTTP(rr, req)
+
+			assertErrorResponse(t, rr, http.StatusBadRequest, "invalid_pack_costs")
+		})
+	}
+}
+
+func TestServeHTTP_HandleSetConfig_InternalServerError(t *testing.T) {
+	comp := TestPackComputer{}
+	repo := TestErrRepository{}
+	handler := NewHandler(&comp, &repo)
+
+	rr := httptest.NewRecorder()
+	req := newCreateConfigRequestWithPayload(t, `{"pack_sizes": [100, 200]}`)
+
+	handler.ServeHTTP(rr, req)
+
+	assertInternalServerErrorResponse(t, rr)
+}
+
+func TestServeHTTP_HandleSetConfig_Headers(t *testing.T) {
+	comp := TestPackComputer{}
+	repo := TestSuccessRepository{}
+	handler := NewHandler(&comp, &repo)
+
+	rr := httptest.NewRecorder()
+	req := newCreateConfigRequestWithPayload(t, `{"pack_sizes": [100, 200]}`)
+
+	handler.ServeHTTP(rr, req)
+
+	assertHeader(t, rr, "Content-Type", "application/json")
+}
+
+func TestServeHTTP_HandleSetConfig_CorsHeaders(t *testing.T) {
+	comp := TestPackComputer{}
+	repo := TestSuccessRepository{}
+	handler := NewHandler(&comp, &repo)
+
+	rr := httptest.NewRecorder()
+	req, err := http.NewRequest(http.MethodOptions, Path+configPath, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	handler.ServeHTTP(rr, req)
+
+	assertStatusOk(t, rr)
+	assertCorsHeaders(t, rr)
+}
+
+func newGetConfigRequest(t *testing.T, ifNoneMatch string) *http.Request {
+	req, err := http.NewRequest(http.MethodGet, Path+configPath, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ifNoneMatch != "" {
+		req.Header.Set("If-None-Match", ifNoneMatch)
+	}
+	return req
+}
+
+func newCreateConfigRequestWithPayload(t *testing.T, payload string) *http.Request {
+	return newCreateConfigRequestWithPayloadAndIfMatch(t, payload, `"1"`)
+}
+
+func newCreateConfigRequestWithPayloadAndIfMatch(t *testing.T, payload, ifMatch string) *http.Request {
+	req, err := http.NewRequest(http.MethodPut, Path+configPath, bytes.NewReader([]byte(payload)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ifMatch != "" {
+		req.Header.Set("If-Match", ifMatch)
+	}
+	return req
+}
+
+func assertRepositoryReceivedConfig(t *testing.T, repo TestSuccessRepository, expected repository.Config) {
+	slices.Sort(repo.passedCfg.PackSizes)
+	slices.Sort(expected.PackSizes)
+	if !slices.Equal(repo.passedCfg.PackSizes, expected.PackSizes) {
+		t.Errorf("unexpected config pack sizes: got '%+v' want '%+v'", repo.passedCfg.PackSizes, expected.PackSizes)
+	}
+}
+
+func TestServeHTTP_HandleSetConfig_AuthorAndReason(t *testing.T) {
+	data := []struct {
+		payload        string
+		expectedAuthor string
+		expectedReason string
+	}{
+		{
+			payload:        `{"pack_sizes": [100], "author": "alice", "reason": "New supplier."}`,
+			expectedAuthor: "alice",
+			expectedReason: "New supplier.",
+		},
+		{
+			payload:        `{"pack_sizes": [100]}`,
+			expectedAuthor: "anonymous",
+			expectedReason: "",
+		},
+	}
+
+	for _, d := range data {
+		t.Run(fmt.Sprintf("with payload: '%s'", d.payload), func(t *testing.T) {
+			comp := TestPackComputer{}
+			repo := TestSuccessRepository{}
+			handler := NewHandler(&comp, &repo)
+
+			rr := httptest.NewRecorder()
+			req := newCreateConfigRequestWithPayload(t, d.payload)
+
+			handler.ServeHTTP(rr, req)
+
+			assertStatusOk(t, rr)
+			if repo.passedCfg.Author != d.expectedAuthor || repo.passedCfg.Reason != d.expectedReason {
+				t.Errorf("unexpected author and reason: got '%s' and '%s' want '%s' and '%s'",
+					repo.passedCfg.Author, repo.passedCfg.Reason, d.expectedAuthor, d.expectedReason)
+			}
+		})
+	}
+}
+
+func TestServeHTTP_HandleSetConfig_EffectiveFrom(t *testing.T) {
+	effectiveFrom := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+
+	comp := TestPackComputer{}
+	repo := TestSuccessRepository{}
+	handler := NewHandler(&comp, &repo)
+
+	rr := httptest.NewRecorder()
+	req := newCreateConfigRequestWithPayload(t,
+		fmt.Sprintf(`{"pack_sizes": [100], "effective_from": "%s"}`, effectiveFrom.Format(time.RFC3339)))
+
+	handler.ServeHTTP(rr, req)
+
+	assertStatusOk(t, rr)
+	if !repo.passedCfg.EffectiveFrom.Equal(effectiveFrom) {
+		t.Errorf("unexpected effective from: got '%s' want '%s'", repo.passedCfg.EffectiveFrom, effectiveFrom)
+	}
+}
+
+func TestServeHTTP_HandleSetConfig_InvalidEffectiveFrom(t *testing.T) {
+	comp := TestPackComputer{}
+	repo := TestSuccessRepository{}
+	handler := NewHandler(&comp, &repo)
+
+	rr := httptest.NewRecorder()
+	req := newCreateConfigRequestWithPayload(t, `{"pack_sizes": [100], "effective_from": "2024-01-01T00:00:00Z"}`)
+
+	handler.ServeHTTP(rr, req)
+
+	assertErrorResponse(t, rr, http.StatusBadRequest, "invalid_effective_from")
+}
+
+func TestServeHTTP_HandleListConfigVersions_Success(t *testing.T) {
+	comp := TestPackComputer{}
+	repo := TestSuccessRepository{
+		versionsResult: []repository.Config{
+			{
+				PackSizes:     []int{250, 500},
+				Version:       2,
+				UpdatedAt:     time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC),
+				EffectiveFrom: time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC),
+				Author:        "alice",
+				Reason:        "New supplier.",
+			},
+			{
+				PackSizes:     []int{250},
+				Version:       1,
+				UpdatedAt:     time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+				EffectiveFrom: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+				Author:        "system",
+				Reason:        "Initial config.",
+			},
+		},
+	}
+	handler := NewHandler(&comp, &repo)
+
+	rr := httptest.NewRecorder()
+	req, err := http.NewRequest(http.MethodGet, Path+configVersionsPath, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	handler.ServeHTTP(rr, req)
+
+	assertStatusOk(t, rr)
+	assertBody(t, rr, `{"versions":[`+
+		`{"version":2,"pack_sizes":[250,500],"created_at":"2024-01-02T00:00:00Z",`+
+		`"effective_from":"2024-01-03T00:00:00Z","author":"alice","reason":"New supplier."},`+
+		`{"version":1,"pack_sizes":[250],"created_at":"2024-01-01T00:00:00Z",`+
+		`"effective_from":"2024-01-01T00:00:00Z","author":"system","reason":"Initial config."}]}`)
+}
+
+func TestServeHTTP_HandleListConfigVersions_InternalServerError(t *testing.T) {
+	comp := TestPackComputer{}
+	repo := TestErrRepository{}
+	handler := NewHandler(&comp, &repo)
+
+	rr := httptest.NewRecorder()
+	req, err := http.NewRequest(http.MethodGet, Path+configVersionsPath, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	handler.ServeHTTP(rr, req)
+
+	assertInternalServerErrorResponse(t, rr)
+}
+
+func TestServeHTTP_HandleRestoreConfigVersion_Success(t *testing.T) {
+	data := []struct {
+		payload        string
+		expectedAuthor string
+		expectedReason string
+	}{
+		{
+			payload:        "",
+			expectedAuthor: "anonymous",
+			expectedReason: "Restored version 1.",
+		},
+		{
+			payload:        `{"author": "bob", "reason": "Rollback."}`,
+			expectedAuthor: "bob",
+			expectedReason: "Rollback.",
+		},
+	}
+
+	for _, d := range data {
+		t.Run(fmt.Sprintf("with payload: '%s'", d.payload), func(t *testing.T) {
+			comp := TestPackComputer{}
+			repo := TestSuccessRepository{
+				versionsResult: []repository.Config{
+					{
+						PackSizes: []int{250},
+						Version:   1,
+					},
+				},
+			}
+			handler := NewHandler(&comp, &repo)
+
+			rr := httptest.NewRecorder()
+			req := newRestoreConfigVersionRequest(t, "1", d.payload, `"2"`)
+
+			handler.ServeHTTP(rr, req)
+
+			assertStatusOk(t, rr)
+			assertHeader(t, rr, "ETag", `"2"`)
+			if repo.passedConfigVersion != 1 {
+				t.Errorf("unexpected restored version: got '%d' want '%d'", repo.passedConfigVersion, 1)
+			}
+			if !slices.Equal(repo.passedCfg.PackSizes, []int{250}) {
+				t.Errorf("unexpected config pack sizes: got '%+v' want '%+v'", repo.passedCfg.PackSizes, []int{250})
+			}
+			if !slices.Equal(repo.passedVersions, []int64{2}) {
+				t.Errorf("unexpected versions passed to repository: got '%+v' want '%+v'", repo.passedVersions, []int64{2})
+			}
+			if repo.passedCfg.Author != d.expectedAuthor || repo.passedCfg.Reason != d.expectedReason {
+				t.Errorf("unexpected author and reason: got '%s' and '%s' want '%s' and '%s'",
+					repo.passedCfg.Author, repo.passedCfg.Reason, d.expectedAuthor, d.expectedReason)
+			}
+		})
+	}
+}
+
+func TestServeHTTP_HandleRestoreConfigVersion_NotFound(t *testing.T) {
+	comp := TestPackComputer{}
+	repo := TestSuccessRepository{}
+	handler := NewHandler(&comp, &repo)
+
+	rr := httptest.NewRecorder()
+	req := newRestoreConfigVersionRequest(t, "9", "", "*")
+
+	handler.ServeHTTP(rr, req)
+
+	assertErrorResponse(t, rr, http.StatusNotFound, "config_version_not_found")
+}
+
+func TestServeHTTP_HandleRestoreConfigVersion_PreconditionRequired(t *testing.T) {
+	comp := TestPackComputer{}
+	repo := TestSuccessRepository{}
+	handler := NewHandler(&comp, &repo)
+
+	rr := httptest.NewRecorder()
+	req := newRestoreConfigVersionRequest(t, "1", "", "")
+
+	handler.ServeHTTP(rr, req)
+
+	assertErrorResponse(t, rr, http.StatusPreconditionRequired, "precondition_required")
+}
+
+func TestServeHTTP_HandleRestoreConfigVersion_PreconditionFailed(t *testing.T) {
+	comp := TestPackComputer{}
+	repo := TestVersionedRepository{version: 3}
+	repo.versionsResult = []repository.Config{{PackSizes: []int{250}, Version: 1}}
+	handler := NewHandler(&comp, &repo)
+
+	rr := httptest.NewRecorder()
+	req := newRestoreConfigVersionRequest(t, "1", "", `"2"`)
+
+	handler.ServeHTTP(rr, req)
+
+	assertErrorResponse(t, rr, http.StatusPreconditionFailed, "precondition_failed")
+}
+
+func newRestoreConfigVersionRequest(t *testing.T, version, payload, ifMatch string) *http.Request {
+	url := Path + configVersionsPath + "/" + version + restoreConfigSuffix
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader([]byte(payload)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ifMatch != "" {
+		req.Header.Set("If-Match", ifMatch)
+	}
+	return req
+}
@@ -3,6 +3,25 @@ package order
 type ErrorResponse struct {
 	Code    string `json:"error_code"`
 	Message string `json:"error_message"`
+	// Lines are the errors of the invalid lines of a multi-line order.
+	Lines []LineErrorResponse `json:"lines,omitempty"`
+	// RequestID is the ID of the request (see logging.RequestIDHeader), to find its logs.
+	RequestID string `json:"request_id,omitempty"`
+}
+
+// LineErrorResponse is the error of a line of a multi-line order, by its index in the lines.
+type LineErrorResponse struct {
+	Index   int    `json:"index"`
+	Code    string `json:"error_code"`
+	Message string `json:"error_message"`
+}
+
+func newLineErrorResponse(index int, errResp ErrorResponse) LineErrorResponse {
+	return LineErrorResponse{
+		Index:   index,
+		Code:    errResp.Code,
+		Message: errResp.Message,
+	}
 }
 
 var (
@@ -16,11 +35,226 @@ var (
 		Message: "Order sizes must be greater than zero.",
 	}
 
+	errRespInvalidLineCount = ErrorResponse{
+		Code:    "invalid_line_count",
+		Message: "Orders must have between 1 and 100 lines.",
+	}
+
+	errRespInvalidLines = ErrorResponse{
+		Code:    "invalid_lines",
+		Message: "Some lines are invalid, see the error of each line.",
+	}
+
+	errRespUnfulfillableLines = ErrorResponse{
+		Code:    "unfulfillable_lines",
+		Message: "The packs of some lines cannot be computed, see the error of each line.",
+	}
+
+	errRespInvalidQuantity = ErrorResponse{
+		Code:    "invalid_quantity",
+		Message: "Quantities must be greater than zero.",
+	}
+
+	errRespInvalidBatchSize = ErrorResponse{
+		Code:    "invalid_batch_size",
+		Message: "Batches must have between 1 and 10000 order sizes.",
+	}
+
+	errRespInvalidAsOf = ErrorResponse{
+		Code:    "invalid_as_of",
+		Message: "The as_of parameter must be an RFC 3339 timestamp.",
+	}
+
+	errRespInvalidOrderID = ErrorResponse{
+		Code:    "invalid_order_id",
+		Message: "Order ids must be integers greater than zero.",
+	}
+
+	errRespOrderNotFound = ErrorResponse{
+		Code:    "order_not_found",
+		Message: "Order not found.",
+	}
+
+	errRespPathNotFound = ErrorResponse{
+		Code:    "path_not_found",
+		Message: "Path not found.",
+	}
+
+	errRespOrderNotQuoted = ErrorResponse{
+		Code:    "order_not_quoted",
+		Message: "Only quoted orders can be confirmed or cancelled.",
+	}
+
+	errRespQuoteExpired = ErrorResponse{
+		Code:    "quote_expired",
+		Message: "The quote expired and its packs were released, create the order again.",
+	}
+
+	errRespInvalidFilter = ErrorResponse{
+		Code:    "invalid_filter",
+		Message: "Creation timestamps must be RFC 3339 timestamps and sizes must be integers greater than zero.",
+	}
+
+	errRespInvalidLimit = ErrorResponse{
+		Code:    "invalid_limit",
+		Message: "Limits must be integers between 1 and 1000.",
+	}
+
+	errRespInvalidCursor = ErrorResponse{
+		Code:    "invalid_cursor",
+		Message: "Invalid cursor.",
+	}
+
+	errRespInvalidObjective = ErrorResponse{
+		Code:    "invalid_objective",
+		Message: "The objective must be one of items_then_packs, cost or packs_then_items.",
+	}
+
+	errRespInvalidSolver = ErrorResponse{
+		Code:    "invalid_solver",
+		Message: "The solver must be one of dp, branch_and_bound or greedy.",
+	}
+
+	errRespInvalidExplain = ErrorResponse{
+		Code:    "invalid_explain",
+		Message: "The explain parameter must be true or false.",
+	}
+
+	errRespExplainUnsupported = ErrorResponse{
+		Code:    "explain_unsupported",
+		Message: "Only single orders computed by the dp solver with the items_then_packs objective can be explained.",
+	}
+
+	errRespInvalidSort = ErrorResponse{
+		Code:    "invalid_sort",
+		Message: "The sort must be one of size_desc, size_asc or quantity_desc.",
+	}
+
+	errRespInvalidTies = ErrorResponse{
+		Code:    "invalid_ties",
+		Message: "The ties parameter must be an integer between 1 and 100.",
+	}
+
+	errRespInvalidTieBreaker = ErrorResponse{
+		Code:    "invalid_tie_breaker",
+		Message: "The tie_breaker must be one of larger_packs, smaller_packs or fewer_sizes.",
+	}
+
+	errRespTiesUnsupported = ErrorResponse{
+		Code:    "ties_unsupported",
+		Message: "Only single orders computed by the dp or branch_and_bound solver can break ties.",
+	}
+
+	errRespNoPackCosts = ErrorResponse{
+		Code:    "no_pack_costs",
+		Message: "The cost objective needs the config to have the unit cost of every pack size.",
+	}
+
+	errRespInvalidPackCosts = ErrorResponse{
+		Code:    "invalid_pack_costs",
+		Message: "Pack costs must be given for every pack size, or none, and not be less than zero, nor the handling fee.",
+	}
+
 	errRespInvalidPackSizes = ErrorResponse{
 		Code:    "invalid_pack_sizes",
 		Message: "Pack sizes should have at least one size and all sizes should be greater than zero.",
 	}
 
+	errRespInvalidComputeInput = ErrorResponse{
+		Code:    "invalid_compute_input",
+		Message: "The packs cannot be computed with the given order size and the configured pack sizes.",
+	}
+
+	errRespOrderTooLarge = ErrorResponse{
+		Code:    "order_too_large",
+		Message: "The order is too large to be computed with the configured pack sizes.",
+	}
+
+	errRespComputationCancelled = ErrorResponse{
+		Code:    "computation_cancelled",
+		Message: "The computation of the packs was cancelled.",
+	}
+
+	errRespPreconditionRequired = ErrorResponse{
+		Code:    "precondition_required",
+		Message: "The If-Match header with the config's ETag is required.",
+	}
+
+	errRespPreconditionFailed = ErrorResponse{
+		Code:    "precondition_failed",
+		Message: "The config was changed since it was read, get it again and retry.",
+	}
+
+	errRespInvalidEffectiveFrom = ErrorResponse{
+		Code:    "invalid_effective_from",
+		Message: "Configs can only be scheduled to take effect in the future.",
+	}
+
+	errRespInsufficientStock = ErrorResponse{
+		Code:    "insufficient_stock",
+		Message: "The order cannot be fulfilled with the packs in stock (see /inventory).",
+	}
+
+	errRespInvalidStock = ErrorResponse{
+		Code:    "invalid_stock",
+		Message: "Stock levels should have at least one level, different pack sizes greater than zero and quantities not less than zero.",
+	}
+
+	errRespInvalidPackSize = ErrorResponse{
+		Code:    "invalid_pack_size",
+		Message: "Pack sizes must be integers greater than zero.",
+	}
+
+	errRespStockLevelNotFound = ErrorResponse{
+		Code:    "stock_level_not_found",
+		Message: "The pack size has no stock level.",
+	}
+
+	errRespStockReserved = ErrorResponse{
+		Code:    "stock_reserved",
+		Message: "Quantities cannot be set below the quantities reserved by quoted orders.",
+	}
+
+	errRespNoConfigInEffect = ErrorResponse{
+		Code:    "no_config_in_effect",
+		Message: "There is no config in effect for the product at the given time.",
+	}
+
+	errRespInvalidSKU = ErrorResponse{
+		Code:    "invalid_sku",
+		Message: "SKUs must have between 1 and 64 letters, digits, '.', '_' or '-'.",
+	}
+
+	errRespInvalidProductName = ErrorResponse{
+		Code:    "invalid_product_name",
+		Message: "Products must have a name.",
+	}
+
+	errRespProductNotFound = ErrorResponse{
+		Code:    "product_not_found",
+		Message: "Product not found.",
+	}
+
+	errRespProductAlreadyExists = ErrorResponse{
+		Code:    "product_already_exists",
+		Message: "A product with the same SKU already exists.",
+	}
+
+	errRespProductInUse = ErrorResponse{
+		Code:    "product_in_use",
+		Message: "Products that were ordered cannot be deleted.",
+	}
+
+	errRespDefaultProduct = ErrorResponse{
+		Code:    "default_product",
+		Message: "The default product cannot be deleted.",
+	}
+
+	errRespConfigVersionNotFound = ErrorResponse{
+		Code:    "config_version_not_found",
+		Message: "Config version not found.",
+	}
+
 	errRespInternalServerError = ErrorResponse{
 		Code:    "internal_server_error",
 		Message: "Internal server error.",
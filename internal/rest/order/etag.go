@@ -0,0 +1,57 @@
+package order
+
+import (
+	"strconv"
+	"strings"
+)
+
+// formatETag formats the config versions as a strong entity tag.
+// It is the latest version (e.g. "3"), checked by If-Match, followed by the version in effect if a later one is
+// scheduled (e.g. "5-3"), so that the entity tag changes when the scheduled version takes effect.
+func formatETag(latestVersion, version int64) string {
+	etag := strconv.FormatInt(latestVersion, 10)
+	if version != latestVersion {
+		etag += "-" + strconv.FormatInt(version, 10)
+	}
+	return `"` + etag + `"`
+}
+
+// etagMatches returns true if the If-None-Match header value ("*" or a list of entity tags) matches the entity tag.
+// Weak entity tags (W/"3") match their strong counterparts, as If-None-Match uses the weak comparison.
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// parseIfMatch parses the If-Match header value ("*" or a list of entity tags) into the latest config versions (see
+// formatETag).
+// It returns anyVersion for "*". Weak and malformed entity tags are skipped, as If-Match uses the strong comparison.
+func parseIfMatch(header string) (versions []int64, anyVersion bool) {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" {
+			return nil, true
+		}
+
+		unquoted, ok := strings.CutPrefix(candidate, `"`)
+		if !ok {
+			continue
+		}
+		unquoted, ok = strings.CutSuffix(unquoted, `"`)
+		if !ok {
+			continue
+		}
+
+		latestVersion, _, _ := strings.Cut(unquoted, "-")
+		version, err := strconv.ParseInt(latestVersion, 10, 64)
+		if err == nil {
+			versions = append(versions, version)
+		}
+	}
+	return versions, false
+}
@@ -0,0 +1,36 @@
+package order
+
+import (
+	"context"
+	"packer/internal/rest/order/pack"
+	"packer/internal/rest/order/repository"
+)
+
+// maxRunnersUp is the maximum number of runners-up of an explanation.
+const maxRunnersUp = 5
+
+// PacksExplainer computes the candidate packs of an order, best first by the fewest items and then the fewest packs,
+// that explain why its packs were chosen (see pack.Explain).
+type PacksExplainer interface {
+	ComputeCandidates(ctx context.Context, packSizes []int, stock map[int]int, orderSize int, limit int) ([]pack.Solution, error)
+}
+
+// explainable returns true if the packs of the order can be explained: a single order computed by a solver that is a
+// PacksExplainer with the objective of its rules.
+func (h *Handler) explainable(orderReq Request, opts computeOptions) bool {
+	_, ok := h.solvers.get(opts.solver).(PacksExplainer)
+	return ok && orderReq.Lines == nil && opts.objective == pack.ObjectiveItemsThenPacks
+}
+
+// explainPacks explains the packs of the order size with the candidates of the solver, which must be explainable.
+// One more candidate than runners-up is computed, since the packs themselves are usually the first one.
+func (h *Handler) explainPacks(ctx context.Context, cfg repository.Config, stock map[int]int, orderSize int, packs []pack.Pack, opts computeOptions) (*pack.Explanation, error) {
+	explainer := h.solvers.get(opts.solver).(PacksExplainer)
+	candidates, err := explainer.ComputeCandidates(ctx, cfg.PackSizes, stock, orderSize, maxRunnersUp+1)
+	if err != nil {
+		return nil, err
+	}
+
+	explanation := pack.Explain(orderSize, packs, candidates, maxRunnersUp)
+	return &explanation, nil
+}
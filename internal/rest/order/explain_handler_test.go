@@ -0,0 +1,139 @@
+package order
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"packer/internal/rest/order/pack"
+	"packer/internal/rest/order/repository"
+	"testing"
+)
+
+type TestExplainingPackComputer struct {
+	TestPackComputer
+	passedLimit int
+	candidates  []pack.Solution
+}
+
+func (comp *TestExplainingPackComputer) ComputeCandidates(_ context.Context, _ []int, _ map[int]int, _ int, limit int) ([]pack.Solution, error) {
+	comp.passedLimit = limit
+	return comp.candidates, nil
+}
+
+func TestServeHTTP_HandleCreateOrder_Explain(t *testing.T) {
+	comp := TestExplainingPackComputer{
+		TestPackComputer: TestPackComputer{result: []pack.Pack{{Size: 500, Quantity: 1}}},
+		candidates: []pack.Solution{
+			{Packs: []pack.Pack{{Size: 500, Quantity: 1}}, Items: 500, PackCount: 1},
+			{Packs: []pack.Pack{{Size: 250, Quantity: 2}}, Items: 500, PackCount: 2},
+			{Packs: []pack.Pack{{Size: 500, Quantity: 1}, {Size: 250, Quantity: 1}}, Items: 750, PackCount: 2},
+		},
+	}
+	repo := TestSuccessRepository{result: repository.Config{PackSizes: []int{250}}}
+	handler := NewHandler(&comp, &repo)
+
+	rr := httptest.NewRecorder()
+	req, err := http.NewRequest(http.MethodPost, Path+"?explain=true", bytes.NewReader([]byte(`{"size": 251}`)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	handler.ServeHTTP(rr, req)
+
+	assertStatusOk(t, rr)
+	if comp.passedLimit != maxRunnersUp+1 {
+		t.Errorf("unexpected candidates limit: got '%d' want '%d'", comp.passedLimit, maxRunnersUp+1)
+	}
+	assertBody(t, rr, `{"id":1,"product":"default","size":251,"packs":[{"size":500,"quantity":1}],`+
+		`"pack_sizes":[250],"config_version":0,"created_at":"2024-01-01T00:00:00Z","status":"quoted",`+
+		`"expires_at":"2024-01-01T00:15:00Z","objective":"items_then_packs","solver":"dp",`+
+		`"explanation":{"items_shipped":500,"overshoot":249,"runners_up":[`+
+		`{"packs":[{"size":250,"quantity":2}],"items_shipped":500,"pack_count":2,"rule":3,"reason":"more_packs"},`+
+		`{"packs":[{"size":500,"quantity":1},{"size":250,"quantity":1}],"items_shipped":750,"pack_count":2,"rule":2,`+
+		`"reason":"more_items"}]}}`)
+}
+
+func TestServeHTTP_HandleCreateOrder_NotExplained(t *testing.T) {
+	comp := TestExplainingPackComputer{TestPackComputer: TestPackComputer{result: []pack.Pack{{Size: 250, Quantity: 1}}}}
+	repo := TestSuccessRepository{result: repository.Config{PackSizes: []int{250}}}
+	handler := NewHandler(&comp, &repo)
+
+	rr := httptest.NewRecorder()
+	req, err := http.NewRequest(http.MethodPost, Path+"?explain=false", bytes.NewReader([]byte(`{"size": 1}`)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	handler.ServeHTTP(rr, req)
+
+	assertStatusOk(t, rr)
+	if comp.passedLimit != 0 {
+		t.Errorf("unexpected candidates computation with limit: '%d'", comp.passedLimit)
+	}
+	assertBody(t, rr, `{"id":1,"product":"default","size":1,"packs":[{"size":250,"quantity":1}],`+
+		`"pack_sizes":[250],"config_version":0,"created_at":"2024-01-01T00:00:00Z","status":"quoted",`+
+		`"expires_at":"2024-01-01T00:15:00Z","objective":"items_then_packs","solver":"dp"}`)
+}
+
+func TestServeHTTP_HandleCreateOrder_InvalidExplain(t *testing.T) {
+	comp := TestExplainingPackComputer{}
+	repo := TestSuccessRepository{}
+	handler := NewHandler(&comp, &repo)
+
+	rr := httptest.NewRecorder()
+	req, err := http.NewRequest(http.MethodPost, Path+"?explain=maybe", bytes.NewReader([]byte(`{"size": 251}`)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	handler.ServeHTTP(rr, req)
+
+	assertErrorResponse(t, rr, http.StatusBadRequest, "invalid_explain")
+}
+
+func TestServeHTTP_HandleCreateOrder_ExplainUnsupported(t *testing.T) {
+	data := []struct {
+		description string
+		query       string
+		payload     string
+	}{
+		{
+			description: "solver without candidates",
+			query:       "?explain=true&solver=greedy",
+			payload:     `{"size": 251}`,
+		},
+		{
+			description: "cost objective",
+			query:       "?explain=true",
+			payload:     `{"size": 251, "objective": "cost"}`,
+		},
+		{
+			description: "lines",
+			query:       "?explain=true",
+			payload:     `{"lines": [{"quantity": 251}]}`,
+		},
+	}
+
+	for _, d := range data {
+		t.Run(fmt.Sprintf("with %s", d.description), func(t *testing.T) {
+			solvers := NewSolvers(map[string]PacksComputer{
+				pack.SolverDP:     &TestExplainingPackComputer{},
+				pack.SolverGreedy: &TestPackComputer{},
+			}, pack.SolverDP)
+			repo := TestSuccessRepository{}
+			handler := NewHandlerWithSolvers(solvers, &repo, DefaultQuoteTTL)
+
+			rr := httptest.NewRecorder()
+			req, err := http.NewRequest(http.MethodPost, Path+d.query, bytes.NewReader([]byte(d.payload)))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			handler.ServeHTTP(rr, req)
+
+			assertErrorResponse(t, rr, http.StatusBadRequest, "explain_unsupported")
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package order
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"packer/internal/rest/order/repository"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultListLimit = 100
+	maxListLimit     = 1000
+)
+
+var (
+	errInvalidFilter = errors.New("invalid filter")
+	errInvalidLimit  = errors.New("invalid limit")
+	errInvalidCursor = errors.New("invalid cursor")
+)
+
+// cursor is the JSON representation of a repository.OrderCursor, encoded in base64 to keep it opaque to clients.
+type cursor struct {
+	CreatedAt time.Time `json:"created_at"`
+	ID        int64     `json:"id"`
+}
+
+// parseOrderFilter parses the orders list query parameters.
+// It returns errInvalidFilter, errInvalidLimit or errInvalidCursor if a parameter is invalid.
+func parseOrderFilter(query url.Values) (repository.OrderFilter, error) {
+	filter := repository.OrderFilter{Product: query.Get("product")}
+	var err error
+
+	if filter.CreatedFrom, err = parseTimeParam(query, "created_from"); err != nil {
+		return repository.OrderFilter{}, err
+	}
+	if filter.CreatedTo, err = parseTimeParam(query, "created_to"); err != nil {
+		return repository.OrderFilter{}, err
+	}
+	if filter.MinSize, err = parsePositiveIntParam(query, "min_size", errInvalidFilter); err != nil {
+		return repository.OrderFilter{}, err
+	}
+	if filter.MaxSize, err = parsePositiveIntParam(query, "max_size", errInvalidFilter); err != nil {
+		return repository.OrderFilter{}, err
+	}
+	if filter.PackSize, err = parsePositiveIntParam(query, "pack_size", errInvalidFilter); err != nil {
+		return repository.OrderFilter{}, err
+	}
+
+	if filter.Limit, err = parsePositiveIntParam(query, "limit", errInvalidLimit); err != nil {
+		return repository.OrderFilter{}, err
+	}
+	if filter.Limit == 0 {
+		filter.Limit = defaultListLimit
+	}
+	if filter.Limit > maxListLimit {
+		return repository.OrderFilter{}, errInvalidLimit
+	}
+
+	if query.Has("cursor") {
+		after, err := decodeCursor(query.Get("cursor"))
+		if err != nil {
+			return repository.OrderFilter{}, err
+		}
+		filter.After = &after
+	}
+
+	return filter, nil
+}
+
+func parseTimeParam(query url.Values, name string) (time.Time, error) {
+	if !query.Has(name) {
+		return time.Time{}, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, query.Get(name))
+	if err != nil {
+		return time.Time{}, errInvalidFilter
+	}
+	return t, nil
+}
+
+func parsePositiveIntParam(query url.Values, name string, invalidErr error) (int, error) {
+	if !query.Has(name) {
+		return 0, nil
+	}
+
+	i, err := strconv.Atoi(query.Get(name))
+	if err != nil || i <= 0 {
+		return 0, invalidErr
+	}
+	return i, nil
+}
+
+func encodeCursor(order repository.Order) (string, error) {
+	jsonBytes, err := json.Marshal(cursor{CreatedAt: order.CreatedAt, ID: order.ID})
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(jsonBytes), nil
+}
+
+func decodeCursor(s string) (repository.OrderCursor, error) {
+	jsonBytes, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return repository.OrderCursor{}, errInvalidCursor
+	}
+
+	var c cursor
+	if err = json.Unmarshal(jsonBytes, &c); err != nil || c.ID <= 0 {
+		return repository.OrderCursor{}, errInvalidCursor
+	}
+	return repository.OrderCursor{CreatedAt: c.CreatedAt, ID: c.ID}, nil
+}
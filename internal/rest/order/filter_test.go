@@ -0,0 +1,92 @@
+package order
+
+import (
+	"errors"
+	"net/url"
+	"packer/internal/rest/order/repository"
+	"testing"
+	"time"
+)
+
+func TestParseOrderFilter_Defaults(t *testing.T) {
+	filter, err := parseOrderFilter(url.Values{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := repository.OrderFilter{Limit: defaultListLimit}
+	if filter != expected {
+		t.Errorf("unexpected filter: got '%+v' want '%+v'", filter, expected)
+	}
+}
+
+func TestParseOrderFilter_AllParams(t *testing.T) {
+	query, err := url.ParseQuery("product=tea&created_from=2024-01-01T00:00:00Z&created_to=2024-02-01T00:00:00Z" +
+		"&min_size=10&max_size=1000&pack_size=250&limit=50")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	filter, err := parseOrderFilter(query)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := repository.OrderFilter{
+		Product:     "tea",
+		CreatedFrom: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		CreatedTo:   time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+		MinSize:     10,
+		MaxSize:     1000,
+		PackSize:    250,
+		Limit:       50,
+	}
+	if filter != expected {
+		t.Errorf("unexpected filter: got '%+v' want '%+v'", filter, expected)
+	}
+}
+
+func TestParseOrderFilter_Invalid(t *testing.T) {
+	data := []struct {
+		query       string
+		expectedErr error
+	}{
+		{query: "created_to=2024-01-01", expectedErr: errInvalidFilter},
+		{query: "max_size=abc", expectedErr: errInvalidFilter},
+		{query: "pack_size=-1", expectedErr: errInvalidFilter},
+		{query: "limit=0", expectedErr: errInvalidLimit},
+		{query: "cursor=e30", expectedErr: errInvalidCursor},
+	}
+
+	for _, d := range data {
+		t.Run("with query: "+d.query, func(t *testing.T) {
+			query, err := url.ParseQuery(d.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			_, err = parseOrderFilter(query)
+
+			if !errors.Is(err, d.expectedErr) {
+				t.Errorf("unexpected error: got '%v' want '%v'", err, d.expectedErr)
+			}
+		})
+	}
+}
+
+func TestEncodeDecodeCursor(t *testing.T) {
+	order := repository.Order{ID: 42, CreatedAt: time.Date(2024, 1, 1, 12, 30, 0, 123456000, time.UTC)}
+
+	encoded, err := encodeCursor(order)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := decodeCursor(encoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if decoded.ID != order.ID || !decoded.CreatedAt.Equal(order.CreatedAt) {
+		t.Errorf("unexpected cursor: got '%+v' want '%+v'", decoded, order)
+	}
+}
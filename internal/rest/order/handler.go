@@ -3,37 +3,84 @@ package order
 import (
 	"context"
 	"encoding/json"
-	"log"
+	"errors"
+	"log/slog"
 	"net/http"
+	"net/url"
+	"packer/internal/logging"
 	"packer/internal/rest/order/pack"
 	"packer/internal/rest/order/repository"
+	"runtime"
+	"strconv"
 	"strings"
+	"time"
 )
 
 const (
-	Path       = "/orders"
-	configPath = "/config"
+	Path                = "/orders"
+	configPath          = "/config"
+	configVersionsPath  = configPath + "/versions"
+	restoreConfigSuffix = "/restore"
 )
 
-// PacksComputer computes the number of packs in an order.
+// PacksComputer computes the best packs of an order by the objective, with at most stock[size] packs of the pack sizes
+// in stock.
+// It returns one of the pack package errors (e.g. pack.ErrInputTooLarge) when the packs cannot be computed.
 type PacksComputer interface {
-	ComputePacks(packSizes []int, orderSize int) []pack.Pack
+	ComputePacksWithObjective(ctx context.Context, packSizes []int, stock map[int]int, orderSize int, objective pack.Objective) ([]pack.Pack, error)
 }
 
 type Repository interface {
-	SetConfig(ctx context.Context, cfg repository.Config) error
-	FindConfig(ctx context.Context) (repository.Config, error)
+	SetConfig(ctx context.Context, cfg repository.Config, versions []int64) (repository.Config, error)
+	FindConfig(ctx context.Context, product string, at time.Time) (repository.Config, error)
+	FindConfigs(ctx context.Context, products []string, at time.Time) ([]repository.Config, error)
+	FindConfigVersions(ctx context.Context, product string) ([]repository.Config, error)
+	FindConfigVersion(ctx context.Context, product string, version int64) (repository.Config, error)
+	SaveOrder(ctx context.Context, order repository.Order) (repository.Order, error)
+	SaveOrders(ctx context.Context, orders []repository.Order) ([]repository.Order, error)
+	FindOrder(ctx context.Context, id int64) (repository.Order, error)
+	FindOrders(ctx context.Context, filter repository.OrderFilter) ([]repository.Order, error)
+	FindStockLevels(ctx context.Context, products []string) ([]repository.StockLevel, error)
+	ReservationRepository
 }
 
 type Handler struct {
-	packsComputer PacksComputer
-	repository    Repository
+	solvers    Solvers
+	repository Repository
+	// batchWorkers is the number of order sizes of a batch computed concurrently.
+	batchWorkers int
+	// quoteTTL is how long the packs of a created order are reserved until it is confirmed.
+	quoteTTL time.Duration
+	// cache has the configs and the solution tables of the orders, or is nil if they are not cached.
+	cache *Cache
 }
 
 func NewHandler(packsComputer PacksComputer, repository Repository) Handler {
+	return NewHandlerWithQuoteTTL(packsComputer, repository, DefaultQuoteTTL)
+}
+
+// NewHandlerWithQuoteTTL creates a handler that computes the packs with the packs computer, registered as the default
+// pack.SolverDP solver.
+func NewHandlerWithQuoteTTL(packsComputer PacksComputer, repository Repository, quoteTTL time.Duration) Handler {
+	solvers := NewSolvers(map[string]PacksComputer{pack.SolverDP: packsComputer}, pack.SolverDP)
+	return NewHandlerWithSolvers(solvers, repository, quoteTTL)
+}
+
+// NewHandlerWithSolvers creates a handler that computes the packs with the solver named by the requests, or the
+// default one.
+func NewHandlerWithSolvers(solvers Solvers, repository Repository, quoteTTL time.Duration) Handler {
+	return NewHandlerWithCache(solvers, repository, quoteTTL, nil)
+}
+
+// NewHandlerWithCache creates a handler like NewHandlerWithSolvers that finds the configs and computes the packs with
+// the cache (see Cache), unless it is nil.
+func NewHandlerWithCache(solvers Solvers, repository Repository, quoteTTL time.Duration, cache *Cache) Handler {
 	return Handler{
-		packsComputer: packsComputer,
-		repository:    repository,
+		solvers:      solvers,
+		repository:   repository,
+		batchWorkers: runtime.GOMAXPROCS(0),
+		quoteTTL:     quoteTTL,
+		cache:        cache,
 	}
 }
 
@@ -41,10 +88,30 @@ func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	setHeaders(w) // could be more granular
 
 	switch {
+	case r.Method == http.MethodPost && isRestoreConfigVersionPath(r.URL.Path):
+		h.handleRestoreConfigVersion(w, r, repository.DefaultProduct)
+	case r.Method == http.MethodPost && isBatchPath(r.URL.Path):
+		h.handleQuoteBatch(w, r)
+	case r.Method == http.MethodPost && isConfirmOrderPath(r.URL.Path):
+		h.handleConfirmOrder(w, r)
+	case r.Method == http.MethodPost && isCancelOrderPath(r.URL.Path):
+		h.handleCancelOrder(w, r)
 	case r.Method == http.MethodPost:
 		h.handleCreateOrder(w, r)
+	case r.Method == http.MethodGet && isOrdersPath(r.URL.Path):
+		h.handleListOrders(w, r)
 	case r.Method == http.MethodPut && isConfigPath(r.URL.Path):
-		h.handleSetConfig(w, r)
+		h.handleSetConfig(w, r, repository.DefaultProduct)
+	case r.Method == http.MethodGet && isConfigPath(r.URL.Path):
+		h.handleGetConfig(w, r, repository.DefaultProduct)
+	case r.Method == http.MethodGet && isConfigVersionsPath(r.URL.Path):
+		h.handleListConfigVersions(w, r, repository.DefaultProduct)
+	case r.Method == http.MethodGet && isCachePath(r.URL.Path):
+		h.handleGetCacheStats(w, r)
+	case r.Method == http.MethodGet && isOrderPath(r.URL.Path):
+		h.handleGetOrder(w, r)
+	case r.Method != http.MethodOptions:
+		h.writeErrorResponse(w, errRespPathNotFound, http.StatusNotFound)
 	}
 }
 
@@ -55,12 +122,31 @@ func setHeaders(w http.ResponseWriter) {
 
 func setCorsHeaders(w http.ResponseWriter) {
 	w.Header().Set("Access-Control-Allow-Origin", "*")
-	w.Header().Set("Access-Control-Allow-Methods", "POST, PUT")
+	w.Header().Set("Access-Control-Allow-Methods", "POST, PUT, DELETE")
 	w.Header().Set("Access-Control-Allow-Headers", "*")
+	w.Header().Set("Access-Control-Expose-Headers", "ETag, Last-Modified, "+logging.RequestIDHeader)
+}
+
+// isOrdersPath returns true for the orders' collection path, with or without the orders' path prefix.
+func isOrdersPath(path string) bool {
+	path = strings.TrimSuffix(path, "/")
+	return path == "" || path == Path
 }
 
-func isConfigPath(path string) bool {
-	return path[strings.LastIndex(path, "/"):] == configPath
+// isOrderPath returns true for the path of an order, a single segment under the orders' collection path (e.g.
+// "/orders/1"), with or without the orders' path prefix.
+func isOrderPath(path string) bool {
+	segment, ok := strings.CutPrefix(strings.TrimPrefix(path, Path), "/")
+	return ok && segment != "" && !strings.Contains(segment, "/")
+}
+
+// parseOrderID parses the order id from the last segment of the path (e.g. 1 for "/orders/1").
+func parseOrderID(path string) (int64, bool) {
+	id, err := strconv.ParseInt(path[strings.LastIndex(path, "/")+1:], 10, 64)
+	if err != nil || id <= 0 {
+		return 0, false
+	}
+	return id, true
 }
 
 func (h *Handler) handleCreateOrder(w http.ResponseWriter, r *http.Request) {
@@ -72,21 +158,48 @@ func (h *Handler) handleCreateOrder(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	opts, errResp, ok := h.parseComputeOptions(orderReq, r.URL.Query())
+	if !ok {
+		h.writeBadRequestResponse(w, errResp)
+		return
+	}
+
+	packSort, ok := parsePackSort(r.URL.Query())
+	if !ok {
+		h.writeBadRequestResponse(w, errRespInvalidSort)
+		return
+	}
+
+	if orderReq.Lines != nil {
+		h.handleCreateLinesOrder(w, r, orderReq, opts, packSort)
+		return
+	}
+
 	if orderReq.Size <= 0 {
 		h.writeBadRequestResponse(w, errRespOrderSize)
 		return
 	}
 
-	packs, err := h.computePacks(ctx, orderReq.Size)
+	if orderReq.Product == "" {
+		orderReq.Product = repository.DefaultProduct
+	}
+
+	var order Order
+	var err error
+	if orderReq.AsOf != nil {
+		order, err = h.quoteOrder(ctx, orderReq.Product, orderReq.Size, opts, *orderReq.AsOf)
+	} else {
+		order, err = h.createOrder(ctx, orderReq.Product, orderReq.Size, opts)
+	}
 	if err != nil {
-		log.Println(err)
-		h.writeInternalServerErrorResponse(w)
+		h.writeCreateOrderErrorResponse(ctx, w, err)
 		return
 	}
 
-	jsonBytes, err := json.Marshal(Order{Packs: packs})
+	order.sortPacks(packSort)
+	jsonBytes, err := json.Marshal(order)
 	if err != nil {
-		log.Println(err)
+		slog.ErrorContext(ctx, "unable to encode the response", "error", err)
 		h.writeInternalServerErrorResponse(w)
 		return
 	}
@@ -94,38 +207,248 @@ func (h *Handler) handleCreateOrder(w http.ResponseWriter, r *http.Request) {
 	h.writeMessageWithStatusResponse(w, jsonBytes, http.StatusOK)
 }
 
-func (h *Handler) computePacks(ctx context.Context, orderSize int) ([]pack.Pack, error) {
-	cfg, err := h.repository.FindConfig(ctx)
+// parseComputeOptions returns the options the packs of the order are computed with, from the order and the query
+// parameters, or the error response of the first invalid one.
+func (h *Handler) parseComputeOptions(orderReq Request, query url.Values) (computeOptions, ErrorResponse, bool) {
+	if !pack.ObjectiveValid(orderReq.Objective) {
+		return computeOptions{}, errRespInvalidObjective, false
+	}
+	opts := computeOptions{objective: orderReq.Objective}
+	if opts.objective == "" {
+		opts.objective = pack.ObjectiveItemsThenPacks
+	}
+
+	var ok bool
+	if opts.solver, ok = h.solvers.resolve(query.Get("solver")); !ok {
+		return computeOptions{}, errRespInvalidSolver, false
+	}
+
+	var err error
+	if opts.explain, err = strconv.ParseBool(query.Get("explain")); query.Has("explain") && err != nil {
+		return computeOptions{}, errRespInvalidExplain, false
+	}
+	if opts.explain && !h.explainable(orderReq, opts) {
+		return computeOptions{}, errRespExplainUnsupported, false
+	}
+
+	if opts.ties, err = parseTies(query); err != nil {
+		return computeOptions{}, errRespInvalidTies, false
+	}
+	if opts.tieBreaker = query.Get("tie_breaker"); !pack.TieBreakerValid(opts.tieBreaker) {
+		return computeOptions{}, errRespInvalidTieBreaker, false
+	}
+	if (opts.ties > 0 || opts.tieBreaker != "") && !tiesComputable(orderReq, opts) {
+		return computeOptions{}, errRespTiesUnsupported, false
+	}
+
+	return opts, ErrorResponse{}, true
+}
+
+// createOrder computes the packs of the order with the product's config in effect and saves the order as a quote
+// that reserves its packs until it is confirmed (see handleConfirmOrder).
+// The packs are computed again if the stock changed before they could be reserved.
+func (h *Handler) createOrder(ctx context.Context, product string, orderSize int, opts computeOptions) (Order, error) {
+	for attempt := 1; ; attempt++ {
+		order, extras, err := h.computeOrder(ctx, product, orderSize, opts, time.Now())
+		if err != nil {
+			return Order{}, err
+		}
+
+		order, err = h.repository.SaveOrder(ctx, h.newQuote(order))
+		if errors.Is(err, repository.ErrInsufficientStock) && attempt < maxReserveAttempts {
+			continue
+		}
+		if err != nil {
+			return Order{}, err
+		}
+
+		created := newOrder(order)
+		extras.apply(&created)
+		return created, nil
+	}
+}
+
+// quoteOrder computes the packs of the order with the product's config in effect at the given time, without saving
+// the order.
+func (h *Handler) quoteOrder(ctx context.Context, product string, orderSize int, opts computeOptions, asOf time.Time) (Order, error) {
+	order, extras, err := h.computeOrder(ctx, product, orderSize, opts, asOf)
+	if err != nil {
+		return Order{}, err
+	}
+
+	order.CreatedAt = time.Now()
+	quote := newOrder(order)
+	quote.AsOf = &asOf
+	extras.apply(&quote)
+	return quote, nil
+}
+
+// computeOrder computes the packs of the order with the options, the product's config in effect at the given time and
+// its current stock, with the extras the options ask for.
+// With a tie-breaker or ties, the packs are the first of their ties by the tie-breaker (see breakTies).
+func (h *Handler) computeOrder(ctx context.Context, product string, orderSize int, opts computeOptions, at time.Time) (repository.Order, computeExtras, error) {
+	cfg, err := h.findConfig(ctx, product, at)
+	if err != nil {
+		return repository.Order{}, computeExtras{}, err
+	}
+
+	levels, err := h.repository.FindStockLevels(ctx, []string{product})
+	if err != nil {
+		return repository.Order{}, computeExtras{}, err
+	}
+
+	stock := stockByProduct(levels)[product]
+	packs, err := h.computePacks(ctx, cfg, stock, orderSize, opts)
+	if err != nil {
+		return repository.Order{}, computeExtras{}, err
+	}
+
+	var extras computeExtras
+	if opts.ties > 0 || opts.tieBreaker != "" {
+		ties, err := h.breakTies(ctx, cfg, stock, packs, opts)
+		if err != nil {
+			return repository.Order{}, computeExtras{}, err
+		}
+		packs = ties[0]
+		if opts.ties > 0 {
+			extras.ties = ties
+		}
+	}
+	if opts.explain {
+		if extras.explanation, err = h.explainPacks(ctx, cfg, stock, orderSize, packs, opts); err != nil {
+			return repository.Order{}, computeExtras{}, err
+		}
+	}
+
+	return newRepositoryOrder(orderSize, packs, cfg, opts), extras, nil
+}
+
+// computeOptions are the names of the objective (see pack.NewObjective) and of the solver (see Solvers) the packs of
+// an order are computed with, whether they are explained (see explainPacks), and the number of their ties returned
+// and the name of the tie-breaker that sorts them (see breakTies).
+type computeOptions struct {
+	objective  string
+	solver     string
+	explain    bool
+	ties       int
+	tieBreaker string
+}
+
+// computeExtras are the details of the packs of an order computed on demand (see computeOptions), which are not saved.
+type computeExtras struct {
+	explanation *pack.Explanation
+	ties        [][]pack.Pack
+}
+
+func (e computeExtras) apply(order *Order) {
+	order.Explanation = e.explanation
+	order.Ties = e.ties
+}
+
+// computePacks computes the packs of the order size with the options and the costs of the config.
+func (h *Handler) computePacks(ctx context.Context, cfg repository.Config, stock map[int]int, orderSize int, opts computeOptions) ([]pack.Pack, error) {
+	objective, err := pack.NewObjective(opts.objective, cfg.PackSizes, configCost(cfg))
 	if err != nil {
 		return nil, err
 	}
+	return h.solvePacks(ctx, cfg, stock, orderSize, opts.solver, objective)
+}
 
-	return h.packsComputer.ComputePacks(cfg.PackSizes, orderSize), nil
+func (h *Handler) writeCreateOrderErrorResponse(ctx context.Context, w http.ResponseWriter, err error) {
+	errResp, status, ok := createOrderErrorResponse(err)
+	if !ok {
+		slog.ErrorContext(ctx, "unable to compute the packs", "error", err)
+		h.writeInternalServerErrorResponse(w)
+		return
+	}
+	h.writeErrorResponse(w, errResp, status)
 }
 
-func (h *Handler) handleSetConfig(w http.ResponseWriter, r *http.Request) {
+// createOrderErrorResponse returns the error response and status of an error computing the packs of an order, or
+// false if the error is unexpected.
+func createOrderErrorResponse(err error) (ErrorResponse, int, bool) {
+	switch {
+	case errors.Is(err, pack.ErrInvalidInput):
+		return errRespInvalidComputeInput, http.StatusBadRequest, true
+	case errors.Is(err, pack.ErrInputTooLarge):
+		return errRespOrderTooLarge, http.StatusRequestEntityTooLarge, true
+	case errors.Is(err, pack.ErrInsufficientStock), errors.Is(err, repository.ErrInsufficientStock):
+		return errRespInsufficientStock, http.StatusUnprocessableEntity, true
+	case errors.Is(err, pack.ErrNoCosts):
+		return errRespNoPackCosts, http.StatusUnprocessableEntity, true
+	case errors.Is(err, pack.ErrCancelled):
+		return errRespComputationCancelled, http.StatusServiceUnavailable, true
+	case errors.Is(err, repository.ErrNotFound):
+		return errRespNoConfigInEffect, http.StatusUnprocessableEntity, true
+	default:
+		return ErrorResponse{}, 0, false
+	}
+}
+
+func (h *Handler) handleGetOrder(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
-	var cfg Config
-	if err := json.NewDecoder(r.Body).Decode(&cfg); err != nil {
-		h.writeBadRequestResponse(w, errRespInvalidPayload)
+	id, ok := parseOrderID(r.URL.Path)
+	if !ok {
+		h.writeBadRequestResponse(w, errRespInvalidOrderID)
+		return
+	}
+
+	packSort, ok := parsePackSort(r.URL.Query())
+	if !ok {
+		h.writeBadRequestResponse(w, errRespInvalidSort)
+		return
+	}
+
+	order, err := h.repository.FindOrder(ctx, id)
+	if errors.Is(err, repository.ErrNotFound) {
+		h.writeErrorResponse(w, errRespOrderNotFound, http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "unable to find the order", "error", err)
+		h.writeInternalServerErrorResponse(w)
+		return
+	}
+
+	found := newOrder(order)
+	found.sortPacks(packSort)
+	jsonBytes, err := json.Marshal(found)
+	if err != nil {
+		slog.ErrorContext(ctx, "unable to encode the response", "error", err)
+		h.writeInternalServerErrorResponse(w)
 		return
 	}
 
-	if !pack.SizesValid(cfg.PackSizes) {
-		h.writeBadRequestResponse(w, errRespInvalidPackSizes)
+	h.writeMessageWithStatusResponse(w, jsonBytes, http.StatusOK)
+}
+
+func (h *Handler) handleListOrders(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	filter, err := parseOrderFilter(r.URL.Query())
+	if err != nil {
+		h.writeBadRequestResponse(w, listOrdersErrorResponse(err))
 		return
 	}
 
-	if err := h.saveConfig(ctx, cfg); err != nil {
-		log.Println(err)
+	packSort, ok := parsePackSort(r.URL.Query())
+	if !ok {
+		h.writeBadRequestResponse(w, errRespInvalidSort)
+		return
+	}
+
+	page, err := h.findOrderPage(ctx, filter)
+	if err != nil {
+		slog.ErrorContext(ctx, "unable to find the orders", "error", err)
 		h.writeInternalServerErrorResponse(w)
 		return
 	}
 
-	jsonBytes, err := json.Marshal(cfg)
+	page.sortPacks(packSort)
+	jsonBytes, err := json.Marshal(page)
 	if err != nil {
-		log.Println(err)
+		slog.ErrorContext(ctx, "unable to encode the response", "error", err)
 		h.writeInternalServerErrorResponse(w)
 		return
 	}
@@ -133,25 +456,64 @@ func (h *Handler) handleSetConfig(w http.ResponseWriter, r *http.Request) {
 	h.writeMessageWithStatusResponse(w, jsonBytes, http.StatusOK)
 }
 
-func (h *Handler) saveConfig(ctx context.Context, cfg Config) error {
-	packSizes := pack.RemoveDuplicateSizes(cfg.PackSizes)
-	return h.repository.SetConfig(ctx, repository.Config{PackSizes: packSizes})
+func listOrdersErrorResponse(err error) ErrorResponse {
+	switch {
+	case errors.Is(err, errInvalidLimit):
+		return errRespInvalidLimit
+	case errors.Is(err, errInvalidCursor):
+		return errRespInvalidCursor
+	default:
+		return errRespInvalidFilter
+	}
+}
+
+// findOrderPage finds one more order than the limit to know whether there is a next page.
+func (h *Handler) findOrderPage(ctx context.Context, filter repository.OrderFilter) (OrderPage, error) {
+	limit := filter.Limit
+	filter.Limit++
+
+	orders, err := h.repository.FindOrders(ctx, filter)
+	if err != nil {
+		return OrderPage{}, err
+	}
+
+	page := OrderPage{Orders: make([]Order, 0, min(len(orders), limit))}
+	for i := 0; i < len(orders) && i < limit; i++ {
+		page.Orders = append(page.Orders, newOrder(orders[i]))
+	}
+
+	if len(orders) > limit {
+		page.NextCursor, err = encodeCursor(orders[limit-1])
+		if err != nil {
+			return OrderPage{}, err
+		}
+	}
+
+	return page, nil
 }
 
 func (h *Handler) writeBadRequestResponse(w http.ResponseWriter, errResp ErrorResponse) {
+	h.writeErrorResponse(w, errResp, http.StatusBadRequest)
+}
+
+// writeErrorResponse writes the error response with the request ID echoed in the response's header, if any.
+func (h *Handler) writeErrorResponse(w http.ResponseWriter, errResp ErrorResponse, status int) {
+	errResp.RequestID = w.Header().Get(logging.RequestIDHeader)
 	jsonBytes, err := json.Marshal(errResp)
 	if err != nil {
-		log.Println(err)
+		slog.Error("unable to encode the error response", "error", err)
 		h.writeInternalServerErrorResponse(w)
 		return
 	}
-	h.writeMessageWithStatusResponse(w, jsonBytes, http.StatusBadRequest)
+	h.writeMessageWithStatusResponse(w, jsonBytes, status)
 }
 
 func (h *Handler) writeInternalServerErrorResponse(w http.ResponseWriter) {
-	jsonBytes, err := json.Marshal(errRespInternalServerError)
+	errResp := errRespInternalServerError
+	errResp.RequestID = w.Header().Get(logging.RequestIDHeader)
+	jsonBytes, err := json.Marshal(errResp)
 	if err != nil {
-		log.Println(err)
+		slog.Error("unable to encode the error response", "error", err)
 		return
 	}
 	h.writeMessageWithStatusResponse(w, jsonBytes, http.StatusInternalServerError)
@@ -161,6 +523,6 @@ func (h *Handler) writeMessageWithStatusResponse(w http.ResponseWriter, message
 	w.WriteHeader(status)
 	_, err := w.Write(message)
 	if err != nil {
-		log.Println(err)
+		slog.Error("unable to write the response", "error", err)
 	}
 }
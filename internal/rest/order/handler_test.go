@@ -6,48 +6,259 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"maps"
 	"net/http"
 	"net/http/httptest"
+	"packer/internal/logging"
 	"packer/internal/rest/order/pack"
 	"packer/internal/rest/order/repository"
 	"slices"
+	"sync"
 	"testing"
+	"time"
 )
 
 type TestPackComputer struct {
 	passedPackSizes []int
+	passedStock     map[int]int
 	passedOrderSize int
+	passedObjective pack.Objective
 	result          []pack.Pack
+	err             error
 }
 
-func (comp *TestPackComputer) ComputePacks(packSizes []int, orderSize int) []pack.Pack {
+func (comp *TestPackComputer) ComputePacksWithObjective(_ context.Context, packSizes []int, stock map[int]int, orderSize int, objective pack.Objective) ([]pack.Pack, error) {
 	comp.passedPackSizes = packSizes
+	comp.passedStock = stock
 	comp.passedOrderSize = orderSize
-	return comp.result
+	comp.passedObjective = objective
+	return comp.result, comp.err
 }
 
 type TestErrRepository struct{}
 
-func (_ *TestErrRepository) SetConfig(_ context.Context, _ repository.Config) error {
-	return errors.New("test error")
+func (_ *TestErrRepository) SetConfig(_ context.Context, _ repository.Config, _ []int64) (repository.Config, error) {
+	return repository.Config{}, errors.New("test error")
 }
 
-func (_ *TestErrRepository) FindConfig(_ context.Context) (repository.Config, error) {
+func (_ *TestErrRepository) FindConfig(_ context.Context, _ string, _ time.Time) (repository.Config, error) {
 	return repository.Config{}, errors.New("test error")
 }
 
+func (_ *TestErrRepository) FindConfigs(_ context.Context, _ []string, _ time.Time) ([]repository.Config, error) {
+	return nil, errors.New("test error")
+}
+
+func (_ *TestErrRepository) FindConfigVersions(_ context.Context, _ string) ([]repository.Config, error) {
+	return nil, errors.New("test error")
+}
+
+func (_ *TestErrRepository) FindConfigVersion(_ context.Context, _ string, _ int64) (repository.Config, error) {
+	return repository.Config{}, errors.New("test error")
+}
+
+func (_ *TestErrRepository) SaveOrder(_ context.Context, _ repository.Order) (repository.Order, error) {
+	return repository.Order{}, errors.New("test error")
+}
+
+func (_ *TestErrRepository) SaveOrders(_ context.Context, _ []repository.Order) ([]repository.Order, error) {
+	return nil, errors.New("test error")
+}
+
+func (_ *TestErrRepository) FindOrder(_ context.Context, _ int64) (repository.Order, error) {
+	return repository.Order{}, errors.New("test error")
+}
+
+func (_ *TestErrRepository) FindOrders(_ context.Context, _ repository.OrderFilter) ([]repository.Order, error) {
+	return nil, errors.New("test error")
+}
+
+func (_ *TestErrRepository) FindStockLevels(_ context.Context, _ []string) ([]repository.StockLevel, error) {
+	return nil, errors.New("test error")
+}
+
+func (_ *TestErrRepository) ConfirmOrder(_ context.Context, _ int64) (repository.Order, error) {
+	return repository.Order{}, errors.New("test error")
+}
+
+func (_ *TestErrRepository) CancelOrder(_ context.Context, _ int64) (repository.Order, error) {
+	return repository.Order{}, errors.New("test error")
+}
+
+func (_ *TestErrRepository) ExpireOrders(_ context.Context) (int64, error) {
+	return 0, errors.New("test error")
+}
+
+// TestSaveOrderErrRepository finds the config but fails to save orders.
+type TestSaveOrderErrRepository struct {
+	TestSuccessRepository
+}
+
+func (_ *TestSaveOrderErrRepository) SaveOrder(_ context.Context, _ repository.Order) (repository.Order, error) {
+	return repository.Order{}, errors.New("test error")
+}
+
 type TestSuccessRepository struct {
-	passedCfg repository.Config
-	result    repository.Config
+	passedCfg           repository.Config
+	passedVersions      []int64
+	versionsResult      []repository.Config
+	passedConfigVersion int64
+	passedProduct       string
+	passedProducts      []string
+	passedAt            time.Time
+	result              repository.Config
+	configsResult       []repository.Config
+	findConfigErr       error
+	passedOrder         repository.Order
+	passedOrders        []repository.Order
+	passedOrderID       int64
+	orderResult         repository.Order
+	findOrderErr        error
+	passedFilter        repository.OrderFilter
+	ordersResult        []repository.Order
+	passedStockProducts []string
+	stockResult         []repository.StockLevel
+	// orderActionErr is returned when confirming or cancelling orderResult.
+	orderActionErr error
+	// saveOrderErrs are returned by the first calls to save orders.
+	saveOrderErrs []error
 }
 
-func (repo *TestSuccessRepository) SetConfig(_ context.Context, cfg repository.Config) error {
+func (repo *TestSuccessRepository) SetConfig(_ context.Context, cfg repository.Config, versions []int64) (repository.Config, error) {
 	repo.passedCfg = cfg
-	return nil
+	repo.passedVersions = versions
+	cfg.Version = 2
+	cfg.LatestVersion = 2
+	cfg.UpdatedAt = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	return cfg, nil
+}
+
+// TestVersionedRepository sets the config only if its version matches, like the database does.
+type TestVersionedRepository struct {
+	TestSuccessRepository
+	mu      sync.Mutex
+	version int64
+}
+
+func (repo *TestVersionedRepository) SetConfig(_ context.Context, cfg repository.Config, versions []int64) (repository.Config, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	if versions != nil && !slices.Contains(versions, repo.version) {
+		return repository.Config{}, repository.ErrVersionConflict
+	}
+	repo.version++
+	cfg.Version = repo.version
+	cfg.LatestVersion = repo.version
+	return cfg, nil
+}
+
+func (repo *TestSuccessRepository) FindConfig(_ context.Context, product string, at time.Time) (repository.Config, error) {
+	repo.passedProduct = product
+	repo.passedAt = at
+	cfg := repo.result
+	cfg.Product = product
+	return cfg, repo.findConfigErr
+}
+
+func (repo *TestSuccessRepository) FindConfigs(_ context.Context, products []string, at time.Time) ([]repository.Config, error) {
+	repo.passedProducts = products
+	repo.passedAt = at
+	return repo.configsResult, repo.findConfigErr
+}
+
+func (repo *TestSuccessRepository) FindConfigVersions(_ context.Context, product string) ([]repository.Config, error) {
+	repo.passedProduct = product
+	return repo.versionsResult, nil
+}
+
+// FindConfigVersion returns the version from versionsResult, or repository.ErrNotFound.
+func (repo *TestSuccessRepository) FindConfigVersion(_ context.Context, product string, version int64) (repository.Config, error) {
+	repo.passedProduct = product
+	repo.passedConfigVersion = version
+	for _, cfg := range repo.versionsResult {
+		if cfg.Version == version {
+			return cfg, nil
+		}
+	}
+	return repository.Config{}, repository.ErrNotFound
+}
+
+func (repo *TestSuccessRepository) SaveOrder(_ context.Context, order repository.Order) (repository.Order, error) {
+	repo.passedOrder = order
+	if err := repo.nextSaveOrderErr(); err != nil {
+		return repository.Order{}, err
+	}
+	order.ID = 1
+	order.CreatedAt = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	order.ExpiresAt = testExpiresAt(order.ExpiresAt)
+	return order, nil
+}
+
+func (repo *TestSuccessRepository) SaveOrders(_ context.Context, orders []repository.Order) ([]repository.Order, error) {
+	repo.passedOrders = orders
+	if err := repo.nextSaveOrderErr(); err != nil {
+		return nil, err
+	}
+	result := make([]repository.Order, len(orders))
+	for i, order := range orders {
+		order.ID = int64(i + 1)
+		order.CreatedAt = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		order.ExpiresAt = testExpiresAt(order.ExpiresAt)
+		result[i] = order
+	}
+	return result, nil
+}
+
+// testExpiresAt replaces the expiry set by the handler, which depends on the time of the request, with a fixed one.
+func testExpiresAt(expiresAt *time.Time) *time.Time {
+	if expiresAt == nil {
+		return nil
+	}
+	fixed := time.Date(2024, 1, 1, 0, 15, 0, 0, time.UTC)
+	return &fixed
+}
+
+func (repo *TestSuccessRepository) nextSaveOrderErr() error {
+	if len(repo.saveOrderErrs) == 0 {
+		return nil
+	}
+	err := repo.saveOrderErrs[0]
+	repo.saveOrderErrs = repo.saveOrderErrs[1:]
+	return err
+}
+
+func (repo *TestSuccessRepository) FindOrder(_ context.Context, id int64) (repository.Order, error) {
+	repo.passedOrderID = id
+	return repo.orderResult, repo.findOrderErr
+}
+
+func (repo *TestSuccessRepository) FindOrders(_ context.Context, filter repository.OrderFilter) ([]repository.Order, error) {
+	repo.passedFilter = filter
+	return repo.ordersResult, nil
+}
+
+func (repo *TestSuccessRepository) FindStockLevels(_ context.Context, products []string) ([]repository.StockLevel, error) {
+	repo.passedStockProducts = products
+	return repo.stockResult, nil
 }
 
-func (repo *TestSuccessRepository) FindConfig(_ context.Context) (repository.Config, error) {
-	return repo.result, nil
+func (repo *TestSuccessRepository) ConfirmOrder(_ context.Context, id int64) (repository.Order, error) {
+	repo.passedOrderID = id
+	order := repo.orderResult
+	order.Status = repository.OrderStatusConfirmed
+	return order, repo.orderActionErr
+}
+
+func (repo *TestSuccessRepository) CancelOrder(_ context.Context, id int64) (repository.Order, error) {
+	repo.passedOrderID = id
+	order := repo.orderResult
+	order.Status = repository.OrderStatusCancelled
+	return order, repo.orderActionErr
+}
+
+func (repo *TestSuccessRepository) ExpireOrders(_ context.Context) (int64, error) {
+	return 0, nil
 }
 
 func TestServeHTTP_HandleCreateOrder_Success(t *testing.T) {
@@ -98,6 +309,144 @@ func TestServeHTTP_HandleCreateOrder_Success(t *testing.T) {
 	}
 }
 
+func TestServeHTTP_HandleCreateOrder_SavesOrder(t *testing.T) {
+	comp := TestPackComputer{
+		result: []pack.Pack{
+			{
+				Size:     500,
+				Quantity: 1,
+			},
+		},
+	}
+	cfg := repository.Config{
+		PackSizes: []int{250, 500},
+	}
+	repo := TestSuccessRepository{result: cfg}
+	handler := NewHandler(&comp, &repo)
+
+	rr := httptest.NewRecorder()
+	req := newCreateOrderRequestWithPayload(t, `{"size": 251}`)
+
+	handler.ServeHTTP(rr, req)
+
+	assertStatusOk(t, rr)
+	expectedOrder := repository.Order{
+		Product: repository.DefaultProduct,
+		Size:    251,
+		Packs: []repository.Pack{
+			{
+				Size:     500,
+				Quantity: 1,
+			},
+		},
+		PackSizes: []int{250, 500},
+	}
+	assertRepositoryReceivedOrder(t, repo, expectedOrder)
+	assertBody(t, rr, `{"id":1,"product":"default","size":251,"packs":[{"size":500,"quantity":1}],"pack_sizes":[250,500],"config_version":0,"created_at":"2024-01-01T00:00:00Z","status":"quoted","expires_at":"2024-01-01T00:15:00Z","objective":"items_then_packs","solver":"dp"}`)
+}
+
+func TestServeHTTP_HandleCreateOrder_Product(t *testing.T) {
+	data := []struct {
+		payload         string
+		expectedProduct string
+	}{
+		{
+			payload:         `{"size": 1, "product": "tea"}`,
+			expectedProduct: "tea",
+		},
+		{
+			payload:         `{"size": 1}`,
+			expectedProduct: repository.DefaultProduct,
+		},
+	}
+
+	for _, d := range data {
+		t.Run(fmt.Sprintf("with payload: '%s'", d.payload), func(t *testing.T) {
+			comp := TestPackComputer{}
+			repo := TestSuccessRepository{result: repository.Config{PackSizes: []int{250}}}
+			handler := NewHandler(&comp, &repo)
+
+			rr := httptest.NewRecorder()
+			req := newCreateOrderRequestWithPayload(t, d.payload)
+
+			handler.ServeHTTP(rr, req)
+
+			assertStatusOk(t, rr)
+			if repo.passedProduct != d.expectedProduct {
+				t.Errorf("unexpected product passed to repository: got '%s' want '%s'", repo.passedProduct, d.expectedProduct)
+			}
+			if repo.passedOrder.Product != d.expectedProduct {
+				t.Errorf("unexpected order product: got '%s' want '%s'", repo.passedOrder.Product, d.expectedProduct)
+			}
+		})
+	}
+}
+
+func TestServeHTTP_HandleCreateOrder_AsOf(t *testing.T) {
+	comp := TestPackComputer{
+		result: []pack.Pack{
+			{
+				Size:     500,
+				Quantity: 1,
+			},
+		},
+	}
+	cfg := repository.Config{
+		PackSizes: []int{250, 500},
+		Version:   2,
+	}
+	repo := TestSuccessRepository{result: cfg}
+	handler := NewHandler(&comp, &repo)
+
+	rr := httptest.NewRecorder()
+	req := newCreateOrderRequestWithPayload(t, `{"size": 251, "as_of": "2030-01-01T00:00:00Z"}`)
+
+	handler.ServeHTTP(rr, req)
+
+	assertStatusOk(t, rr)
+	asOf := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	if !repo.passedAt.Equal(asOf) {
+		t.Errorf("unexpected time passed to repository: got '%s' want '%s'", repo.passedAt, asOf)
+	}
+	if repo.passedOrder.Size != 0 {
+		t.Errorf("unexpected order saved: got '%+v' want none", repo.passedOrder)
+	}
+
+	var order Order
+	if err := json.NewDecoder(rr.Body).Decode(&order); err != nil {
+		t.Fatal(err)
+	}
+	if order.ID != 0 || order.ConfigVersion != 2 || order.AsOf == nil || !order.AsOf.Equal(asOf) {
+		t.Errorf("unexpected quote: got '%+v'", order)
+	}
+}
+
+func TestServeHTTP_HandleCreateOrder_NoConfigInEffect(t *testing.T) {
+	comp := TestPackComputer{}
+	repo := TestSuccessRepository{findConfigErr: repository.ErrNotFound}
+	handler := NewHandler(&comp, &repo)
+
+	rr := httptest.NewRecorder()
+	req := newCreateOrderRequestWithPayload(t, `{"size": 1, "as_of": "2000-01-01T00:00:00Z"}`)
+
+	handler.ServeHTTP(rr, req)
+
+	assertErrorResponse(t, rr, http.StatusUnprocessableEntity, "no_config_in_effect")
+}
+
+func TestServeHTTP_HandleCreateOrder_SaveOrderInternalServerError(t *testing.T) {
+	comp := TestPackComputer{}
+	repo := TestSaveOrderErrRepository{}
+	handler := NewHandler(&comp, &repo)
+
+	rr := httptest.NewRecorder()
+	req := newCreateOrderRequestWithPayload(t, `{"size": 1}`)
+
+	handler.ServeHTTP(rr, req)
+
+	assertInternalServerErrorResponse(t, rr)
+}
+
 func TestServeHTTP_HandleCreateOrder_InvalidPayload(t *testing.T) {
 	comp := TestPackComputer{}
 	repo := TestSuccessRepository{}
@@ -143,6 +492,208 @@ func TestServeHTTP_HandleCreateOrder_InternalServerError(t *testing.T) {
 	assertInternalServerErrorResponse(t, rr)
 }
 
+func TestServeHTTP_HandleCreateOrder_ErrorResponseRequestID(t *testing.T) {
+	data := []struct {
+		description string
+		repo        Repository
+		payload     string
+		expected    string
+	}{
+		{
+			description: "bad request",
+			repo:        &TestSuccessRepository{},
+			payload:     "{size}",
+			expected:    `{"error_code":"invalid_payload","error_message":"Invalid payload.","request_id":"abc-123"}`,
+		},
+		{
+			description: "internal server error",
+			repo:        &TestErrRepository{},
+			payload:     `{"size": 1}`,
+			expected:    `{"error_code":"internal_server_error","error_message":"Internal server error.","request_id":"abc-123"}`,
+		},
+	}
+
+	for _, d := range data {
+		t.Run(fmt.Sprintf("with %s", d.description), func(t *testing.T) {
+			comp := TestPackComputer{}
+			handler := NewHandler(&comp, d.repo)
+
+			rr := httptest.NewRecorder()
+			rr.Header().Set(logging.RequestIDHeader, "abc-123")
+			req := newCreateOrderRequestWithPayload(t, d.payload)
+
+			handler.ServeHTTP(rr, req)
+
+			assertBody(t, rr, d.expected)
+		})
+	}
+}
+
+func TestServeHTTP_HandleCreateOrder_ComputePacksErrors(t *testing.T) {
+	data := []struct {
+		err             error
+		expectedStatus  int
+		expectedErrCode string
+	}{
+		{
+			err:             pack.ErrInvalidInput,
+			expectedStatus:  http.StatusBadRequest,
+			expectedErrCode: "invalid_compute_input",
+		},
+		{
+			err:             pack.ErrInputTooLarge,
+			expectedStatus:  http.StatusRequestEntityTooLarge,
+			expectedErrCode: "order_too_large",
+		},
+		{
+			err:             pack.ErrInsufficientStock,
+			expectedStatus:  http.StatusUnprocessableEntity,
+			expectedErrCode: "insufficient_stock",
+		},
+		{
+			err:             fmt.Errorf("%w: %w", pack.ErrCancelled, context.Canceled),
+			expectedStatus:  http.StatusServiceUnavailable,
+			expectedErrCode: "computation_cancelled",
+		},
+		{
+			err:             errors.New("test error"),
+			expectedStatus:  http.StatusInternalServerError,
+			expectedErrCode: "internal_server_error",
+		},
+	}
+
+	for _, d := range data {
+		t.Run(fmt.Sprintf("with error: %v", d.err), func(t *testing.T) {
+			comp := TestPackComputer{err: d.err}
+			repo := TestSuccessRepository{}
+			handler := NewHandler(&comp, &repo)
+
+			rr := httptest.NewRecorder()
+			req := newCreateOrderRequestWithPayload(t, `{"size": 1}`)
+
+			handler.ServeHTTP(rr, req)
+
+			assertErrorResponse(t, rr, d.expectedStatus, d.expectedErrCode)
+		})
+	}
+}
+
+func TestServeHTTP_HandleCreateOrder_Stock(t *testing.T) {
+	comp := TestPackComputer{result: []pack.Pack{{Size: 250, Quantity: 1}}}
+	repo := TestSuccessRepository{
+		stockResult: []repository.StockLevel{
+			{Product: "tea", PackSize: 250, Quantity: 3},
+			{Product: "tea", PackSize: 500, Quantity: 0},
+		},
+	}
+	handler := NewHandler(&comp, &repo)
+
+	rr := httptest.NewRecorder()
+	req := newCreateOrderRequestWithPayload(t, `{"product": "tea", "size": 1}`)
+
+	handler.ServeHTTP(rr, req)
+
+	assertStatusOk(t, rr)
+	if !slices.Equal(repo.passedStockProducts, []string{"tea"}) {
+		t.Errorf("unexpected products passed to repository: got '%+v' want '%+v'", repo.passedStockProducts, []string{"tea"})
+	}
+	expectedStock := map[int]int{250: 3, 500: 0}
+	if !maps.Equal(comp.passedStock, expectedStock) {
+		t.Errorf("unexpected stock passed to computer: got '%+v' want '%+v'", comp.passedStock, expectedStock)
+	}
+}
+
+func TestServeHTTP_HandleCreateOrder_Objective(t *testing.T) {
+	cost := pack.Cost{UnitCosts: map[int]int{250: 3, 500: 5}, HandlingFee: 7}
+	data := []struct {
+		payload           string
+		packCosts         map[int]int
+		expectedObjective pack.Objective
+		expectedName      string
+		expectedCost      string
+	}{
+		{
+			payload:           `{"size": 251}`,
+			expectedObjective: pack.ItemsThenPacks{},
+			expectedName:      pack.ObjectiveItemsThenPacks,
+		},
+		{
+			payload:           `{"size": 251, "objective": "packs_then_items"}`,
+			expectedObjective: pack.PacksThenItems{},
+			expectedName:      pack.ObjectivePacksThenItems,
+		},
+		{
+			payload:           `{"size": 251, "objective": "cost"}`,
+			packCosts:         cost.UnitCosts,
+			expectedObjective: cost,
+			expectedName:      pack.ObjectiveCost,
+			expectedCost:      `,"cost":13`,
+		},
+		{
+			payload:           `{"size": 251}`,
+			packCosts:         cost.UnitCosts,
+			expectedObjective: pack.ItemsThenPacks{},
+			expectedName:      pack.ObjectiveItemsThenPacks,
+			expectedCost:      `,"cost":13`,
+		},
+	}
+
+	for _, d := range data {
+		t.Run(fmt.Sprintf("with payload: '%s' and pack costs: %v", d.payload, d.packCosts), func(t *testing.T) {
+			comp := TestPackComputer{result: []pack.Pack{{Size: 250, Quantity: 2}}}
+			cfg := repository.Config{
+				PackSizes:   []int{250, 500},
+				PackCosts:   d.packCosts,
+				HandlingFee: cost.HandlingFee,
+			}
+			repo := TestSuccessRepository{result: cfg}
+			handler := NewHandler(&comp, &repo)
+
+			rr := httptest.NewRecorder()
+			req := newCreateOrderRequestWithPayload(t, d.payload)
+
+			handler.ServeHTTP(rr, req)
+
+			assertStatusOk(t, rr)
+			if fmt.Sprint(comp.passedObjective) != fmt.Sprint(d.expectedObjective) {
+				t.Errorf("unexpected objective passed to computer: got '%+v' want '%+v'", comp.passedObjective, d.expectedObjective)
+			}
+			if repo.passedOrder.Objective != d.expectedName {
+				t.Errorf("unexpected order objective: got '%s' want '%s'", repo.passedOrder.Objective, d.expectedName)
+			}
+			assertBody(t, rr, `{"id":1,"product":"default","size":251,"packs":[{"size":250,"quantity":2}],`+
+				`"pack_sizes":[250,500],"config_version":0,"created_at":"2024-01-01T00:00:00Z","status":"quoted",`+
+				`"expires_at":"2024-01-01T00:15:00Z","objective":"`+d.expectedName+`"`+d.expectedCost+`,"solver":"dp"}`)
+		})
+	}
+}
+
+func TestServeHTTP_HandleCreateOrder_InvalidObjective(t *testing.T) {
+	comp := TestPackComputer{}
+	repo := TestSuccessRepository{}
+	handler := NewHandler(&comp, &repo)
+
+	rr := httptest.NewRecorder()
+	req := newCreateOrderRequestWithPayload(t, `{"size": 251, "objective": "fastest"}`)
+
+	handler.ServeHTTP(rr, req)
+
+	assertErrorResponse(t, rr, http.StatusBadRequest, "invalid_objective")
+}
+
+func TestServeHTTP_HandleCreateOrder_NoPackCosts(t *testing.T) {
+	comp := TestPackComputer{}
+	repo := TestSuccessRepository{result: repository.Config{PackSizes: []int{250, 500}}}
+	handler := NewHandler(&comp, &repo)
+
+	rr := httptest.NewRecorder()
+	req := newCreateOrderRequestWithPayload(t, `{"size": 251, "objective": "cost"}`)
+
+	handler.ServeHTTP(rr, req)
+
+	assertErrorResponse(t, rr, http.StatusUnprocessableEntity, "no_pack_costs")
+}
+
 func TestServeHTTP_HandleCreateOrder_Headers(t *testing.T) {
 	comp := TestPackComputer{}
 	repo := TestSuccessRepository{}
@@ -173,113 +724,279 @@ func TestServeHTTP_HandleCreateOrder_CorsHeaders(t *testing.T) {
 	assertCorsHeaders(t, rr)
 }
 
-func TestServeHTTP_HandleSetConfig_Success(t *testing.T) {
+func TestServeHTTP_HandleGetOrder_Success(t *testing.T) {
 	comp := TestPackComputer{}
-	repo := TestSuccessRepository{}
+	repo := TestSuccessRepository{
+		orderResult: repository.Order{
+			ID:      7,
+			Product: "tea",
+			Size:    501,
+			Packs: []repository.Pack{
+				{
+					Size:     500,
+					Quantity: 1,
+				},
+				{
+					Size:     250,
+					Quantity: 1,
+				},
+			},
+			PackSizes: []int{250, 500},
+			CreatedAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		},
+	}
 	handler := NewHandler(&comp, &repo)
 
 	rr := httptest.NewRecorder()
-	req := newCreateConfigRequestWithPayload(t, `{"pack_sizes": [100, 200]}`)
+	req := newGetOrderRequest(t, "7")
 
 	handler.ServeHTTP(rr, req)
 
 	assertStatusOk(t, rr)
+	if repo.passedOrderID != 7 {
+		t.Errorf("unexpected order id passed to repository: got '%d' want '%d'", repo.passedOrderID, 7)
+	}
+	assertBody(t, rr, `{"id":7,"product":"tea","size":501,"packs":[{"size":500,"quantity":1},{"size":250,"quantity":1}],"pack_sizes":[250,500],"config_version":0,"created_at":"2024-01-01T00:00:00Z"}`)
+}
 
-	cfg := repository.Config{
-		PackSizes: []int{100, 200},
+func TestServeHTTP_HandleGetOrder_InvalidOrderID(t *testing.T) {
+	invalidIDs := []string{"abc", "0", "-1"}
+
+	for _, id := range invalidIDs {
+		t.Run(fmt.Sprintf("with id: %s", id), func(t *testing.T) {
+			comp := TestPackComputer{}
+			repo := TestSuccessRepository{}
+			handler := NewHandler(&comp, &repo)
+
+			rr := httptest.NewRecorder()
+			req := newGetOrderRequest(t, id)
+
+			handler.ServeHTTP(rr, req)
+
+			assertBadRequestResponse(t, rr, "invalid_order_id", "Order ids must be integers greater than zero.")
+		})
 	}
-	assertRepositoryReceivedConfig(t, repo, cfg)
 }
 
-func TestServeHTTP_HandleSetConfig_InvalidPayload(t *testing.T) {
+func TestServeHTTP_HandleGetOrder_NotFound(t *testing.T) {
 	comp := TestPackComputer{}
-	repo := TestSuccessRepository{}
+	repo := TestSuccessRepository{findOrderErr: repository.ErrNotFound}
 	handler := NewHandler(&comp, &repo)
 
 	rr := httptest.NewRecorder()
-	req := newCreateConfigRequestWithPayload(t, "{pack_sizes}")
+	req := newGetOrderRequest(t, "1")
 
 	handler.ServeHTTP(rr, req)
 
-	assertBadRequestResponse(t, rr, "invalid_payload", "Invalid payload.")
+	assertErrorResponse(t, rr, http.StatusNotFound, "order_not_found")
 }
 
-func TestServeHTTP_HandleSetConfig_InvalidPackSizes(t *testing.T) {
-	payloads := []string{"{}", `{"pack_sizes": []}`, `{"pack_sizes": [-1, 100]}`, `{"pack_sizes": [0, 100]}`}
+func TestServeHTTP_HandleGetOrder_PathNotFound(t *testing.T) {
+	paths := []string{"/config/versions/3", "/1/confirm", "/1/", "//1"}
 
-	for _, payload := range payloads {
-		t.Run(fmt.Sprintf("with payload: '%s'", payload), func(t *testing.T) {
+	for _, path := range paths {
+		t.Run(fmt.Sprintf("with path: %s", path), func(t *testing.T) {
 			comp := TestPackComputer{}
 			repo := TestSuccessRepository{}
 			handler := NewHandler(&comp, &repo)
 
 			rr := httptest.NewRecorder()
-			req := newCreateConfigRequestWithPayload(t, payload)
+			req := httptest.NewRequest(http.MethodGet, Path+path, nil)
 
 			handler.ServeHTTP(rr, req)
 
-			assertBadRequestResponse(t, rr, "invalid_pack_sizes", "Pack sizes should have at least one size and all sizes should be greater than zero.")
+			assertErrorResponse(t, rr, http.StatusNotFound, "path_not_found")
+			if repo.passedOrderID != 0 {
+				t.Errorf("unexpected order id passed to repository: got '%d'", repo.passedOrderID)
+			}
 		})
 	}
 }
 
-func TestServeHTTP_HandleSetConfig_RemoveDuplicates(t *testing.T) {
-	comp := TestPackComputer{}
-	repo := TestSuccessRepository{}
-	handler := NewHandler(&comp, &repo)
+func TestServeHTTP_StripPrefix(t *testing.T) {
+	data := []struct {
+		method          string
+		target          string
+		expectedStatus  int
+		expectedErrCode string
+		expectedLimit   int
+	}{
+		{method: http.MethodGet, target: Path, expectedStatus: http.StatusOK, expectedLimit: defaultListLimit + 1},
+		{method: http.MethodGet, target: Path + "/", expectedStatus: http.StatusOK, expectedLimit: defaultListLimit + 1},
+		{method: http.MethodGet, target: Path + "?limit=1", expectedStatus: http.StatusOK, expectedLimit: 2},
+		{method: http.MethodGet, target: Path + "/config", expectedStatus: http.StatusOK},
+		{method: http.MethodPut, target: Path, expectedStatus: http.StatusNotFound, expectedErrCode: "path_not_found"},
+		{method: http.MethodGet, target: Path + "/config/versions/3", expectedStatus: http.StatusNotFound, expectedErrCode: "path_not_found"},
+	}
 
-	rr := httptest.NewRecorder()
-	req := newCreateConfigRequestWithPayload(t, `{"pack_sizes": [100, 100, 200, 200]}`)
+	for _, d := range data {
+		t.Run(fmt.Sprintf("with %s %s", d.method, d.target), func(t *testing.T) {
+			comp := TestPackComputer{}
+			repo := TestSuccessRepository{}
+			handler := NewHandler(&comp, &repo)
 
-	handler.ServeHTTP(rr, req)
+			rr := httptest.NewRecorder()
+			req := httptest.NewRequest(d.method, d.target, nil)
 
-	cfg := repository.Config{
-		PackSizes: []int{100, 200},
+			http.StripPrefix(Path, &handler).ServeHTTP(rr, req)
+
+			if d.expectedErrCode != "" {
+				assertErrorResponse(t, rr, d.expectedStatus, d.expectedErrCode)
+				return
+			}
+			if rr.Code != d.expectedStatus {
+				t.Errorf("unexpected status code: got '%d' want '%d'", rr.Code, d.expectedStatus)
+			}
+			if repo.passedFilter.Limit != d.expectedLimit {
+				t.Errorf("unexpected limit passed to repository: got '%d' want '%d'", repo.passedFilter.Limit, d.expectedLimit)
+			}
+		})
 	}
-	assertRepositoryReceivedConfig(t, repo, cfg)
 }
 
-func TestServeHTTP_HandleSetConfig_InternalServerError(t *testing.T) {
+func TestServeHTTP_HandleGetOrder_InternalServerError(t *testing.T) {
 	comp := TestPackComputer{}
 	repo := TestErrRepository{}
 	handler := NewHandler(&comp, &repo)
 
 	rr := httptest.NewRecorder()
-	req := newCreateConfigRequestWithPayload(t, `{"pack_sizes": [100, 200]}`)
+	req := newGetOrderRequest(t, "1")
 
 	handler.ServeHTTP(rr, req)
 
 	assertInternalServerErrorResponse(t, rr)
 }
 
-func TestServeHTTP_HandleSetConfig_Headers(t *testing.T) {
+func TestServeHTTP_HandleListOrders_Success(t *testing.T) {
+	createdAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	data := []struct {
+		limit              int
+		expectedNextCursor bool
+	}{
+		{
+			limit:              1,
+			expectedNextCursor: true,
+		},
+		{
+			limit:              2,
+			expectedNextCursor: false,
+		},
+	}
+
+	for _, d := range data {
+		t.Run(fmt.Sprintf("with limit: %d", d.limit), func(t *testing.T) {
+			comp := TestPackComputer{}
+			repo := TestSuccessRepository{
+				ordersResult: []repository.Order{
+					{ID: 2, Size: 1, Packs: []repository.Pack{{Size: 250, Quantity: 1}}, CreatedAt: createdAt},
+					{ID: 1, Size: 1, Packs: []repository.Pack{{Size: 250, Quantity: 1}}, CreatedAt: createdAt},
+				},
+			}
+			handler := NewHandler(&comp, &repo)
+
+			rr := httptest.NewRecorder()
+			req := newListOrdersRequest(t, fmt.Sprintf("?limit=%d&pack_size=250", d.limit))
+
+			handler.ServeHTTP(rr, req)
+
+			assertStatusOk(t, rr)
+			if repo.passedFilter.Limit != d.limit+1 || repo.passedFilter.PackSize != 250 {
+				t.Errorf("unexpected filter passed to repository: got '%+v'", repo.passedFilter)
+			}
+
+			var page OrderPage
+			if err := json.NewDecoder(rr.Body).Decode(&page); err != nil {
+				t.Fatal(err)
+			}
+			if len(page.Orders) != d.limit || page.Orders[0].ID != 2 {
+				t.Errorf("unexpected orders: got '%+v'", page.Orders)
+			}
+			if (page.NextCursor != "") != d.expectedNextCursor {
+				t.Errorf("unexpected next cursor: got '%s'", page.NextCursor)
+			}
+		})
+	}
+}
+
+func TestServeHTTP_HandleListOrders_NextCursor(t *testing.T) {
+	createdAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
 	comp := TestPackComputer{}
-	repo := TestSuccessRepository{}
+	repo := TestSuccessRepository{
+		ordersResult: []repository.Order{
+			{ID: 3, CreatedAt: createdAt},
+			{ID: 2, CreatedAt: createdAt},
+		},
+	}
 	handler := NewHandler(&comp, &repo)
 
 	rr := httptest.NewRecorder()
-	req := newCreateConfigRequestWithPayload(t, `{"pack_sizes": [100, 200]}`)
+	handler.ServeHTTP(rr, newListOrdersRequest(t, "?limit=1"))
 
-	handler.ServeHTTP(rr, req)
+	var page OrderPage
+	if err := json.NewDecoder(rr.Body).Decode(&page); err != nil {
+		t.Fatal(err)
+	}
 
-	assertHeader(t, rr, "Content-Type", "application/json")
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, newListOrdersRequest(t, "?limit=1&cursor="+page.NextCursor))
+
+	assertStatusOk(t, rr)
+	expectedAfter := repository.OrderCursor{CreatedAt: createdAt, ID: 3}
+	if repo.passedFilter.After == nil || *repo.passedFilter.After != expectedAfter {
+		t.Errorf("unexpected cursor passed to repository: got '%+v' want '%+v'", repo.passedFilter.After, expectedAfter)
+	}
+}
+
+func TestServeHTTP_HandleListOrders_InvalidQuery(t *testing.T) {
+	data := []struct {
+		query           string
+		expectedErrCode string
+	}{
+		{
+			query:           "?created_from=yesterday",
+			expectedErrCode: "invalid_filter",
+		},
+		{
+			query:           "?min_size=0",
+			expectedErrCode: "invalid_filter",
+		},
+		{
+			query:           "?limit=1001",
+			expectedErrCode: "invalid_limit",
+		},
+		{
+			query:           "?cursor=abc",
+			expectedErrCode: "invalid_cursor",
+		},
+	}
+
+	for _, d := range data {
+		t.Run(fmt.Sprintf("with query: %s", d.query), func(t *testing.T) {
+			comp := TestPackComputer{}
+			repo := TestSuccessRepository{}
+			handler := NewHandler(&comp, &repo)
+
+			rr := httptest.NewRecorder()
+			req := newListOrdersRequest(t, d.query)
+
+			handler.ServeHTTP(rr, req)
+
+			assertErrorResponse(t, rr, http.StatusBadRequest, d.expectedErrCode)
+		})
+	}
 }
 
-func TestServeHTTP_HandleSetConfig_CorsHeaders(t *testing.T) {
+func TestServeHTTP_HandleListOrders_InternalServerError(t *testing.T) {
 	comp := TestPackComputer{}
-	repo := TestSuccessRepository{}
+	repo := TestErrRepository{}
 	handler := NewHandler(&comp, &repo)
 
 	rr := httptest.NewRecorder()
-	req, err := http.NewRequest(http.MethodOptions, Path+configPath, nil)
-	if err != nil {
-		t.Fatal(err)
-	}
+	req := newListOrdersRequest(t, "")
 
 	handler.ServeHTTP(rr, req)
 
-	assertStatusOk(t, rr)
-	assertCorsHeaders(t, rr)
+	assertInternalServerErrorResponse(t, rr)
 }
 
 func newCreateOrderRequestWithPayload(t *testing.T, payload string) *http.Request {
@@ -290,8 +1007,16 @@ func newCreateOrderRequestWithPayload(t *testing.T, payload string) *http.Reques
 	return req
 }
 
-func newCreateConfigRequestWithPayload(t *testing.T, payload string) *http.Request {
-	req, err := http.NewRequest(http.MethodPut, Path+configPath, bytes.NewReader([]byte(payload)))
+func newGetOrderRequest(t *testing.T, id string) *http.Request {
+	req, err := http.NewRequest(http.MethodGet, Path+"/"+id, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return req
+}
+
+func newListOrdersRequest(t *testing.T, query string) *http.Request {
+	req, err := http.NewRequest(http.MethodGet, Path+query, nil)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -323,11 +1048,18 @@ func assertOrderPacks(t *testing.T, rr *httptest.ResponseRecorder, expected []pa
 	}
 }
 
-func assertRepositoryReceivedConfig(t *testing.T, repo TestSuccessRepository, expected repository.Config) {
-	slices.Sort(repo.passedCfg.PackSizes)
-	slices.Sort(expected.PackSizes)
-	if !slices.Equal(repo.passedCfg.PackSizes, expected.PackSizes) {
-		t.Errorf("unexpected config pack sizes: got '%+v' want '%+v'", repo.passedCfg.PackSizes, expected.PackSizes)
+func assertRepositoryReceivedOrder(t *testing.T, repo TestSuccessRepository, expected repository.Order) {
+	if repo.passedOrder.Product != expected.Product {
+		t.Errorf("unexpected order product: got '%s' want '%s'", repo.passedOrder.Product, expected.Product)
+	}
+	if repo.passedOrder.Size != expected.Size {
+		t.Errorf("unexpected order size: got '%d' want '%d'", repo.passedOrder.Size, expected.Size)
+	}
+	if !slices.Equal(repo.passedOrder.Packs, expected.Packs) {
+		t.Errorf("unexpected order packs: got '%+v' want '%+v'", repo.passedOrder.Packs, expected.Packs)
+	}
+	if !slices.Equal(repo.passedOrder.PackSizes, expected.PackSizes) {
+		t.Errorf("unexpected order pack sizes: got '%+v' want '%+v'", repo.passedOrder.PackSizes, expected.PackSizes)
 	}
 }
 
@@ -348,6 +1080,21 @@ func assertBadRequestResponse(t *testing.T, rr *httptest.ResponseRecorder, expec
 	}
 }
 
+func assertErrorResponse(t *testing.T, rr *httptest.ResponseRecorder, expectedStatus int, expectedErrCode string) {
+	if rr.Code != expectedStatus {
+		t.Errorf("unexpected status code: got '%d' want '%d'", rr.Code, expectedStatus)
+	}
+
+	var errResp ErrorResponse
+	if err := json.NewDecoder(rr.Body).Decode(&errResp); err != nil {
+		t.Fatal(err)
+	}
+
+	if errResp.Code != expectedErrCode {
+		t.Errorf("unexpected error code: got '%s' want '%s'", errResp.Code, expectedErrCode)
+	}
+}
+
 func assertInternalServerErrorResponse(t *testing.T, rr *httptest.ResponseRecorder) {
 	if rr.Code != http.StatusInternalServerError {
 		t.Errorf("unexpected status code: got '%d' want '%d'", rr.Code, http.StatusInternalServerError)
@@ -359,10 +1106,17 @@ func assertInternalServerErrorResponse(t *testing.T, rr *httptest.ResponseRecord
 	}
 }
 
+func assertBody(t *testing.T, rr *httptest.ResponseRecorder, expected string) {
+	if rr.Body.String() != expected {
+		t.Errorf("unexpected body: got '%s' want '%s'", rr.Body.String(), expected)
+	}
+}
+
 func assertCorsHeaders(t *testing.T, rr *httptest.ResponseRecorder) {
 	assertHeader(t, rr, "Access-Control-Allow-Origin", "*")
-	assertHeader(t, rr, "Access-Control-Allow-Methods", "POST, PUT")
+	assertHeader(t, rr, "Access-Control-Allow-Methods", "POST, PUT, DELETE")
 	assertHeader(t, rr, "Access-Control-Allow-Headers", "*")
+	assertHeader(t, rr, "Access-Control-Expose-Headers", "ETag, Last-Modified, X-Request-ID")
 }
 
 func assertHeader(t *testing.T, rr *httptest.ResponseRecorder, name, expected string) {
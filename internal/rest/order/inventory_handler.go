@@ -0,0 +1,201 @@
+package order
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"math"
+	"net/http"
+	"packer/internal/rest/order/pack"
+	"packer/internal/rest/order/repository"
+	"strconv"
+	"strings"
+)
+
+const InventoryPath = "/inventory"
+
+type InventoryRepository interface {
+	FindStockLevels(ctx context.Context, products []string) ([]repository.StockLevel, error)
+	SetStockLevels(ctx context.Context, product string, levels []repository.StockLevel) ([]repository.StockLevel, error)
+	DeleteStockLevel(ctx context.Context, product string, packSize int) error
+}
+
+// InventoryHandler handles the stock of the products' pack sizes, which limits the packs of the orders (see
+// pack.Computer.ComputePacksWithStock).
+type InventoryHandler struct {
+	handler    *Handler
+	repository InventoryRepository
+}
+
+func NewInventoryHandler(handler *Handler, repository InventoryRepository) InventoryHandler {
+	return InventoryHandler{
+		handler:    handler,
+		repository: repository,
+	}
+}
+
+func (h *InventoryHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	setHeaders(w) // could be more granular
+
+	sku, packSize := parseInventoryPath(r.URL.Path)
+
+	switch {
+	case sku == "" && r.Method == http.MethodGet:
+		h.handleListStock(w, r)
+	case sku == "":
+		// no other methods on the inventory
+	case packSize == "" && r.Method == http.MethodGet:
+		h.handleGetStock(w, r, sku)
+	case packSize == "" && r.Method == http.MethodPut:
+		h.handleSetStock(w, r, sku)
+	case packSize != "" && r.Method == http.MethodDelete:
+		h.handleDeleteStockLevel(w, r, sku, packSize)
+	}
+}
+
+// parseInventoryPath parses the SKU and the pack size after it (e.g. "tea" and "50" for "/tea/50").
+func parseInventoryPath(path string) (string, string) {
+	path = strings.TrimPrefix(strings.TrimPrefix(path, InventoryPath), "/")
+	sku, packSize, _ := strings.Cut(strings.TrimSuffix(path, "/"), "/")
+	return sku, packSize
+}
+
+// handleListStock lists the stock levels of every product, or of the product query parameter.
+func (h *InventoryHandler) handleListStock(w http.ResponseWriter, r *http.Request) {
+	var products []string
+	if product := r.URL.Query().Get("product"); product != "" {
+		products = []string{product}
+	}
+	h.writeStockLevels(w, r, products)
+}
+
+func (h *InventoryHandler) handleGetStock(w http.ResponseWriter, r *http.Request, sku string) {
+	h.writeStockLevels(w, r, []string{sku})
+}
+
+func (h *InventoryHandler) writeStockLevels(w http.ResponseWriter, r *http.Request, products []string) {
+	ctx := r.Context()
+
+	levels, err := h.repository.FindStockLevels(ctx, products)
+	if err != nil {
+		slog.ErrorContext(ctx, "unable to find the stock levels", "error", err)
+		h.handler.writeInternalServerErrorResponse(w)
+		return
+	}
+
+	h.writeJSONResponse(ctx, w, newInventory(levels))
+}
+
+// handleSetStock sets the stock levels of the given pack sizes of the product. The stock levels of other pack sizes
+// are kept. Quantities cannot be set below the quantities reserved by quoted orders.
+func (h *InventoryHandler) handleSetStock(w http.ResponseWriter, r *http.Request, sku string) {
+	ctx := r.Context()
+
+	var stockReq SetStockRequest
+	if err := json.NewDecoder(r.Body).Decode(&stockReq); err != nil {
+		h.handler.writeBadRequestResponse(w, errRespInvalidPayload)
+		return
+	}
+
+	if !stockLevelsValid(stockReq.Stock) {
+		h.handler.writeBadRequestResponse(w, errRespInvalidStock)
+		return
+	}
+
+	levels := make([]repository.StockLevel, len(stockReq.Stock))
+	for i, level := range stockReq.Stock {
+		levels[i] = repository.StockLevel{Product: sku, PackSize: level.PackSize, Quantity: level.Quantity}
+	}
+
+	levels, err := h.repository.SetStockLevels(ctx, sku, levels)
+	if errors.Is(err, repository.ErrNotFound) {
+		h.handler.writeErrorResponse(w, errRespProductNotFound, http.StatusNotFound)
+		return
+	}
+	if errors.Is(err, repository.ErrStockReserved) {
+		h.handler.writeErrorResponse(w, errRespStockReserved, http.StatusConflict)
+		return
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "unable to set the stock level", "error", err)
+		h.handler.writeInternalServerErrorResponse(w)
+		return
+	}
+
+	h.writeJSONResponse(ctx, w, newInventory(levels))
+}
+
+// stockLevelsValid returns true if there are stock levels, all of them of different pack sizes greater than zero and
+// with quantities not less than zero (that fit in the database), false otherwise.
+func stockLevelsValid(levels []StockLevelRequest) bool {
+	if len(levels) == 0 {
+		return false
+	}
+
+	packSizes := make(map[int]bool, len(levels))
+	for _, level := range levels {
+		if level.PackSize <= 0 || level.PackSize > math.MaxInt32 || level.Quantity < 0 ||
+			level.Quantity > math.MaxInt32 || packSizes[level.PackSize] {
+			return false
+		}
+		packSizes[level.PackSize] = true
+	}
+	return true
+}
+
+// handleDeleteStockLevel deletes the stock level of the product's pack size, which becomes unlimited.
+func (h *InventoryHandler) handleDeleteStockLevel(w http.ResponseWriter, r *http.Request, sku, packSizeParam string) {
+	ctx := r.Context()
+
+	packSize, err := strconv.Atoi(packSizeParam)
+	if err != nil || packSize <= 0 {
+		h.handler.writeBadRequestResponse(w, errRespInvalidPackSize)
+		return
+	}
+
+	err = h.repository.DeleteStockLevel(ctx, sku, packSize)
+	if errors.Is(err, repository.ErrNotFound) {
+		h.handler.writeErrorResponse(w, errRespStockLevelNotFound, http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "unable to delete the stock level", "error", err)
+		h.handler.writeInternalServerErrorResponse(w)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *InventoryHandler) writeJSONResponse(ctx context.Context, w http.ResponseWriter, v any) {
+	jsonBytes, err := json.Marshal(v)
+	if err != nil {
+		slog.ErrorContext(ctx, "unable to encode the response", "error", err)
+		h.handler.writeInternalServerErrorResponse(w)
+		return
+	}
+
+	h.handler.writeMessageWithStatusResponse(w, jsonBytes, http.StatusOK)
+}
+
+// stockByProduct returns the quantity available (i.e. not reserved) of every pack size with a stock level, by product.
+func stockByProduct(levels []repository.StockLevel) map[string]map[int]int {
+	result := make(map[string]map[int]int)
+	for _, level := range levels {
+		if result[level.Product] == nil {
+			result[level.Product] = make(map[int]int)
+		}
+		result[level.Product][level.PackSize] = level.Available()
+	}
+	return result
+}
+
+// takeFromStock removes the packs from the stock. Pack sizes without a stock level are unlimited.
+func takeFromStock(stock map[int]int, packs []pack.Pack) {
+	for _, p := range packs {
+		if _, limited := stock[p.Size]; limited {
+			stock[p.Size] -= p.Quantity
+		}
+	}
+}
@@ -0,0 +1,258 @@
+package order
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"packer/internal/rest/order/pack"
+	"packer/internal/rest/order/repository"
+	"slices"
+	"testing"
+	"time"
+)
+
+// TestInventoryRepository records the passed stock levels and returns levelsResult and err.
+type TestInventoryRepository struct {
+	passedProducts []string
+	passedProduct  string
+	passedLevels   []repository.StockLevel
+	passedPackSize int
+	levelsResult   []repository.StockLevel
+	err            error
+}
+
+func (repo *TestInventoryRepository) FindStockLevels(_ context.Context, products []string) ([]repository.StockLevel, error) {
+	repo.passedProducts = products
+	return repo.levelsResult, repo.err
+}
+
+func (repo *TestInventoryRepository) SetStockLevels(_ context.Context, product string, levels []repository.StockLevel) ([]repository.StockLevel, error) {
+	repo.passedProduct = product
+	repo.passedLevels = levels
+	return repo.levelsResult, repo.err
+}
+
+func (repo *TestInventoryRepository) DeleteStockLevel(_ context.Context, product string, packSize int) error {
+	repo.passedProduct = product
+	repo.passedPackSize = packSize
+	return repo.err
+}
+
+func TestInventoryHandler_HandleListStock_Success(t *testing.T) {
+	data := []struct {
+		path             string
+		expectedProducts []string
+	}{
+		{
+			path: "",
+		},
+		{
+			path:             "?product=tea",
+			expectedProducts: []string{"tea"},
+		},
+		{
+			path:             "/tea",
+			expectedProducts: []string{"tea"},
+		},
+	}
+
+	for _, d := range data {
+		t.Run(fmt.Sprintf("with path: '%s'", d.path), func(t *testing.T) {
+			inventoryRepo := TestInventoryRepository{
+				levelsResult: []repository.StockLevel{
+					{
+						Product:   "tea",
+						PackSize:  50,
+						Quantity:  3,
+						Reserved:  1,
+						UpdatedAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+					},
+				},
+			}
+			handler := newTestInventoryHandler(&inventoryRepo)
+
+			rr := httptest.NewRecorder()
+			req := newInventoryRequest(t, http.MethodGet, d.path, "")
+
+			handler.ServeHTTP(rr, req)
+
+			assertStatusOk(t, rr)
+			assertBody(t, rr, `{"stock":[{"product":"tea","pack_size":50,"quantity":3,"reserved":1,"updated_at":"2024-01-01T00:00:00Z"}]}`)
+			if !slices.Equal(inventoryRepo.passedProducts, d.expectedProducts) {
+				t.Errorf("unexpected products passed to repository: got '%+v' want '%+v'",
+					inventoryRepo.passedProducts, d.expectedProducts)
+			}
+		})
+	}
+}
+
+func TestInventoryHandler_HandleSetStock_Success(t *testing.T) {
+	inventoryRepo := TestInventoryRepository{}
+	handler := newTestInventoryHandler(&inventoryRepo)
+
+	rr := httptest.NewRecorder()
+	req := newInventoryRequest(t, http.MethodPut, "/tea",
+		`{"stock": [{"pack_size": 50, "quantity": 3}, {"pack_size": 10, "quantity": 0}]}`)
+
+	handler.ServeHTTP(rr, req)
+
+	assertStatusOk(t, rr)
+	assertBody(t, rr, `{"stock":[]}`)
+	expectedLevels := []repository.StockLevel{
+		{Product: "tea", PackSize: 50, Quantity: 3},
+		{Product: "tea", PackSize: 10, Quantity: 0},
+	}
+	if inventoryRepo.passedProduct != "tea" || !slices.Equal(inventoryRepo.passedLevels, expectedLevels) {
+		t.Errorf("unexpected stock levels passed to repository: got '%s' '%+v' want '%s' '%+v'",
+			inventoryRepo.passedProduct, inventoryRepo.passedLevels, "tea", expectedLevels)
+	}
+}
+
+func TestInventoryHandler_HandleSetStock_BadRequest(t *testing.T) {
+	data := []struct {
+		payload         string
+		expectedErrCode string
+	}{
+		{
+			payload:         "{stock}",
+			expectedErrCode: "invalid_payload",
+		},
+		{
+			payload:         `{"stock": []}`,
+			expectedErrCode: "invalid_stock",
+		},
+		{
+			payload:         `{"stock": [{"pack_size": 0, "quantity": 1}]}`,
+			expectedErrCode: "invalid_stock",
+		},
+		{
+			payload:         `{"stock": [{"pack_size": 50, "quantity": -1}]}`,
+			expectedErrCode: "invalid_stock",
+		},
+		{
+			payload:         `{"stock": [{"pack_size": 50, "quantity": 1}, {"pack_size": 50, "quantity": 2}]}`,
+			expectedErrCode: "invalid_stock",
+		},
+	}
+
+	for _, d := range data {
+		t.Run(fmt.Sprintf("with payload: '%s'", d.payload), func(t *testing.T) {
+			handler := newTestInventoryHandler(&TestInventoryRepository{})
+
+			rr := httptest.NewRecorder()
+			req := newInventoryRequest(t, http.MethodPut, "/tea", d.payload)
+
+			handler.ServeHTTP(rr, req)
+
+			assertErrorResponse(t, rr, http.StatusBadRequest, d.expectedErrCode)
+		})
+	}
+}
+
+func TestInventoryHandler_HandleSetStock_ProductNotFound(t *testing.T) {
+	handler := newTestInventoryHandler(&TestInventoryRepository{err: repository.ErrNotFound})
+
+	rr := httptest.NewRecorder()
+	req := newInventoryRequest(t, http.MethodPut, "/tea", `{"stock": [{"pack_size": 50, "quantity": 3}]}`)
+
+	handler.ServeHTTP(rr, req)
+
+	assertErrorResponse(t, rr, http.StatusNotFound, "product_not_found")
+}
+
+func TestInventoryHandler_HandleSetStock_StockReserved(t *testing.T) {
+	handler := newTestInventoryHandler(&TestInventoryRepository{err: repository.ErrStockReserved})
+
+	rr := httptest.NewRecorder()
+	req := newInventoryRequest(t, http.MethodPut, "/tea", `{"stock": [{"pack_size": 50, "quantity": 0}]}`)
+
+	handler.ServeHTTP(rr, req)
+
+	assertErrorResponse(t, rr, http.StatusConflict, "stock_reserved")
+}
+
+func TestInventoryHandler_HandleDeleteStockLevel(t *testing.T) {
+	data := []struct {
+		path            string
+		err             error
+		expectedStatus  int
+		expectedErrCode string
+	}{
+		{
+			path:           "/tea/50",
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			path:            "/tea/0",
+			expectedStatus:  http.StatusBadRequest,
+			expectedErrCode: "invalid_pack_size",
+		},
+		{
+			path:            "/tea/50",
+			err:             repository.ErrNotFound,
+			expectedStatus:  http.StatusNotFound,
+			expectedErrCode: "stock_level_not_found",
+		},
+		{
+			path:            "/tea/50",
+			err:             errors.New("test error"),
+			expectedStatus:  http.StatusInternalServerError,
+			expectedErrCode: "internal_server_error",
+		},
+	}
+
+	for _, d := range data {
+		t.Run(fmt.Sprintf("with path '%s' and error: %v", d.path, d.err), func(t *testing.T) {
+			inventoryRepo := TestInventoryRepository{err: d.err}
+			handler := newTestInventoryHandler(&inventoryRepo)
+
+			rr := httptest.NewRecorder()
+			req := newInventoryRequest(t, http.MethodDelete, d.path, "")
+
+			handler.ServeHTTP(rr, req)
+
+			if d.expectedErrCode == "" {
+				if rr.Code != d.expectedStatus {
+					t.Errorf("unexpected status code: got '%d' want '%d'", rr.Code, d.expectedStatus)
+				}
+				if inventoryRepo.passedProduct != "tea" || inventoryRepo.passedPackSize != 50 {
+					t.Errorf("unexpected stock level passed to repository: got '%s' '%d' want '%s' '%d'",
+						inventoryRepo.passedProduct, inventoryRepo.passedPackSize, "tea", 50)
+				}
+				return
+			}
+			assertErrorResponse(t, rr, d.expectedStatus, d.expectedErrCode)
+		})
+	}
+}
+
+func TestTakeFromStock(t *testing.T) {
+	stocks := stockByProduct([]repository.StockLevel{
+		{Product: "tea", PackSize: 50, Quantity: 3},
+		{Product: "tea", PackSize: 10, Quantity: 5, Reserved: 2},
+	})
+
+	takeFromStock(stocks["tea"], []pack.Pack{{Size: 50, Quantity: 2}, {Size: 20, Quantity: 4}})
+
+	expected := map[int]int{50: 1, 10: 3}
+	if !maps.Equal(stocks["tea"], expected) {
+		t.Errorf("unexpected stock: got '%+v' want '%+v'", stocks["tea"], expected)
+	}
+}
+
+func newTestInventoryHandler(inventoryRepo InventoryRepository) InventoryHandler {
+	handler := NewHandler(&TestPackComputer{}, &TestSuccessRepository{})
+	return NewInventoryHandler(&handler, inventoryRepo)
+}
+
+func newInventoryRequest(t *testing.T, method, path, payload string) *http.Request {
+	req, err := http.NewRequest(method, InventoryPath+path, bytes.NewReader([]byte(payload)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return req
+}
@@ -0,0 +1,169 @@
+package order
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"packer/internal/rest/order/pack"
+	"packer/internal/rest/order/repository"
+	"time"
+)
+
+const maxLines = 100
+
+// errLines is returned when the packs of some lines cannot be computed, see the line errors.
+var errLines = errors.New("lines cannot be computed")
+
+// handleCreateLinesOrder computes the packs of every line with a single snapshot of the products' configs and saves
+// the lines as quoted orders of their products, all or none.
+// The lines are validated and computed individually, so the error response has the error of every invalid line.
+func (h *Handler) handleCreateLinesOrder(w http.ResponseWriter, r *http.Request, orderReq Request, opts computeOptions, packSort string) {
+	ctx := r.Context()
+
+	if orderReq.Product != "" || orderReq.Size != 0 {
+		h.writeBadRequestResponse(w, errRespInvalidPayload)
+		return
+	}
+
+	if len(orderReq.Lines) == 0 || len(orderReq.Lines) > maxLines {
+		h.writeBadRequestResponse(w, errRespInvalidLineCount)
+		return
+	}
+
+	if lineErrs := validateLines(orderReq.Lines); len(lineErrs) > 0 {
+		errResp := errRespInvalidLines
+		errResp.Lines = lineErrs
+		h.writeBadRequestResponse(w, errResp)
+		return
+	}
+
+	var orders []repository.Order
+	var lineErrs []LineErrorResponse
+	var err error
+	if orderReq.AsOf != nil {
+		orders, lineErrs, err = h.computeLines(ctx, orderReq.Lines, opts, *orderReq.AsOf)
+	} else {
+		orders, lineErrs, err = h.createLines(ctx, orderReq.Lines, opts)
+	}
+	if errors.Is(err, errLines) {
+		errResp := errRespUnfulfillableLines
+		errResp.Lines = lineErrs
+		h.writeErrorResponse(w, errResp, http.StatusUnprocessableEntity)
+		return
+	}
+	if err != nil {
+		h.writeCreateOrderErrorResponse(ctx, w, err)
+		return
+	}
+
+	if orderReq.AsOf != nil {
+		now := time.Now()
+		for i := range orders {
+			orders[i].CreatedAt = now
+		}
+	}
+	linesOrder := newLinesOrder(orders)
+	linesOrder.AsOf = orderReq.AsOf
+	linesOrder.sortPacks(packSort)
+
+	jsonBytes, err := json.Marshal(linesOrder)
+	if err != nil {
+		slog.ErrorContext(ctx, "unable to encode the response", "error", err)
+		h.writeInternalServerErrorResponse(w)
+		return
+	}
+
+	h.writeMessageWithStatusResponse(w, jsonBytes, http.StatusOK)
+}
+
+// createLines computes the packs of every line with the products' configs in effect and saves the lines as quotes
+// that reserve their packs until they are confirmed, like createOrder.
+func (h *Handler) createLines(ctx context.Context, lines []LineRequest, opts computeOptions) ([]repository.Order, []LineErrorResponse, error) {
+	for attempt := 1; ; attempt++ {
+		orders, lineErrs, err := h.computeLines(ctx, lines, opts, time.Now())
+		if err != nil {
+			return nil, lineErrs, err
+		}
+
+		for i := range orders {
+			orders[i] = h.newQuote(orders[i])
+		}
+
+		orders, err = h.repository.SaveOrders(ctx, orders)
+		if errors.Is(err, repository.ErrInsufficientStock) && attempt < maxReserveAttempts {
+			continue
+		}
+		return orders, nil, err
+	}
+}
+
+// validateLines sets the default product of the lines and returns the errors of the invalid lines.
+func validateLines(lines []LineRequest) []LineErrorResponse {
+	var lineErrs []LineErrorResponse
+	for i := range lines {
+		if lines[i].Product == "" {
+			lines[i].Product = repository.DefaultProduct
+		}
+		if lines[i].Quantity <= 0 {
+			lineErrs = append(lineErrs, newLineErrorResponse(i, errRespInvalidQuantity))
+		}
+	}
+	return lineErrs
+}
+
+// computeLines computes the packs of every line with the options, the products' configs in effect at the given time and
+// their current stock. The packs of every line are taken from the stock left by the previous lines.
+// It returns errLines with the errors of the lines that cannot be computed, or the first unexpected error (e.g.
+// pack.ErrCancelled).
+func (h *Handler) computeLines(ctx context.Context, lines []LineRequest, opts computeOptions, at time.Time) ([]repository.Order, []LineErrorResponse, error) {
+	products := make([]string, len(lines))
+	for i, line := range lines {
+		products[i] = line.Product
+	}
+
+	cfgs, err := h.repository.FindConfigs(ctx, products, at)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cfgByProduct := make(map[string]repository.Config, len(cfgs))
+	for _, cfg := range cfgs {
+		cfgByProduct[cfg.Product] = cfg
+	}
+
+	levels, err := h.repository.FindStockLevels(ctx, products)
+	if err != nil {
+		return nil, nil, err
+	}
+	stocks := stockByProduct(levels)
+
+	orders := make([]repository.Order, len(lines))
+	var lineErrs []LineErrorResponse
+	for i, line := range lines {
+		cfg, ok := cfgByProduct[line.Product]
+		if !ok {
+			lineErrs = append(lineErrs, newLineErrorResponse(i, errRespNoConfigInEffect))
+			continue
+		}
+
+		packs, err := h.computePacks(ctx, cfg, stocks[line.Product], line.Quantity, opts)
+		if err != nil {
+			errResp, _, ok := createOrderErrorResponse(err)
+			if !ok || errors.Is(err, pack.ErrCancelled) {
+				return nil, nil, err
+			}
+			lineErrs = append(lineErrs, newLineErrorResponse(i, errResp))
+			continue
+		}
+
+		takeFromStock(stocks[line.Product], packs)
+		orders[i] = newRepositoryOrder(line.Quantity, packs, cfg, opts)
+	}
+
+	if len(lineErrs) > 0 {
+		return nil, lineErrs, errLines
+	}
+	return orders, nil, nil
+}
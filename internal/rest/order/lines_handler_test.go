@@ -0,0 +1,224 @@
+package order
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"packer/internal/rest/order/pack"
+	"packer/internal/rest/order/repository"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestPackComputerBySize returns the result or the error of the order size.
+type TestPackComputerBySize struct {
+	results map[int][]pack.Pack
+	errs    map[int]error
+}
+
+func (comp *TestPackComputerBySize) ComputePacksWithObjective(_ context.Context, _ []int, _ map[int]int, orderSize int, _ pack.Objective) ([]pack.Pack, error) {
+	return comp.results[orderSize], comp.errs[orderSize]
+}
+
+func TestServeHTTP_HandleCreateLinesOrder_Success(t *testing.T) {
+	comp := TestPackComputerBySize{
+		results: map[int][]pack.Pack{
+			61: {{Size: 50, Quantity: 1}, {Size: 10, Quantity: 2}},
+			1:  {{Size: 250, Quantity: 1}},
+		},
+	}
+	repo := TestSuccessRepository{
+		configsResult: []repository.Config{
+			{Product: "tea", PackSizes: []int{10, 50}, Version: 1},
+			{Product: repository.DefaultProduct, PackSizes: []int{250}, Version: 3},
+		},
+	}
+	handler := NewHandler(&comp, &repo)
+
+	rr := httptest.NewRecorder()
+	req := newCreateOrderRequestWithPayload(t, `{"lines": [{"product": "tea", "quantity": 61}, {"quantity": 1}]}`)
+
+	handler.ServeHTTP(rr, req)
+
+	assertStatusOk(t, rr)
+	assertBody(t, rr, `{"lines":[`+
+		`{"id":1,"product":"tea","quantity":61,"packs":[{"size":50,"quantity":1},{"size":10,"quantity":2}],`+
+		`"pack_sizes":[10,50],"config_version":1,"status":"quoted","objective":"items_then_packs","solver":"dp","items_shipped":70,"overshoot":9,"pack_count":3},`+
+		`{"id":2,"product":"default","quantity":1,"packs":[{"size":250,"quantity":1}],`+
+		`"pack_sizes":[250],"config_version":3,"status":"quoted","objective":"items_then_packs","solver":"dp","items_shipped":250,"overshoot":249,"pack_count":1}],`+
+		`"totals":{"items_shipped":320,"overshoot":258,"pack_count":4},"created_at":"2024-01-01T00:00:00Z",`+
+		`"expires_at":"2024-01-01T00:15:00Z"}`)
+	if !slices.Equal(repo.passedProducts, []string{"tea", repository.DefaultProduct}) {
+		t.Errorf("unexpected products passed to repository: got '%+v' want '%+v'",
+			repo.passedProducts, []string{"tea", repository.DefaultProduct})
+	}
+	if len(repo.passedOrders) != 2 {
+		t.Errorf("unexpected number of saved orders: got '%d' want '%d'", len(repo.passedOrders), 2)
+	}
+}
+
+func TestServeHTTP_HandleCreateLinesOrder_AsOf(t *testing.T) {
+	comp := TestPackComputerBySize{
+		results: map[int][]pack.Pack{
+			1: {{Size: 250, Quantity: 1}},
+		},
+	}
+	repo := TestSuccessRepository{
+		configsResult: []repository.Config{
+			{Product: repository.DefaultProduct, PackSizes: []int{250}, Version: 3},
+		},
+	}
+	handler := NewHandler(&comp, &repo)
+
+	rr := httptest.NewRecorder()
+	req := newCreateOrderRequestWithPayload(t, `{"lines": [{"quantity": 1}], "as_of": "2030-01-01T00:00:00Z"}`)
+
+	handler.ServeHTTP(rr, req)
+
+	assertStatusOk(t, rr)
+	if repo.passedOrders != nil {
+		t.Errorf("unexpected orders saved: got '%+v' want none", repo.passedOrders)
+	}
+
+	var linesOrder LinesOrder
+	if err := json.NewDecoder(rr.Body).Decode(&linesOrder); err != nil {
+		t.Fatal(err)
+	}
+	asOf := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	if len(linesOrder.Lines) != 1 || linesOrder.Lines[0].ID != 0 || linesOrder.AsOf == nil || !linesOrder.AsOf.Equal(asOf) {
+		t.Errorf("unexpected quote: got '%+v'", linesOrder)
+	}
+}
+
+func TestServeHTTP_HandleCreateLinesOrder_BadRequest(t *testing.T) {
+	data := []struct {
+		payload         string
+		expectedErrCode string
+	}{
+		{
+			payload:         `{"lines": []}`,
+			expectedErrCode: "invalid_line_count",
+		},
+		{
+			payload:         `{"lines": [` + strings.Repeat(`{"quantity": 1},`, maxLines) + `{"quantity": 1}]}`,
+			expectedErrCode: "invalid_line_count",
+		},
+		{
+			payload:         `{"size": 1, "lines": [{"quantity": 1}]}`,
+			expectedErrCode: "invalid_payload",
+		},
+	}
+
+	for _, d := range data {
+		t.Run(fmt.Sprintf("with error code: %s", d.expectedErrCode), func(t *testing.T) {
+			handler := NewHandler(&TestPackComputer{}, &TestSuccessRepository{})
+
+			rr := httptest.NewRecorder()
+			req := newCreateOrderRequestWithPayload(t, d.payload)
+
+			handler.ServeHTTP(rr, req)
+
+			assertErrorResponse(t, rr, http.StatusBadRequest, d.expectedErrCode)
+		})
+	}
+}
+
+func TestServeHTTP_HandleCreateLinesOrder_InvalidLines(t *testing.T) {
+	handler := NewHandler(&TestPackComputer{}, &TestSuccessRepository{})
+
+	rr := httptest.NewRecorder()
+	req := newCreateOrderRequestWithPayload(t, `{"lines": [{"quantity": 0}, {"quantity": 5}, {"quantity": -1}]}`)
+
+	handler.ServeHTTP(rr, req)
+
+	assertLineErrorResponse(t, rr, http.StatusBadRequest, "invalid_lines", []LineErrorResponse{
+		newLineErrorResponse(0, errRespInvalidQuantity),
+		newLineErrorResponse(2, errRespInvalidQuantity),
+	})
+}
+
+func TestServeHTTP_HandleCreateLinesOrder_UnfulfillableLines(t *testing.T) {
+	comp := TestPackComputerBySize{
+		results: map[int][]pack.Pack{
+			1: {{Size: 250, Quantity: 1}},
+		},
+		errs: map[int]error{
+			2: pack.ErrInsufficientStock,
+		},
+	}
+	repo := TestSuccessRepository{
+		configsResult: []repository.Config{
+			{Product: repository.DefaultProduct, PackSizes: []int{250}},
+		},
+	}
+	handler := NewHandler(&comp, &repo)
+
+	rr := httptest.NewRecorder()
+	req := newCreateOrderRequestWithPayload(t,
+		`{"lines": [{"quantity": 1}, {"product": "tea", "quantity": 1}, {"quantity": 2}]}`)
+
+	handler.ServeHTTP(rr, req)
+
+	assertLineErrorResponse(t, rr, http.StatusUnprocessableEntity, "unfulfillable_lines", []LineErrorResponse{
+		newLineErrorResponse(1, errRespNoConfigInEffect),
+		newLineErrorResponse(2, errRespInsufficientStock),
+	})
+	if repo.passedOrders != nil {
+		t.Errorf("unexpected orders saved: got '%+v' want none", repo.passedOrders)
+	}
+}
+
+func TestServeHTTP_HandleCreateLinesOrder_Cancelled(t *testing.T) {
+	comp := TestPackComputerBySize{
+		errs: map[int]error{
+			1: fmt.Errorf("%w: %w", pack.ErrCancelled, context.Canceled),
+		},
+	}
+	repo := TestSuccessRepository{
+		configsResult: []repository.Config{
+			{Product: repository.DefaultProduct, PackSizes: []int{250}},
+		},
+	}
+	handler := NewHandler(&comp, &repo)
+
+	rr := httptest.NewRecorder()
+	req := newCreateOrderRequestWithPayload(t, `{"lines": [{"quantity": 1}]}`)
+
+	handler.ServeHTTP(rr, req)
+
+	assertErrorResponse(t, rr, http.StatusServiceUnavailable, "computation_cancelled")
+}
+
+func TestServeHTTP_HandleCreateLinesOrder_InternalServerError(t *testing.T) {
+	handler := NewHandler(&TestPackComputer{}, &TestErrRepository{})
+
+	rr := httptest.NewRecorder()
+	req := newCreateOrderRequestWithPayload(t, `{"lines": [{"quantity": 1}]}`)
+
+	handler.ServeHTTP(rr, req)
+
+	assertInternalServerErrorResponse(t, rr)
+}
+
+func assertLineErrorResponse(t *testing.T, rr *httptest.ResponseRecorder, expectedStatus int, expectedErrCode string,
+	expectedLines []LineErrorResponse) {
+	if rr.Code != expectedStatus {
+		t.Errorf("unexpected status code: got '%d' want '%d'", rr.Code, expectedStatus)
+	}
+
+	var errResp ErrorResponse
+	if err := json.NewDecoder(rr.Body).Decode(&errResp); err != nil {
+		t.Fatal(err)
+	}
+
+	if errResp.Code != expectedErrCode {
+		t.Errorf("unexpected error code: got '%s' want '%s'", errResp.Code, expectedErrCode)
+	}
+	if !slices.Equal(errResp.Lines, expectedLines) {
+		t.Errorf("unexpected line errors: got '%+v' want '%+v'", errResp.Lines, expectedLines)
+	}
+}
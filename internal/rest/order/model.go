@@ -1,15 +1,379 @@
 package order
 
-import "packer/internal/rest/order/pack"
+import (
+	"packer/internal/rest/order/pack"
+	"packer/internal/rest/order/repository"
+	"time"
+)
 
+// Request is an order of a single product (Product and Size) or of several (Lines).
 type Request struct {
-	Size int `json:"size"`
+	// Product is the SKU of the ordered product. It defaults to repository.DefaultProduct.
+	Product string `json:"product,omitempty"`
+	Size    int    `json:"size,omitempty"`
+	// Lines orders several products at once, instead of Product and Size.
+	Lines []LineRequest `json:"lines,omitempty"`
+	// AsOf computes a what-if quote with the config in effect at that time. The quote is not saved.
+	AsOf *time.Time `json:"as_of,omitempty"`
+	// Objective is the name of the objective the packs are computed by, for every line. It defaults to
+	// pack.ObjectiveItemsThenPacks. The cost objective needs the pack costs of the config.
+	Objective string `json:"objective,omitempty"`
 }
 
+type LineRequest struct {
+	// Product is the SKU of the ordered product. It defaults to repository.DefaultProduct.
+	Product  string `json:"product,omitempty"`
+	Quantity int    `json:"quantity"`
+}
+
+// Order is a saved order, or a what-if quote (see Request.AsOf) without id.
+// Saved orders are quoted until they are confirmed, cancelled or expired (see repository.OrderStatusQuoted).
 type Order struct {
-	Packs []pack.Pack `json:"packs"`
+	ID            int64       `json:"id,omitempty"`
+	Product       string      `json:"product"`
+	Size          int         `json:"size"`
+	Packs         []pack.Pack `json:"packs"`
+	PackSizes     []int       `json:"pack_sizes"`
+	ConfigVersion int64       `json:"config_version"`
+	CreatedAt     time.Time   `json:"created_at"`
+	AsOf          *time.Time  `json:"as_of,omitempty"`
+	Status        string      `json:"status,omitempty"`
+	// ExpiresAt is when a quoted order expires, releasing its reserved packs.
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	Objective string     `json:"objective,omitempty"`
+	// Cost is the cost of the packs plus the handling fee, if the config has pack costs.
+	Cost *int `json:"cost,omitempty"`
+	// Solver is the name of the solver that computed the packs (see Solvers).
+	Solver string `json:"solver,omitempty"`
+	// Explanation is why the packs were chosen, if it was asked for. It is not saved.
+	Explanation *pack.Explanation `json:"explanation,omitempty"`
+	// Ties are the packs that tie with the chosen ones, sorted by the tie-breaker, if they were asked for. The first
+	// ones are the chosen packs. They are not saved.
+	Ties [][]pack.Pack `json:"ties,omitempty"`
+}
+
+// LinesOrder is an order of several lines, each saved as an order of its product (or a what-if quote without ids).
+type LinesOrder struct {
+	Lines     []Line     `json:"lines"`
+	Totals    Totals     `json:"totals"`
+	CreatedAt time.Time  `json:"created_at"`
+	AsOf      *time.Time `json:"as_of,omitempty"`
+	// ExpiresAt is when the quoted lines expire, releasing their reserved packs. Every line is confirmed on its own.
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+type Line struct {
+	ID            int64       `json:"id,omitempty"`
+	Product       string      `json:"product"`
+	Quantity      int         `json:"quantity"`
+	Packs         []pack.Pack `json:"packs"`
+	PackSizes     []int       `json:"pack_sizes"`
+	ConfigVersion int64       `json:"config_version"`
+	Status        string      `json:"status,omitempty"`
+	Objective     string      `json:"objective,omitempty"`
+	Cost          *int        `json:"cost,omitempty"`
+	Solver        string      `json:"solver,omitempty"`
+	Totals
+}
+
+// Totals are the items shipped, the items shipped over the ordered quantity and the number of packs.
+type Totals struct {
+	ItemsShipped int `json:"items_shipped"`
+	Overshoot    int `json:"overshoot"`
+	PackCount    int `json:"pack_count"`
+}
+
+// BatchItem is the quote of an order size of a batch (see Handler.handleQuoteBatch), or its error, by its index in
+// the batch.
+type BatchItem struct {
+	Index         int         `json:"index"`
+	Size          int         `json:"size,omitempty"`
+	Packs         []pack.Pack `json:"packs,omitempty"`
+	ConfigVersion int64       `json:"config_version,omitempty"`
+	Solver        string      `json:"solver,omitempty"`
+	*Totals
+	Code    string `json:"error_code,omitempty"`
+	Message string `json:"error_message,omitempty"`
+}
+
+// OrderPage is a page of orders. NextCursor is empty on the last page.
+type OrderPage struct {
+	Orders     []Order `json:"orders"`
+	NextCursor string  `json:"next_cursor,omitempty"`
 }
 
 type Config struct {
 	PackSizes []int `json:"pack_sizes"`
+	// PackCosts are the unit costs of the pack sizes, by pack size, for the cost objective. Every pack size must have
+	// one, or none of them.
+	PackCosts map[int]int `json:"pack_costs,omitempty"`
+	// HandlingFee is added once to the cost of every order.
+	HandlingFee int `json:"handling_fee,omitempty"`
+	// EffectiveFrom schedules the config to take effect in the future. It takes effect immediately if nil.
+	EffectiveFrom *time.Time `json:"effective_from,omitempty"`
+	// Author and Reason are kept in the config history.
+	Author string `json:"author,omitempty"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// StockLevel is the quantity in stock of a pack size of a product.
+type StockLevel struct {
+	Product  string `json:"product"`
+	PackSize int    `json:"pack_size"`
+	Quantity int    `json:"quantity"`
+	// Reserved is the quantity reserved by quoted orders, which is not available to other orders.
+	Reserved  int       `json:"reserved"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Inventory is the stock of the products' pack sizes. Pack sizes without a stock level are unlimited.
+type Inventory struct {
+	Stock []StockLevel `json:"stock"`
+}
+
+// SetStockRequest is the payload to set the stock levels of a product's pack sizes.
+type SetStockRequest struct {
+	Stock []StockLevelRequest `json:"stock"`
+}
+
+type StockLevelRequest struct {
+	PackSize int `json:"pack_size"`
+	Quantity int `json:"quantity"`
+}
+
+// CreateProductRequest is the payload to create a product with its first config.
+type CreateProductRequest struct {
+	SKU       string `json:"sku"`
+	Name      string `json:"name"`
+	PackSizes []int  `json:"pack_sizes"`
+	// Author and Reason are kept in the config history.
+	Author string `json:"author,omitempty"`
+	Reason string `json:"reason,omitempty"`
+}
+
+type UpdateProductRequest struct {
+	Name string `json:"name"`
+}
+
+type Product struct {
+	SKU       string    `json:"sku"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type Products struct {
+	Products []Product `json:"products"`
+}
+
+// RestoreConfigRequest is the optional payload to restore a config version.
+type RestoreConfigRequest struct {
+	EffectiveFrom *time.Time `json:"effective_from"`
+	Author        string     `json:"author"`
+	Reason        string     `json:"reason"`
+}
+
+// ConfigVersion is an immutable version in the config history.
+type ConfigVersion struct {
+	Version       int64       `json:"version"`
+	PackSizes     []int       `json:"pack_sizes"`
+	PackCosts     map[int]int `json:"pack_costs,omitempty"`
+	HandlingFee   int         `json:"handling_fee,omitempty"`
+	CreatedAt     time.Time   `json:"created_at"`
+	EffectiveFrom time.Time   `json:"effective_from"`
+	Author        string      `json:"author"`
+	Reason        string      `json:"reason"`
+}
+
+type ConfigVersions struct {
+	Versions []ConfigVersion `json:"versions"`
+}
+
+// VersionedConfig is the config with the version and the timestamp of its last change.
+type VersionedConfig struct {
+	PackSizes     []int       `json:"pack_sizes"`
+	PackCosts     map[int]int `json:"pack_costs,omitempty"`
+	HandlingFee   int         `json:"handling_fee,omitempty"`
+	Version       int64       `json:"version"`
+	LastModified  time.Time   `json:"last_modified"`
+	EffectiveFrom time.Time   `json:"effective_from"`
+}
+
+// CacheStats are the statistics of the cached configs and solution tables (see Cache).
+type CacheStats struct {
+	Configs CacheCounters   `json:"configs"`
+	Tables  TableCacheStats `json:"tables"`
+}
+
+// CacheCounters are the entries of a cache and how many lookups found (hits) or did not find (misses) their entry.
+type CacheCounters struct {
+	Entries  int     `json:"entries"`
+	Hits     int64   `json:"hits"`
+	Misses   int64   `json:"misses"`
+	HitRatio float64 `json:"hit_ratio"`
+}
+
+// TableCacheStats are the counters of the solution tables with the memory they use, its limit and the number of tables
+// evicted to stay within it.
+type TableCacheStats struct {
+	CacheCounters
+	Bytes     int   `json:"bytes"`
+	MaxBytes  int   `json:"max_bytes"`
+	Evictions int64 `json:"evictions"`
+}
+
+func newVersionedConfig(cfg repository.Config) VersionedConfig {
+	return VersionedConfig{
+		PackSizes:     cfg.PackSizes,
+		PackCosts:     cfg.PackCosts,
+		HandlingFee:   cfg.HandlingFee,
+		Version:       cfg.Version,
+		LastModified:  cfg.UpdatedAt,
+		EffectiveFrom: cfg.EffectiveFrom,
+	}
+}
+
+func newConfigVersions(cfgs []repository.Config) ConfigVersions {
+	versions := make([]ConfigVersion, len(cfgs))
+	for i, cfg := range cfgs {
+		versions[i] = ConfigVersion{
+			Version:       cfg.Version,
+			PackSizes:     cfg.PackSizes,
+			PackCosts:     cfg.PackCosts,
+			HandlingFee:   cfg.HandlingFee,
+			CreatedAt:     cfg.UpdatedAt,
+			EffectiveFrom: cfg.EffectiveFrom,
+			Author:        cfg.Author,
+			Reason:        cfg.Reason,
+		}
+	}
+	return ConfigVersions{Versions: versions}
+}
+
+func newCacheCounters(entries int, hits, misses int64) CacheCounters {
+	counters := CacheCounters{Entries: entries, Hits: hits, Misses: misses}
+	if hits+misses > 0 {
+		counters.HitRatio = float64(hits) / float64(hits+misses)
+	}
+	return counters
+}
+
+func newProduct(product repository.Product) Product {
+	return Product{
+		SKU:       product.SKU,
+		Name:      product.Name,
+		CreatedAt: product.CreatedAt,
+	}
+}
+
+func newProducts(products []repository.Product) Products {
+	result := make([]Product, len(products))
+	for i, product := range products {
+		result[i] = newProduct(product)
+	}
+	return Products{Products: result}
+}
+
+func newInventory(levels []repository.StockLevel) Inventory {
+	stock := make([]StockLevel, len(levels))
+	for i, level := range levels {
+		stock[i] = StockLevel{
+			Product:   level.Product,
+			PackSize:  level.PackSize,
+			Quantity:  level.Quantity,
+			Reserved:  level.Reserved,
+			UpdatedAt: level.UpdatedAt,
+		}
+	}
+	return Inventory{Stock: stock}
+}
+
+func newOrder(order repository.Order) Order {
+	packs := make([]pack.Pack, len(order.Packs))
+	for i, p := range order.Packs {
+		packs[i] = pack.Pack{Size: p.Size, Quantity: p.Quantity}
+	}
+
+	return Order{
+		ID:            order.ID,
+		Product:       order.Product,
+		Size:          order.Size,
+		Packs:         packs,
+		PackSizes:     order.PackSizes,
+		ConfigVersion: order.ConfigVersion,
+		CreatedAt:     order.CreatedAt,
+		Status:        order.Status,
+		ExpiresAt:     order.ExpiresAt,
+		Objective:     order.Objective,
+		Cost:          order.Cost,
+		Solver:        order.Solver,
+	}
+}
+
+func newLinesOrder(orders []repository.Order) LinesOrder {
+	var linesOrder LinesOrder
+	linesOrder.Lines = make([]Line, len(orders))
+	for i, order := range orders {
+		o := newOrder(order)
+		linesOrder.Lines[i] = Line{
+			ID:            o.ID,
+			Product:       o.Product,
+			Quantity:      o.Size,
+			Packs:         o.Packs,
+			PackSizes:     o.PackSizes,
+			ConfigVersion: o.ConfigVersion,
+			Status:        o.Status,
+			Objective:     o.Objective,
+			Cost:          o.Cost,
+			Solver:        o.Solver,
+			Totals:        newTotals(o.Size, o.Packs),
+		}
+
+		linesOrder.Totals.ItemsShipped += linesOrder.Lines[i].ItemsShipped
+		linesOrder.Totals.Overshoot += linesOrder.Lines[i].Overshoot
+		linesOrder.Totals.PackCount += linesOrder.Lines[i].PackCount
+		linesOrder.CreatedAt = order.CreatedAt
+		linesOrder.ExpiresAt = order.ExpiresAt
+	}
+	return linesOrder
+}
+
+func newTotals(quantity int, packs []pack.Pack) Totals {
+	var totals Totals
+	for _, p := range packs {
+		totals.ItemsShipped += p.Size * p.Quantity
+		totals.PackCount += p.Quantity
+	}
+	totals.Overshoot = totals.ItemsShipped - quantity
+	return totals
+}
+
+// newRepositoryOrder returns the order of the packs computed with the options. It has a cost if the config has pack
+// costs.
+func newRepositoryOrder(size int, packs []pack.Pack, cfg repository.Config, opts computeOptions) repository.Order {
+	repoPacks := make([]repository.Pack, len(packs))
+	for i, p := range packs {
+		repoPacks[i] = repository.Pack{Size: p.Size, Quantity: p.Quantity}
+	}
+
+	var cost *int
+	if cfg.PackCosts != nil {
+		total := configCost(cfg).Total(packs)
+		cost = &total
+	}
+
+	return repository.Order{
+		Product:       cfg.Product,
+		Size:          size,
+		Packs:         repoPacks,
+		PackSizes:     cfg.PackSizes,
+		ConfigVersion: cfg.Version,
+		Objective:     opts.objective,
+		Cost:          cost,
+		Solver:        opts.solver,
+	}
+}
+
+// configCost returns the cost objective with the pack costs and the handling fee of the config.
+func configCost(cfg repository.Config) pack.Cost {
+	return pack.Cost{UnitCosts: cfg.PackCosts, HandlingFee: cfg.HandlingFee}
 }
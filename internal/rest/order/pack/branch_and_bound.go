@@ -0,0 +1,177 @@
+package pack
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+)
+
+// DefaultMaxNodes is the default maximum number of partial combinations of packs a branch and bound search visits.
+const DefaultMaxNodes = 10_000_000
+
+// BranchAndBound computes the best packs of an order by the objective with a depth-first search over the quantity of
+// every pack size, largest first, that skips the quantities that cannot lead to better packs than the best found so
+// far.
+// It needs no memory besides the search path, so it solves orders that are too large for the Computer's table, but its
+// time depends on how quickly the best packs are found and may exceed its maximum number of visited nodes.
+type BranchAndBound struct {
+	maxNodes int
+}
+
+func NewBranchAndBound() BranchAndBound {
+	return NewBranchAndBoundWithMaxNodes(DefaultMaxNodes)
+}
+
+// NewBranchAndBoundWithMaxNodes creates a solver that returns ErrInputTooLarge instead of visiting more than maxNodes
+// partial combinations of packs.
+func NewBranchAndBoundWithMaxNodes(maxNodes int) BranchAndBound {
+	return BranchAndBound{maxNodes: maxNodes}
+}
+
+// branchAndBoundSearch is the state of a search for the packs of an order.
+type branchAndBoundSearch struct {
+	ctx        context.Context
+	packSizes  []int
+	bounds     []int
+	weights    []int
+	orderSize  int
+	objective  Objective
+	quantities []int
+	best       []int
+	bestFound  bool
+	bestValue  Candidate
+	nodes      int
+	maxNodes   int
+	// minWeightPerItem[i] is the index of the pack size from i on with the smallest weight per item.
+	minWeightPerItem []int
+}
+
+// ComputePacksWithObjective computes the best packs by the objective, with at most stock[size] packs of every pack size
+// in stock. Pack sizes missing from the stock are unlimited.
+// A combination that fulfills the order and still would without one of its packs is never better than without it, so
+// at most enough packs of every pack size to fulfill the rest of the order are searched.
+// It returns ErrInvalidInput, ErrInsufficientStock, ErrInputTooLarge or ErrCancelled if the packs cannot be computed.
+// ErrInputTooLarge is returned when the search visits more than the maximum number of nodes, or the weights of the
+// packs could overflow.
+func (bb *BranchAndBound) ComputePacksWithObjective(ctx context.Context, packSizes []int, stock map[int]int, orderSize int, objective Objective) ([]Pack, error) {
+	if !SizesValid(packSizes) || orderSize <= 0 || !StockValid(stock) {
+		return nil, ErrInvalidInput
+	}
+
+	sortedPackSizes := slices.Clone(packSizes)
+	slices.Sort(sortedPackSizes)
+	sortedPackSizes = slices.Compact(sortedPackSizes)
+	slices.Reverse(sortedPackSizes)
+
+	s := branchAndBoundSearch{
+		ctx:              ctx,
+		packSizes:        sortedPackSizes,
+		bounds:           make([]int, len(sortedPackSizes)),
+		weights:          make([]int, len(sortedPackSizes)),
+		orderSize:        orderSize,
+		objective:        objective,
+		quantities:       make([]int, len(sortedPackSizes)),
+		best:             make([]int, len(sortedPackSizes)),
+		maxNodes:         bb.maxNodes,
+		minWeightPerItem: make([]int, len(sortedPackSizes)),
+	}
+	maxWeight := 0
+	for i, packSize := range sortedPackSizes {
+		s.bounds[i] = math.MaxInt
+		if quantity, limited := stock[packSize]; limited {
+			s.bounds[i] = quantity
+		}
+		s.weights[i] = objective.Weight(packSize)
+		maxWeight = max(maxWeight, s.weights[i])
+	}
+	// the searched combinations have at most orderSize+1 packs, so their weights cannot overflow
+	if maxWeight > 0 && orderSize > math.MaxInt/4/maxWeight {
+		return nil, ErrInputTooLarge
+	}
+	for i := len(sortedPackSizes) - 1; i >= 0; i-- {
+		s.minWeightPerItem[i] = i
+		if i+1 == len(sortedPackSizes) {
+			continue
+		}
+		// weights[j]/packSizes[j] < weights[i]/packSizes[i], without rounding
+		if j := s.minWeightPerItem[i+1]; s.weights[j]*sortedPackSizes[i] < s.weights[i]*sortedPackSizes[j] {
+			s.minWeightPerItem[i] = j
+		}
+	}
+
+	if err := s.search(0, Candidate{}); err != nil {
+		return nil, err
+	}
+	if !s.bestFound {
+		return nil, ErrInsufficientStock
+	}
+
+	var result []Pack
+	for i, packSize := range sortedPackSizes {
+		if s.best[i] > 0 {
+			result = append(result, Pack{Size: packSize, Quantity: s.best[i]})
+		}
+	}
+	return result, nil
+}
+
+// search tries every quantity of the pack size i, most first, after the quantities of the larger pack sizes that add
+// up to the partial candidate.
+func (s *branchAndBoundSearch) search(i int, partial Candidate) error {
+	if partial.Items >= s.orderSize {
+		if !s.bestFound || s.objective.Less(partial, s.bestValue) {
+			s.bestFound, s.bestValue = true, partial
+			copy(s.best, s.quantities)
+		}
+		return nil
+	}
+	if i == len(s.packSizes) {
+		return nil
+	}
+
+	// checked on the first node too, so that an already cancelled context never searches
+	if s.nodes%cancelCheckInterval == 0 && s.ctx.Err() != nil {
+		return fmt.Errorf("%w: %w", ErrCancelled, s.ctx.Err())
+	}
+	s.nodes++
+	if s.nodes > s.maxNodes {
+		return ErrInputTooLarge
+	}
+
+	if s.bestFound && !s.objective.Less(s.lowerBound(i, partial), s.bestValue) {
+		return nil
+	}
+
+	packSize, weight := s.packSizes[i], s.weights[i]
+	remaining := s.orderSize - partial.Items
+	maxQuantity := min((remaining-1)/packSize+1, s.bounds[i])
+	for quantity := maxQuantity; quantity >= 0; quantity-- {
+		s.quantities[i] = quantity
+		err := s.search(i+1, Candidate{
+			Items:  partial.Items + quantity*packSize,
+			Packs:  partial.Packs + quantity,
+			Weight: partial.Weight + quantity*weight,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	s.quantities[i] = 0
+	return nil
+}
+
+// lowerBound returns a candidate with no more items, packs or weight than any that fulfills the order from the partial
+// candidate with the pack sizes from i on, regardless of their stock.
+// The objectives rank a candidate with no more items, packs and weight than another one as no worse, so the search
+// can skip the partial candidate if its lower bound is not better than the best candidate found.
+func (s *branchAndBoundSearch) lowerBound(i int, partial Candidate) Candidate {
+	remaining := s.orderSize - partial.Items
+	cheapest := s.minWeightPerItem[i]
+	return Candidate{
+		Items: s.orderSize,
+		Packs: partial.Packs + (remaining-1)/s.packSizes[i] + 1,
+		// the remaining items weigh at least as much as with the lightest pack size per item
+		Weight: partial.Weight + (remaining*s.weights[cheapest]+s.packSizes[cheapest]-1)/s.packSizes[cheapest],
+	}
+}
@@ -0,0 +1,139 @@
+package pack
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+func TestBranchAndBound_Compute_Success(t *testing.T) {
+	data := []struct {
+		packSizes     []int
+		stock         map[int]int
+		orderSize     int
+		objective     Objective
+		expectedPacks []Pack
+	}{
+		{
+			packSizes:     []int{250, 500, 1000, 2000, 5000},
+			orderSize:     12001,
+			objective:     ItemsThenPacks{},
+			expectedPacks: []Pack{{Size: 5000, Quantity: 2}, {Size: 2000, Quantity: 1}, {Size: 250, Quantity: 1}},
+		},
+		{
+			packSizes:     []int{250, 500, 1000, 2000, 5000},
+			stock:         map[int]int{5000: 1},
+			orderSize:     12001,
+			objective:     ItemsThenPacks{},
+			expectedPacks: []Pack{{Size: 5000, Quantity: 1}, {Size: 2000, Quantity: 3}, {Size: 1000, Quantity: 1}, {Size: 250, Quantity: 1}},
+		},
+		{
+			packSizes:     []int{23, 31, 53},
+			orderSize:     500000,
+			objective:     ItemsThenPacks{},
+			expectedPacks: []Pack{{Size: 53, Quantity: 9429}, {Size: 31, Quantity: 7}, {Size: 23, Quantity: 2}},
+		},
+		{
+			packSizes: []int{250, 500, 1000, 2000, 5000},
+			orderSize: 12001,
+			objective: Cost{
+				UnitCosts: map[int]int{250: 300, 500: 150, 1000: 250, 2000: 450, 5000: 1000},
+			},
+			expectedPacks: []Pack{{Size: 5000, Quantity: 2}, {Size: 2000, Quantity: 1}, {Size: 500, Quantity: 1}},
+		},
+	}
+
+	for _, d := range data {
+		t.Run(fmt.Sprintf("with pack sizes: %v, stock: %v and order size: %d", d.packSizes, d.stock, d.orderSize), func(t *testing.T) {
+			bb := NewBranchAndBound()
+
+			packs, err := bb.ComputePacksWithObjective(context.Background(), d.packSizes, d.stock, d.orderSize, d.objective)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !slices.Equal(packs, d.expectedPacks) {
+				t.Errorf("unexpected packs: got '%+v' want '%+v'", packs, d.expectedPacks)
+			}
+		})
+	}
+}
+
+func TestBranchAndBound_Compute_MatchesBruteForceOracle(t *testing.T) {
+	rnd := rand.New(rand.NewSource(7))
+
+	for i := 0; i < 300; i++ {
+		packSizes := randomPackSizes(rnd, 4, 60)
+		stock := make(map[int]int)
+		unitCosts := make(map[int]int)
+		for _, packSize := range packSizes {
+			if rnd.Intn(3) == 0 {
+				stock[packSize] = rnd.Intn(6)
+			}
+			unitCosts[packSize] = rnd.Intn(20)
+		}
+		objective := []Objective{ItemsThenPacks{}, PacksThenItems{}, Cost{UnitCosts: unitCosts}}[i%3]
+		orderSize := rnd.Intn(300) + 1
+
+		t.Run(fmt.Sprintf("with pack sizes: %v, stock: %v, objective: %+v and order size: %d", packSizes, stock,
+			objective, orderSize), func(t *testing.T) {
+			bb := NewBranchAndBound()
+
+			packs, err := bb.ComputePacksWithObjective(context.Background(), packSizes, stock, orderSize, objective)
+
+			expected, ok := bruteForceObjectiveCandidate(packSizes, stock, orderSize, objective)
+			if !ok {
+				if !errors.Is(err, ErrInsufficientStock) {
+					t.Errorf("unexpected error: got '%v' want '%v'", err, ErrInsufficientStock)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assertPacksUseSizes(t, packs, packSizes)
+			assertPacksInStock(t, packs, stock)
+			items, numPacks := countItemsAndPacks(packs)
+			candidate := Candidate{Items: items, Packs: numPacks, Weight: weightOf(packs, objective)}
+			if candidate != expected {
+				t.Errorf("unexpected packs: got '%+v' want '%+v'", candidate, expected)
+			}
+		})
+	}
+}
+
+func TestBranchAndBound_Compute_InvalidInput(t *testing.T) {
+	bb := NewBranchAndBound()
+
+	_, err := bb.ComputePacksWithObjective(context.Background(), []int{250, -1}, nil, 1, ItemsThenPacks{})
+
+	if !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("unexpected error: got '%v' want '%v'", err, ErrInvalidInput)
+	}
+}
+
+func TestBranchAndBound_Compute_InputTooLarge(t *testing.T) {
+	bb := NewBranchAndBoundWithMaxNodes(100)
+
+	_, err := bb.ComputePacksWithObjective(context.Background(), []int{23, 31, 53}, nil, 500000, ItemsThenPacks{})
+
+	if !errors.Is(err, ErrInputTooLarge) {
+		t.Errorf("unexpected error: got '%v' want '%v'", err, ErrInputTooLarge)
+	}
+}
+
+func TestBranchAndBound_Compute_Cancelled(t *testing.T) {
+	bb := NewBranchAndBound()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := bb.ComputePacksWithObjective(ctx, []int{23, 31, 53}, nil, 500000, ItemsThenPacks{})
+
+	if !errors.Is(err, ErrCancelled) {
+		t.Errorf("unexpected error: got '%v' want '%v'", err, ErrCancelled)
+	}
+}
@@ -1,27 +1,51 @@
 package pack
 
 import (
-	"maps"
+	"context"
+	"fmt"
+	"math"
 	"slices"
 )
 
-type Computer struct{}
+const (
+	// DefaultMaxTableSize is the default maximum number of totals a computer keeps in memory (around 160MB).
+	DefaultMaxTableSize = 10_000_000
+
+	// cancelCheckInterval is the number of totals computed between checks of the context.
+	cancelCheckInterval = 1 << 16
+
+	// minPacksTableRows is the number of ints kept for every total by computeMinPacksTable, whose totals the maximum
+	// table size counts.
+	minPacksTableRows = 2
+)
+
+type Computer struct {
+	maxTableSize int
+}
 
 func NewComputer() Computer {
-	return Computer{}
+	return NewComputerWithMaxTableSize(DefaultMaxTableSize)
+}
+
+// NewComputerWithMaxTableSize creates a computer that returns ErrInputTooLarge instead of keeping more than
+// maxTableSize totals in memory.
+func NewComputerWithMaxTableSize(maxTableSize int) Computer {
+	return Computer{maxTableSize: maxTableSize}
 }
 
 // ComputePacks the packs that need to be shipped to the customer given the pack sizes and the order size.
-func (comp *Computer) ComputePacks(packSizes []int, orderSize int) []Pack {
+// The memory used depends on the pack sizes and not on the order size, see computeQuantityByPack.
+// It returns ErrInvalidInput, ErrInputTooLarge or ErrCancelled if the packs cannot be computed.
+func (comp *Computer) ComputePacks(ctx context.Context, packSizes []int, orderSize int) ([]Pack, error) {
 	if !comp.isValidInput(packSizes, orderSize) {
-		return []Pack{}
+		return nil, ErrInvalidInput
 	}
 	sortedPackSizes := comp.cloneAndSort(packSizes)
-	return comp.compute(sortedPackSizes, orderSize)
+	return comp.compute(ctx, sortedPackSizes, orderSize)
 }
 
 func (comp *Computer) isValidInput(packSizes []int, orderSize int) bool {
-	return len(packSizes) > 0 && orderSize > 0
+	return SizesValid(packSizes) && orderSize > 0
 }
 
 func (comp *Computer) cloneAndSort(s []int) []int {
@@ -30,117 +54,188 @@ func (comp *Computer) cloneAndSort(s []int) []int {
 	return cloned
 }
 
-func (comp *Computer) compute(packSizes []int, orderSize int) []Pack {
-	rawPacks := comp.computeRawPacks(packSizes, orderSize)
-	optimizedPacks := comp.optimizePacks(packSizes, rawPacks)
-	return comp.toPackModelSlice(optimizedPacks)
+func (comp *Computer) compute(ctx context.Context, packSizes []int, orderSize int) ([]Pack, error) {
+	quantityByPack, err := comp.computeQuantityByPack(ctx, packSizes, orderSize)
+	if err != nil {
+		return nil, err
+	}
+	return comp.toPackModelSlice(quantityByPack), nil
 }
 
-// computeRawPacks computes a slice with all the packs that can fulfill the order size.
-// Some packs will be unoptimized
-// (e.g. for pack sizes of 250 and 500 an order size of 251 it returns 2 packs of 250, instead of 1 pack of 500).
-// See optimizePacks to optimize these packs.
-func (comp *Computer) computeRawPacks(packSizes []int, orderSize int) []int {
-	numPacks := make([]int, orderSize+1)
-	usedPackSizes := make([]int, orderSize+1)
-
-	placeholder := orderSize + 1
-	numPacks = comp.fillWithPlaceholderFromIndex(numPacks, placeholder, 1)
-
-	smallestPackSize := packSizes[0]
-	usedPackSizes = comp.fillWithPlaceholderFromIndex(usedPackSizes, smallestPackSize, 1)
+// computeQuantityByPack computes the quantity of each pack size that fulfills the order size sending out as few items
+// as possible and, within those, as few packs as possible.
+// The pack sizes and the order size are first divided by the pack sizes' greatest common divisor, since every total
+// that can be reached is a multiple of it, and then solved by computeReducedQuantityByPack.
+func (comp *Computer) computeQuantityByPack(ctx context.Context, packSizes []int, orderSize int) (map[int]int, error) {
+	divisor := comp.gcd(packSizes)
+	reducedPackSizes := comp.divideSizes(packSizes, divisor)
+	reducedOrderSize := (orderSize-1)/divisor + 1
+
+	reducedQuantityByPack, err := comp.computeReducedQuantityByPack(ctx, reducedPackSizes, reducedOrderSize)
+	if err != nil {
+		return nil, err
+	}
 
-	for targetOrderSize := 1; targetOrderSize < len(numPacks); targetOrderSize++ {
-		for _, packSize := range packSizes {
-			if targetOrderSize-packSize < 0 {
-				break
-			}
+	result := make(map[int]int, len(reducedQuantityByPack))
+	for packSize, quantity := range reducedQuantityByPack {
+		result[packSize*divisor] = quantity
+	}
+	return result, nil
+}
 
-			if numPacks[targetOrderSize-packSize]+1 < numPacks[targetOrderSize] {
-				numPacks[targetOrderSize] = numPacks[targetOrderSize-packSize] + 1
-				usedPackSizes[targetOrderSize] = packSize
-			}
+func (comp *Computer) gcd(packSizes []int) int {
+	result := packSizes[0]
+	for _, packSize := range packSizes[1:] {
+		for packSize != 0 {
+			result, packSize = packSize, result%packSize
 		}
 	}
-
-	return comp.computePacksByUsedPackSizes(usedPackSizes)
+	return result
 }
 
-func (comp *Computer) fillWithPlaceholderFromIndex(numPacks []int, placeholder, index int) []int {
-	for i := index; i < len(numPacks); i++ {
-		numPacks[i] = placeholder
+func (comp *Computer) divideSizes(packSizes []int, divisor int) []int {
+	result := make([]int, len(packSizes))
+	for i, packSize := range packSizes {
+		result[i] = packSize / divisor
 	}
-	return numPacks
+	return result
 }
 
-func (comp *Computer) computePacksByUsedPackSizes(usedPackSizes []int) []int {
-	var result []int
-	targetOrderSize := len(usedPackSizes) - 1
-	for targetOrderSize > 0 {
-		result = append(result, usedPackSizes[targetOrderSize])
-		targetOrderSize -= usedPackSizes[targetOrderSize]
+// computeReducedQuantityByPack solves pack sizes whose greatest common divisor is 1.
+// Orders smaller than the bound returned by tableBound are solved directly (see computeDirectQuantityByPack), larger
+// ones split into a remainder solved over a table of that bound plus a bulk of the largest packs
+// (see computeBoundedQuantityByPack).
+func (comp *Computer) computeReducedQuantityByPack(ctx context.Context, packSizes []int, orderSize int) (map[int]int, error) {
+	largestPackSize := packSizes[len(packSizes)-1]
+	if orderSize <= comp.tableBound(largestPackSize)-largestPackSize {
+		return comp.computeDirectQuantityByPack(ctx, packSizes, orderSize)
 	}
-	return result
+	return comp.computeBoundedQuantityByPack(ctx, packSizes, orderSize)
 }
 
-// optimizePacks merges smaller packs into larger ones (e.g. 2 packs of 250 will be merged into one of 500, if it exists).
-func (comp *Computer) optimizePacks(packSizes, packs []int) []int {
-	quantityByPack := comp.packSliceToQuantityByPackMap(packs)
-	mergedQuantityByPack := comp.mergeToLargerPacks(packSizes, quantityByPack)
-	return comp.quantityByPackMapToPackSlice(mergedQuantityByPack)
+// tableBound returns the size of the table used by computeBoundedQuantityByPack, i.e. the square of the largest pack
+// size (or the largest int if it overflows).
+func (comp *Computer) tableBound(largestPackSize int) int {
+	if largestPackSize > math.MaxInt/largestPackSize {
+		return math.MaxInt
+	}
+	return largestPackSize * largestPackSize
 }
 
-func (comp *Computer) packSliceToQuantityByPackMap(packs []int) map[int]int {
-	result := make(map[int]int)
-	for _, pack := range packs {
-		result[pack]++
+// computeDirectQuantityByPack searches every total up to the order size.
+// A solution with orderSize+largestPackSize items or more can always drop one of its packs and still fulfill the
+// order, so only the totals in [orderSize, orderSize+largestPackSize) need to be searched.
+func (comp *Computer) computeDirectQuantityByPack(ctx context.Context, packSizes []int, orderSize int) (map[int]int, error) {
+	largestPackSize := packSizes[len(packSizes)-1]
+	numPacks, usedPackSizes, err := comp.computeMinPacksTable(ctx, packSizes, orderSize+largestPackSize)
+	if err != nil {
+		return nil, err
 	}
-	return result
+
+	total := comp.findSmallestReachableTotal(numPacks, orderSize)
+	return comp.computeQuantityByUsedPackSizes(usedPackSizes, total), nil
 }
 
-func (comp *Computer) mergeToLargerPacks(packSizes []int, quantityByPack map[int]int) map[int]int {
-	for i := len(packSizes) - 1; i > 0; i-- {
-		quantityByPack = comp.mergeToPackSize(packSizes[i], quantityByPack)
+// computeBoundedQuantityByPack solves orders greater than or equal to L*(L-1), being L the largest pack size, using a
+// table of L*L totals.
+//
+// With a greatest common divisor of 1 every total greater than (smallestPackSize-1)*(L-1)-1 can be reached (Schur's
+// bound), so the order size itself is the fewest items that can be sent.
+// An optimal solution never has L or more packs smaller than L: some of them would add up to a multiple of L and could
+// be replaced by fewer packs of L. Its remainder (the items not in packs of L) is therefore below L*(L-1).
+// Adding a pack of L to a remainder never costs more than one pack, so the largest remainder in the table with the
+// same residue modulo L as the order size is as good as the optimal one.
+func (comp *Computer) computeBoundedQuantityByPack(ctx context.Context, packSizes []int, orderSize int) (map[int]int, error) {
+	largestPackSize := packSizes[len(packSizes)-1]
+	bound := comp.tableBound(largestPackSize)
+	_, usedPackSizes, err := comp.computeMinPacksTable(ctx, packSizes, bound)
+	if err != nil {
+		return nil, err
 	}
-	return quantityByPack
+
+	remainder := bound - largestPackSize + orderSize%largestPackSize
+	result := comp.computeQuantityByUsedPackSizes(usedPackSizes, remainder)
+	result[largestPackSize] += (orderSize - remainder) / largestPackSize
+	return result, nil
 }
 
-func (comp *Computer) mergeToPackSize(packSize int, quantityByPack map[int]int) map[int]int {
-	result := maps.Clone(quantityByPack)
-	for pack, quantity := range quantityByPack {
-		sum := 0
-		count := 0
-		for i := 0; i < quantity; i++ {
-			sum += pack
-			count++
-			if sum == packSize {
-				result[pack] -= count
-				result[packSize]++
-				sum = 0
-				count = 0
+// computeMinPacksTable computes, for every total in [0, size), the minimum number of packs that add up to exactly
+// that total (numPacks) and the last pack size used to reach it (usedPackSizes).
+// Unreachable totals have a number of packs greater than any reachable one.
+// It returns ErrInputTooLarge if size is greater than the computer's maximum table size, and ErrCancelled if the
+// context is done before the table is computed.
+func (comp *Computer) computeMinPacksTable(ctx context.Context, packSizes []int, size int) ([]int, []int, error) {
+	if size > comp.maxTableSize {
+		return nil, nil, ErrInputTooLarge
+	}
+
+	numPacks := make([]int, size)
+	usedPackSizes := make([]int, size)
+
+	placeholder := size
+	numPacks = comp.fillWithPlaceholderFromIndex(numPacks, placeholder, 1)
+
+	for total := 1; total < size; total++ {
+		// checked on the first total too, so that an already cancelled context never computes the table
+		if total%cancelCheckInterval == 1 && ctx.Err() != nil {
+			return nil, nil, fmt.Errorf("%w: %w", ErrCancelled, ctx.Err())
+		}
+
+		for _, packSize := range packSizes {
+			if total-packSize < 0 {
+				break
+			}
+
+			if numPacks[total-packSize]+1 < numPacks[total] {
+				numPacks[total] = numPacks[total-packSize] + 1
+				usedPackSizes[total] = packSize
 			}
 		}
 	}
-	return result
+
+	return numPacks, usedPackSizes, nil
 }
 
-func (comp *Computer) quantityByPackMapToPackSlice(quantityByPack map[int]int) []int {
-	var result []int
-	for pack, quantity := range quantityByPack {
-		for i := 0; i < quantity; i++ {
-			result = append(result, pack)
-		}
+// tableFits returns true if a table of size totals, keeping rows ints for every total, uses no more memory than the
+// computer's maximum table size of computeMinPacksTable's totals.
+func (comp *Computer) tableFits(size, rows int) bool {
+	return size <= comp.maxTableSize/rows*minPacksTableRows
+}
+
+func (comp *Computer) fillWithPlaceholderFromIndex(numPacks []int, placeholder, index int) []int {
+	for i := index; i < len(numPacks); i++ {
+		numPacks[i] = placeholder
+	}
+	return numPacks
+}
+
+// findSmallestReachableTotal returns the smallest total, greater than or equal to the order size, that can be
+// reached with whole packs.
+// A reachable total always exists in the table (e.g. the smallest multiple of the smallest pack size).
+func (comp *Computer) findSmallestReachableTotal(numPacks []int, orderSize int) int {
+	placeholder := len(numPacks)
+	total := orderSize
+	for numPacks[total] >= placeholder {
+		total++
+	}
+	return total
+}
+
+func (comp *Computer) computeQuantityByUsedPackSizes(usedPackSizes []int, total int) map[int]int {
+	result := make(map[int]int)
+	for total > 0 {
+		result[usedPackSizes[total]]++
+		total -= usedPackSizes[total]
 	}
 	return result
 }
 
-func (comp *Computer) toPackModelSlice(packs []int) []Pack {
-	if len(packs) == 0 {
+// toPackModelSlice returns the packs of the quantities sorted by DefaultSort, since maps are iterated in random order.
+func (comp *Computer) toPackModelSlice(quantityByPack map[int]int) []Pack {
+	if len(quantityByPack) == 0 {
 		return []Pack{}
 	}
 
-	quantityByPack := comp.packSliceToQuantityByPackMap(packs)
-
 	var result []Pack
 	for size, quantity := range quantityByPack {
 		if quantity > 0 {
@@ -150,5 +245,6 @@ func (comp *Computer) toPackModelSlice(packs []int) []Pack {
 			})
 		}
 	}
+	SortPacks(result, DefaultSort)
 	return result
 }
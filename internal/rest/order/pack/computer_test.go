@@ -1,7 +1,10 @@
 package pack
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"math/rand"
 	"slices"
 	"testing"
 )
@@ -12,16 +15,6 @@ func TestComputer_Compute_Success(t *testing.T) {
 		orderSize     int
 		expectedPacks []Pack
 	}{
-		{
-			packSizes:     []int{250, 500},
-			orderSize:     -1,
-			expectedPacks: []Pack{},
-		},
-		{
-			packSizes:     []int{250, 500},
-			orderSize:     0,
-			expectedPacks: []Pack{},
-		},
 		{
 			packSizes: []int{250, 500, 1000, 2000, 5000},
 			orderSize: 1,
@@ -84,6 +77,30 @@ func TestComputer_Compute_Success(t *testing.T) {
 				},
 			},
 		},
+		{
+			packSizes: []int{3, 5},
+			orderSize: 7,
+			expectedPacks: []Pack{
+				{
+					Size:     5,
+					Quantity: 1,
+				},
+				{
+					Size:     3,
+					Quantity: 1,
+				},
+			},
+		},
+		{
+			packSizes: []int{23, 31, 53},
+			orderSize: 24,
+			expectedPacks: []Pack{
+				{
+					Size:     31,
+					Quantity: 1,
+				},
+			},
+		},
 		{
 			packSizes: []int{23, 31, 53},
 			orderSize: 500000,
@@ -108,9 +125,12 @@ func TestComputer_Compute_Success(t *testing.T) {
 		t.Run(fmt.Sprintf("with order size: %d", d.orderSize), func(t *testing.T) {
 			comp := NewComputer()
 
-			packs := comp.ComputePacks(d.packSizes, d.orderSize)
+			packs, err := comp.ComputePacks(context.Background(), d.packSizes, d.orderSize)
+			if err != nil {
+				t.Fatal(err)
+			}
 
-			if !EqualSlice(packs, d.expectedPacks) {
+			if !slices.Equal(packs, d.expectedPacks) {
 				t.Errorf("unexpected packs: got '%+v' want '%+v'", packs, d.expectedPacks)
 			}
 		})
@@ -121,7 +141,10 @@ func TestComputer_Compute_UnorderedPackSizes(t *testing.T) {
 	comp := NewComputer()
 	packSizes := []int{250, 2000, 1000, 500, 5000}
 
-	packs := comp.ComputePacks(packSizes, 12001)
+	packs, err := comp.ComputePacks(context.Background(), packSizes, 12001)
+	if err != nil {
+		t.Fatal(err)
+	}
 
 	expectedPacks := []Pack{
 		{
@@ -137,7 +160,7 @@ func TestComputer_Compute_UnorderedPackSizes(t *testing.T) {
 			Quantity: 1,
 		},
 	}
-	if !EqualSlice(packs, expectedPacks) {
+	if !slices.Equal(packs, expectedPacks) {
 		t.Errorf("unexpected packs: got '%+v' want '%+v'", packs, expectedPacks)
 	}
 }
@@ -147,9 +170,253 @@ func TestComputer_Compute_PackSizesSliceNotAffected(t *testing.T) {
 	packSizes := []int{250, 2000, 1000, 500, 5000}
 	oracle := []int{250, 2000, 1000, 500, 5000}
 
-	packs := comp.ComputePacks(packSizes, 12001)
+	packs, err := comp.ComputePacks(context.Background(), packSizes, 12001)
+	if err != nil {
+		t.Fatal(err)
+	}
 
 	if !slices.Equal(packSizes, oracle) {
 		t.Errorf("unexpected packs: got '%+v' want '%+v'", packs, oracle)
 	}
 }
+
+func TestComputer_Compute_MatchesBruteForceOracle(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+
+	for i := 0; i < 200; i++ {
+		packSizes := randomPackSizes(rnd, 4, 60)
+		orderSize := rnd.Intn(300) + 1
+
+		t.Run(fmt.Sprintf("with pack sizes: %v and order size: %d", packSizes, orderSize), func(t *testing.T) {
+			comp := NewComputer()
+
+			packs, err := comp.ComputePacks(context.Background(), packSizes, orderSize)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assertPacksUseSizes(t, packs, packSizes)
+			expectedItems, expectedPacks := bruteForceItemsAndPacks(packSizes, orderSize)
+			items, numPacks := countItemsAndPacks(packs)
+			if items != expectedItems || numPacks != expectedPacks {
+				t.Errorf("unexpected items and packs: got '%d' items in '%d' packs want '%d' items in '%d' packs",
+					items, numPacks, expectedItems, expectedPacks)
+			}
+		})
+	}
+}
+
+func TestComputer_Compute_InvalidInput(t *testing.T) {
+	data := []struct {
+		packSizes []int
+		orderSize int
+	}{
+		{
+			packSizes: []int{250, 500},
+			orderSize: -1,
+		},
+		{
+			packSizes: []int{250, 500},
+			orderSize: 0,
+		},
+		{
+			packSizes: []int{},
+			orderSize: 1,
+		},
+		{
+			packSizes: []int{0, 250},
+			orderSize: 1,
+		},
+	}
+
+	for _, d := range data {
+		t.Run(fmt.Sprintf("with pack sizes: %v and order size: %d", d.packSizes, d.orderSize), func(t *testing.T) {
+			comp := NewComputer()
+
+			_, err := comp.ComputePacks(context.Background(), d.packSizes, d.orderSize)
+
+			if !errors.Is(err, ErrInvalidInput) {
+				t.Errorf("unexpected error: got '%v' want '%v'", err, ErrInvalidInput)
+			}
+		})
+	}
+}
+
+func TestComputer_Compute_InputTooLarge(t *testing.T) {
+	comp := NewComputerWithMaxTableSize(1000)
+
+	_, err := comp.ComputePacks(context.Background(), []int{23, 31, 53}, 1000)
+
+	if !errors.Is(err, ErrInputTooLarge) {
+		t.Errorf("unexpected error: got '%v' want '%v'", err, ErrInputTooLarge)
+	}
+}
+
+func TestComputer_Compute_Cancelled(t *testing.T) {
+	comp := NewComputer()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := comp.ComputePacks(ctx, []int{23, 31, 53}, 500000)
+
+	if !errors.Is(err, ErrCancelled) {
+		t.Errorf("unexpected error: got '%v' want '%v'", err, ErrCancelled)
+	}
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("unexpected error: got '%v' want '%v'", err, context.Canceled)
+	}
+}
+
+func TestComputer_Compute_LargeOrderSize(t *testing.T) {
+	data := []struct {
+		packSizes     []int
+		orderSize     int
+		expectedPacks []Pack
+	}{
+		{
+			packSizes: []int{250, 500, 1000, 2000, 5000},
+			orderSize: 2000000001,
+			expectedPacks: []Pack{
+				{
+					Size:     5000,
+					Quantity: 400000,
+				},
+				{
+					Size:     250,
+					Quantity: 1,
+				},
+			},
+		},
+		{
+			packSizes: []int{23, 31, 53},
+			orderSize: 2000000000,
+			expectedPacks: []Pack{
+				{
+					Size:     53,
+					Quantity: 37735846,
+				},
+				{
+					Size:     31,
+					Quantity: 3,
+				},
+				{
+					Size:     23,
+					Quantity: 3,
+				},
+			},
+		},
+	}
+
+	for _, d := range data {
+		t.Run(fmt.Sprintf("with order size: %d", d.orderSize), func(t *testing.T) {
+			comp := NewComputer()
+
+			packs, err := comp.ComputePacks(context.Background(), d.packSizes, d.orderSize)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !slices.Equal(packs, d.expectedPacks) {
+				t.Errorf("unexpected packs: got '%+v' want '%+v'", packs, d.expectedPacks)
+			}
+		})
+	}
+}
+
+func TestComputer_Compute_BoundedMatchesDirect(t *testing.T) {
+	rnd := rand.New(rand.NewSource(2))
+
+	for i := 0; i < 200; i++ {
+		packSizes := randomPackSizes(rnd, 4, 40)
+		slices.Sort(packSizes)
+		largestPackSize := packSizes[len(packSizes)-1]
+		orderSize := largestPackSize*largestPackSize + rnd.Intn(2000) + 1
+
+		t.Run(fmt.Sprintf("with pack sizes: %v and order size: %d", packSizes, orderSize), func(t *testing.T) {
+			comp := NewComputer()
+
+			quantityByPack, err := comp.computeQuantityByPack(context.Background(), packSizes, orderSize)
+			if err != nil {
+				t.Fatal(err)
+			}
+			expectedQuantityByPack, err := comp.computeDirectQuantityByPack(context.Background(), packSizes, orderSize)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			items, numPacks := countItemsAndPacks(comp.toPackModelSlice(quantityByPack))
+			expectedItems, expectedPacks := countItemsAndPacks(comp.toPackModelSlice(expectedQuantityByPack))
+			if items != expectedItems || numPacks != expectedPacks {
+				t.Errorf("unexpected items and packs: got '%d' items in '%d' packs want '%d' items in '%d' packs",
+					items, numPacks, expectedItems, expectedPacks)
+			}
+		})
+	}
+}
+
+func randomPackSizes(rnd *rand.Rand, maxLen, maxSize int) []int {
+	packSizes := make([]int, rnd.Intn(maxLen)+1)
+	for i := range packSizes {
+		packSizes[i] = rnd.Intn(maxSize) + 1
+	}
+	return packSizes
+}
+
+// bruteForceItemsAndPacks returns the fewest items and, within those, the fewest packs that fulfill the order size,
+// by trying every combination of pack quantities that ships less than orderSize+largestPackSize items.
+func bruteForceItemsAndPacks(packSizes []int, orderSize int) (int, int) {
+	limit := orderSize + slices.Max(packSizes)
+	bestItems, bestPacks := limit, 0
+
+	var search func(i, items, packs int)
+	search = func(i, items, packs int) {
+		if items >= limit {
+			return
+		}
+		if i == len(packSizes) {
+			if items >= orderSize && (items < bestItems || items == bestItems && packs < bestPacks) {
+				bestItems, bestPacks = items, packs
+			}
+			return
+		}
+		for quantity := 0; items+quantity*packSizes[i] < limit; quantity++ {
+			search(i+1, items+quantity*packSizes[i], packs+quantity)
+		}
+	}
+	search(0, 0, 0)
+
+	return bestItems, bestPacks
+}
+
+func countItemsAndPacks(packs []Pack) (int, int) {
+	items, numPacks := 0, 0
+	for _, p := range packs {
+		items += p.Size * p.Quantity
+		numPacks += p.Quantity
+	}
+	return items, numPacks
+}
+
+func assertPacksUseSizes(t *testing.T, packs []Pack, packSizes []int) {
+	for _, p := range packs {
+		if !slices.Contains(packSizes, p.Size) || p.Quantity <= 0 {
+			t.Errorf("unexpected pack: got '%+v' for pack sizes '%+v'", p, packSizes)
+		}
+	}
+}
+
+func TestComputer_Compute_SortedBySizeDescending(t *testing.T) {
+	comp := NewComputer()
+
+	for i := 0; i < 10; i++ {
+		packs, err := comp.ComputePacks(context.Background(), []int{5000, 250, 2000, 1000, 500}, 12001)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		expectedPacks := []Pack{{Size: 5000, Quantity: 2}, {Size: 2000, Quantity: 1}, {Size: 250, Quantity: 1}}
+		if !slices.Equal(packs, expectedPacks) {
+			t.Fatalf("unexpected packs: got '%+v' want '%+v'", packs, expectedPacks)
+		}
+	}
+}
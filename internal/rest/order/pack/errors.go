@@ -0,0 +1,22 @@
+package pack
+
+import "errors"
+
+var (
+	// ErrInvalidInput is returned when there are no pack sizes, or the pack sizes or the order size are not greater
+	// than zero.
+	ErrInvalidInput = errors.New("invalid pack sizes or order size")
+
+	// ErrInsufficientStock is returned when the pack sizes could fulfill the order, but not the packs in stock.
+	ErrInsufficientStock = errors.New("the order cannot be fulfilled with the packs in stock")
+
+	// ErrNoCosts is returned when the packs are computed with the cost objective and a pack size has no unit cost.
+	ErrNoCosts = errors.New("the pack sizes have no unit costs")
+
+	// ErrInputTooLarge is returned when computing the packs would need more memory than the computer allows.
+	ErrInputTooLarge = errors.New("the pack sizes or the order size are too large to compute")
+
+	// ErrCancelled is returned when the context is done before the packs are computed.
+	// It wraps the context's error.
+	ErrCancelled = errors.New("the pack computation was cancelled")
+)
@@ -0,0 +1,196 @@
+package pack
+
+import (
+	"cmp"
+	"context"
+	"maps"
+	"slices"
+)
+
+// The rules that rank the packs of an order, besides shipping whole packs only (see Explain).
+const (
+	// RuleFewestItems sends out as few items as possible.
+	RuleFewestItems = 2
+	// RuleFewestPacks sends out as few packs as possible, within the fewest items.
+	RuleFewestPacks = 3
+)
+
+// The reasons a runner-up was eliminated by, one per rule.
+const (
+	ReasonMoreItems = "more_items"
+	ReasonMorePacks = "more_packs"
+)
+
+// Solution is a combination of packs that fulfills an order, with its items and number of packs.
+type Solution struct {
+	Packs     []Pack
+	Items     int
+	PackCount int
+}
+
+// Explanation is why the packs of an order were chosen: the items they ship over the order size and the runners-up
+// they beat, each with the rule that eliminated it.
+type Explanation struct {
+	ItemsShipped int        `json:"items_shipped"`
+	Overshoot    int        `json:"overshoot"`
+	RunnersUp    []RunnerUp `json:"runners_up"`
+}
+
+// RunnerUp is a combination of packs that fulfills the order but was eliminated by a rule (e.g. RuleFewestPacks).
+type RunnerUp struct {
+	Packs        []Pack `json:"packs"`
+	ItemsShipped int    `json:"items_shipped"`
+	PackCount    int    `json:"pack_count"`
+	Rule         int    `json:"rule"`
+	Reason       string `json:"reason"`
+}
+
+// Explain explains the packs of the order size with up to limit of the candidates, in order, that are worse than the
+// packs by the fewest items and then the fewest packs. The candidates with the same packs, or as many items and packs,
+// are skipped.
+func Explain(orderSize int, packs []Pack, candidates []Solution, limit int) Explanation {
+	items, packCount := 0, 0
+	for _, p := range packs {
+		items += p.Size * p.Quantity
+		packCount += p.Quantity
+	}
+
+	result := Explanation{
+		ItemsShipped: items,
+		Overshoot:    items - orderSize,
+		RunnersUp:    []RunnerUp{},
+	}
+	for _, candidate := range candidates {
+		if len(result.RunnersUp) == limit {
+			break
+		}
+		if EqualSlice(candidate.Packs, packs) {
+			continue
+		}
+
+		runnerUp := RunnerUp{Packs: candidate.Packs, ItemsShipped: candidate.Items, PackCount: candidate.PackCount}
+		switch {
+		case candidate.Items > items:
+			runnerUp.Rule, runnerUp.Reason = RuleFewestItems, ReasonMoreItems
+		case candidate.Items == items && candidate.PackCount > packCount:
+			runnerUp.Rule, runnerUp.Reason = RuleFewestPacks, ReasonMorePacks
+		default:
+			continue
+		}
+		result.RunnersUp = append(result.RunnersUp, runnerUp)
+	}
+	return result
+}
+
+// ComputeCandidates computes up to limit combinations of packs in stock that fulfill the order, by the fewest items
+// and then the fewest packs. They are the fewest packs of the smallest totals that can be reached, and the fewest
+// packs of the smallest total that end with each of the pack sizes (e.g. 2x250 instead of 1x500), so that they
+// explain both rules (see Explain).
+// The packs of every candidate are sorted by size, largest first.
+// It returns ErrInvalidInput, ErrInputTooLarge or ErrCancelled if the candidates cannot be computed.
+func (comp *Computer) ComputeCandidates(ctx context.Context, packSizes []int, stock map[int]int, orderSize int, limit int) ([]Solution, error) {
+	if !comp.isValidInput(packSizes, orderSize) || !StockValid(stock) {
+		return nil, ErrInvalidInput
+	}
+	sortedPackSizes := slices.Compact(comp.cloneAndSort(packSizes))
+
+	divisor := comp.gcd(sortedPackSizes)
+	reducedPackSizes := comp.divideSizes(sortedPackSizes, divisor)
+	reducedOrderSize := (orderSize-1)/divisor + 1
+	largestPackSize := reducedPackSizes[len(reducedPackSizes)-1]
+
+	// the same table as computeReducedQuantityByPack
+	bound := comp.tableBound(largestPackSize)
+	size := bound
+	if reducedOrderSize <= bound-largestPackSize {
+		size = reducedOrderSize + largestPackSize
+	}
+	numPacks, usedPackSizes, err := comp.computeMinPacksTable(ctx, reducedPackSizes, size)
+	if err != nil {
+		return nil, err
+	}
+	table := candidatesTable{numPacks: numPacks, usedPackSizes: usedPackSizes, largestPackSize: largestPackSize}
+
+	var quantities []map[int]int
+	smallestTotal := -1
+	for total := reducedOrderSize; total < reducedOrderSize+largestPackSize && len(quantities) <= limit; total++ {
+		quantityByPack, ok := comp.candidateQuantityByPack(table, total)
+		if !ok {
+			continue
+		}
+		if smallestTotal < 0 {
+			smallestTotal = total
+		}
+		quantities = append(quantities, quantityByPack)
+	}
+	for _, packSize := range reducedPackSizes {
+		quantityByPack, ok := comp.candidateQuantityByPack(table, smallestTotal-packSize)
+		if !ok {
+			continue
+		}
+		quantityByPack[packSize]++
+		found := slices.ContainsFunc(quantities, func(q map[int]int) bool {
+			return maps.Equal(q, quantityByPack)
+		})
+		if !found {
+			quantities = append(quantities, quantityByPack)
+		}
+	}
+
+	var result []Solution
+	for _, quantityByPack := range quantities {
+		var solution Solution
+		for reducedPackSize, quantity := range quantityByPack {
+			packSize := reducedPackSize * divisor
+			solution.Packs = append(solution.Packs, Pack{Size: packSize, Quantity: quantity})
+			solution.Items += packSize * quantity
+			solution.PackCount += quantity
+		}
+		slices.SortFunc(solution.Packs, func(a, b Pack) int {
+			return cmp.Compare(b.Size, a.Size)
+		})
+		if InStock(solution.Packs, stock) {
+			result = append(result, solution)
+		}
+	}
+	slices.SortStableFunc(result, func(a, b Solution) int {
+		if a.Items != b.Items {
+			return cmp.Compare(a.Items, b.Items)
+		}
+		return cmp.Compare(a.PackCount, b.PackCount)
+	})
+	if len(result) > limit {
+		result = result[:limit]
+	}
+	return result, nil
+}
+
+// candidatesTable is a table computed by computeMinPacksTable whose larger totals are reached with its largest
+// remainders plus packs of the largest pack size, like computeBoundedQuantityByPack.
+type candidatesTable struct {
+	numPacks, usedPackSizes []int
+	largestPackSize         int
+}
+
+// candidateQuantityByPack returns the quantity of each pack size with the fewest packs that add up to exactly the
+// total, and true if the total can be reached.
+func (comp *Computer) candidateQuantityByPack(table candidatesTable, total int) (map[int]int, bool) {
+	if total < 0 {
+		return nil, false
+	}
+	size := len(table.numPacks)
+	remainder := total
+	if total >= size {
+		remainder = size - table.largestPackSize + total%table.largestPackSize
+	}
+	// unreachable totals have a number of packs greater than any reachable one, see computeMinPacksTable
+	if table.numPacks[remainder] >= size {
+		return nil, false
+	}
+
+	result := comp.computeQuantityByUsedPackSizes(table.usedPackSizes, remainder)
+	if total > remainder {
+		result[table.largestPackSize] += (total - remainder) / table.largestPackSize
+	}
+	return result, true
+}
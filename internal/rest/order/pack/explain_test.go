@@ -0,0 +1,174 @@
+package pack
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestComputer_ComputeCandidates_Success(t *testing.T) {
+	data := []struct {
+		packSizes          []int
+		stock              map[int]int
+		orderSize          int
+		limit              int
+		expectedCandidates []Solution
+	}{
+		{
+			packSizes: []int{250, 500, 1000, 2000, 5000},
+			orderSize: 251,
+			limit:     3,
+			expectedCandidates: []Solution{
+				{Packs: []Pack{{Size: 500, Quantity: 1}}, Items: 500, PackCount: 1},
+				{Packs: []Pack{{Size: 250, Quantity: 2}}, Items: 500, PackCount: 2},
+				{Packs: []Pack{{Size: 500, Quantity: 1}, {Size: 250, Quantity: 1}}, Items: 750, PackCount: 2},
+			},
+		},
+		{
+			packSizes: []int{250, 500, 1000, 2000, 5000},
+			stock:     map[int]int{500: 0},
+			orderSize: 251,
+			limit:     2,
+			expectedCandidates: []Solution{
+				{Packs: []Pack{{Size: 250, Quantity: 2}}, Items: 500, PackCount: 2},
+				{Packs: []Pack{{Size: 1000, Quantity: 1}}, Items: 1000, PackCount: 1},
+			},
+		},
+		{
+			packSizes: []int{23, 31, 53},
+			orderSize: 2000000000,
+			limit:     1,
+			expectedCandidates: []Solution{
+				{
+					Packs:     []Pack{{Size: 53, Quantity: 37735846}, {Size: 31, Quantity: 3}, {Size: 23, Quantity: 3}},
+					Items:     2000000000,
+					PackCount: 37735852,
+				},
+			},
+		},
+	}
+
+	for _, d := range data {
+		t.Run(fmt.Sprintf("with pack sizes: %v, stock: %v and order size: %d", d.packSizes, d.stock, d.orderSize), func(t *testing.T) {
+			comp := NewComputer()
+
+			candidates, err := comp.ComputeCandidates(context.Background(), d.packSizes, d.stock, d.orderSize, d.limit)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(candidates, d.expectedCandidates) {
+				t.Errorf("unexpected candidates: got '%+v' want '%+v'", candidates, d.expectedCandidates)
+			}
+		})
+	}
+}
+
+func TestComputer_ComputeCandidates_FirstMatchesComputedPacks(t *testing.T) {
+	rnd := rand.New(rand.NewSource(4))
+
+	for i := 0; i < 200; i++ {
+		packSizes := randomPackSizes(rnd, 4, 40)
+		orderSize := rnd.Intn(5000) + 1
+
+		t.Run(fmt.Sprintf("with pack sizes: %v and order size: %d", packSizes, orderSize), func(t *testing.T) {
+			comp := NewComputer()
+
+			packs, err := comp.ComputePacks(context.Background(), packSizes, orderSize)
+			if err != nil {
+				t.Fatal(err)
+			}
+			candidates, err := comp.ComputeCandidates(context.Background(), packSizes, nil, orderSize, 5)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			items, numPacks := countItemsAndPacks(packs)
+			if candidates[0].Items != items || candidates[0].PackCount != numPacks {
+				t.Errorf("unexpected first candidate: got '%+v' want '%d' items in '%d' packs", candidates[0], items, numPacks)
+			}
+			for _, candidate := range candidates {
+				assertPacksUseSizes(t, candidate.Packs, packSizes)
+				candidateItems, candidatePacks := countItemsAndPacks(candidate.Packs)
+				if candidateItems != candidate.Items || candidatePacks != candidate.PackCount || candidateItems < orderSize {
+					t.Errorf("unexpected candidate: got '%+v'", candidate)
+				}
+			}
+		})
+	}
+}
+
+func TestComputer_ComputeCandidates_InvalidInput(t *testing.T) {
+	comp := NewComputer()
+
+	_, err := comp.ComputeCandidates(context.Background(), []int{250, 500}, map[int]int{250: -1}, 251, 5)
+
+	if !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("unexpected error: got '%v' want '%v'", err, ErrInvalidInput)
+	}
+}
+
+func TestComputer_ComputeCandidates_Cancelled(t *testing.T) {
+	comp := NewComputer()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := comp.ComputeCandidates(ctx, []int{23, 31, 53}, nil, 500000, 5)
+
+	if !errors.Is(err, ErrCancelled) {
+		t.Errorf("unexpected error: got '%v' want '%v'", err, ErrCancelled)
+	}
+}
+
+func TestExplain(t *testing.T) {
+	packs := []Pack{{Size: 500, Quantity: 1}}
+	candidates := []Solution{
+		{Packs: []Pack{{Size: 500, Quantity: 1}}, Items: 500, PackCount: 1},
+		{Packs: []Pack{{Size: 250, Quantity: 2}}, Items: 500, PackCount: 2},
+		{Packs: []Pack{{Size: 500, Quantity: 1}, {Size: 250, Quantity: 1}}, Items: 750, PackCount: 2},
+		{Packs: []Pack{{Size: 1000, Quantity: 1}}, Items: 1000, PackCount: 1},
+	}
+
+	explanation := Explain(251, packs, candidates, 2)
+
+	expectedExplanation := Explanation{
+		ItemsShipped: 500,
+		Overshoot:    249,
+		RunnersUp: []RunnerUp{
+			{
+				Packs:        []Pack{{Size: 250, Quantity: 2}},
+				ItemsShipped: 500,
+				PackCount:    2,
+				Rule:         RuleFewestPacks,
+				Reason:       ReasonMorePacks,
+			},
+			{
+				Packs:        []Pack{{Size: 500, Quantity: 1}, {Size: 250, Quantity: 1}},
+				ItemsShipped: 750,
+				PackCount:    2,
+				Rule:         RuleFewestItems,
+				Reason:       ReasonMoreItems,
+			},
+		},
+	}
+	if !reflect.DeepEqual(explanation, expectedExplanation) {
+		t.Errorf("unexpected explanation: got '%+v' want '%+v'", explanation, expectedExplanation)
+	}
+}
+
+func TestExplain_SkipsTies(t *testing.T) {
+	packs := []Pack{{Size: 3, Quantity: 2}}
+	candidates := []Solution{
+		{Packs: []Pack{{Size: 4, Quantity: 1}, {Size: 2, Quantity: 1}}, Items: 6, PackCount: 2},
+		{Packs: []Pack{{Size: 3, Quantity: 2}}, Items: 6, PackCount: 2},
+	}
+
+	explanation := Explain(5, packs, candidates, 5)
+
+	if len(explanation.RunnersUp) != 0 {
+		t.Errorf("unexpected runners-up: got '%+v' want none", explanation.RunnersUp)
+	}
+}
@@ -0,0 +1,66 @@
+package pack
+
+import (
+	"context"
+	"fmt"
+	"slices"
+)
+
+// Greedy computes the packs of an order in a single pass over the pack sizes, largest first.
+// It is much faster than the Computer and needs no memory besides the packs, but it does not rank the packs by any
+// objective, so they may have more items, packs or cost than the best ones.
+type Greedy struct{}
+
+func NewGreedy() Greedy {
+	return Greedy{}
+}
+
+// ComputePacksWithObjective computes the packs taking as many packs of every pack size as fit in the items still to be
+// sent, largest first and within the stock, and then the smallest pack in stock that covers the rest. The objective is
+// ignored.
+// It returns ErrInvalidInput, ErrInsufficientStock or ErrCancelled if the packs cannot be computed.
+func (g *Greedy) ComputePacksWithObjective(ctx context.Context, packSizes []int, stock map[int]int, orderSize int, _ Objective) ([]Pack, error) {
+	if !SizesValid(packSizes) || orderSize <= 0 || !StockValid(stock) {
+		return nil, ErrInvalidInput
+	}
+	if ctx.Err() != nil {
+		return nil, fmt.Errorf("%w: %w", ErrCancelled, ctx.Err())
+	}
+
+	sortedPackSizes := slices.Clone(packSizes)
+	slices.Sort(sortedPackSizes)
+	sortedPackSizes = slices.Compact(sortedPackSizes)
+	slices.Reverse(sortedPackSizes)
+
+	quantities := make([]int, len(sortedPackSizes))
+	remaining := orderSize
+	for i, packSize := range sortedPackSizes {
+		quantities[i] = remaining / packSize
+		if available, limited := stock[packSize]; limited {
+			quantities[i] = min(quantities[i], available)
+		}
+		remaining -= quantities[i] * packSize
+	}
+
+	// every pack size in stock is larger than the rest now, so the smallest of them covers it
+	if remaining > 0 {
+		covered := false
+		for i := len(sortedPackSizes) - 1; i >= 0 && !covered; i-- {
+			if available, limited := stock[sortedPackSizes[i]]; !limited || quantities[i] < available {
+				quantities[i]++
+				covered = true
+			}
+		}
+		if !covered {
+			return nil, ErrInsufficientStock
+		}
+	}
+
+	var result []Pack
+	for i, packSize := range sortedPackSizes {
+		if quantities[i] > 0 {
+			result = append(result, Pack{Size: packSize, Quantity: quantities[i]})
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,112 @@
+package pack
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+func TestGreedy_Compute_Success(t *testing.T) {
+	data := []struct {
+		packSizes     []int
+		stock         map[int]int
+		orderSize     int
+		expectedPacks []Pack
+	}{
+		{
+			packSizes:     []int{250, 500, 1000, 2000, 5000},
+			orderSize:     12001,
+			expectedPacks: []Pack{{Size: 5000, Quantity: 2}, {Size: 2000, Quantity: 1}, {Size: 250, Quantity: 1}},
+		},
+		{
+			packSizes:     []int{250, 500, 1000, 2000, 5000},
+			stock:         map[int]int{5000: 1, 250: 0},
+			orderSize:     12001,
+			expectedPacks: []Pack{{Size: 5000, Quantity: 1}, {Size: 2000, Quantity: 3}, {Size: 1000, Quantity: 1}, {Size: 500, Quantity: 1}},
+		},
+		{
+			// the best packs are 3x3, greedy is not optimal
+			packSizes:     []int{3, 5},
+			orderSize:     9,
+			expectedPacks: []Pack{{Size: 5, Quantity: 1}, {Size: 3, Quantity: 2}},
+		},
+	}
+
+	for _, d := range data {
+		t.Run(fmt.Sprintf("with pack sizes: %v, stock: %v and order size: %d", d.packSizes, d.stock, d.orderSize), func(t *testing.T) {
+			g := NewGreedy()
+
+			packs, err := g.ComputePacksWithObjective(context.Background(), d.packSizes, d.stock, d.orderSize, ItemsThenPacks{})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !slices.Equal(packs, d.expectedPacks) {
+				t.Errorf("unexpected packs: got '%+v' want '%+v'", packs, d.expectedPacks)
+			}
+		})
+	}
+}
+
+func TestGreedy_Compute_FulfillsOrderWithinStock(t *testing.T) {
+	rnd := rand.New(rand.NewSource(11))
+
+	for i := 0; i < 300; i++ {
+		packSizes := randomPackSizes(rnd, 4, 60)
+		stock := make(map[int]int)
+		for _, packSize := range packSizes {
+			if rnd.Intn(3) == 0 {
+				stock[packSize] = rnd.Intn(6)
+			}
+		}
+		orderSize := rnd.Intn(300) + 1
+
+		t.Run(fmt.Sprintf("with pack sizes: %v, stock: %v and order size: %d", packSizes, stock, orderSize), func(t *testing.T) {
+			g := NewGreedy()
+
+			packs, err := g.ComputePacksWithObjective(context.Background(), packSizes, stock, orderSize, ItemsThenPacks{})
+
+			_, ok := bruteForceObjectiveCandidate(packSizes, stock, orderSize, ItemsThenPacks{})
+			if !ok {
+				if !errors.Is(err, ErrInsufficientStock) {
+					t.Errorf("unexpected error: got '%v' want '%v'", err, ErrInsufficientStock)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assertPacksUseSizes(t, packs, packSizes)
+			assertPacksInStock(t, packs, stock)
+			if items, _ := countItemsAndPacks(packs); items < orderSize {
+				t.Errorf("unexpected packs: got '%+v' with '%d' items for order size '%d'", packs, items, orderSize)
+			}
+		})
+	}
+}
+
+func TestGreedy_Compute_InvalidInput(t *testing.T) {
+	g := NewGreedy()
+
+	_, err := g.ComputePacksWithObjective(context.Background(), []int{250}, map[int]int{250: -1}, 1, ItemsThenPacks{})
+
+	if !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("unexpected error: got '%v' want '%v'", err, ErrInvalidInput)
+	}
+}
+
+func TestGreedy_Compute_Cancelled(t *testing.T) {
+	g := NewGreedy()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := g.ComputePacksWithObjective(ctx, []int{250}, nil, 1, ItemsThenPacks{})
+
+	if !errors.Is(err, ErrCancelled) {
+		t.Errorf("unexpected error: got '%v' want '%v'", err, ErrCancelled)
+	}
+}
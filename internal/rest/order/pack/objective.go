@@ -0,0 +1,129 @@
+package pack
+
+// The names of the objectives (see NewObjective).
+const (
+	ObjectiveItemsThenPacks = "items_then_packs"
+	ObjectiveCost           = "cost"
+	ObjectivePacksThenItems = "packs_then_items"
+)
+
+// Candidate is a combination of packs that fulfills an order, by its items, packs and weight (see Objective.Weight).
+type Candidate struct {
+	Items, Packs, Weight int
+}
+
+// Objective ranks the combinations of packs that fulfill an order.
+// The computer keeps, for every total of items, the combination with the smallest weight and, within those, the
+// fewest packs, and returns the best of them by Less.
+// Removing a pack from a combination that still fulfills the order must never make it worse, so that only the totals
+// below the order size plus the largest pack size need to be searched. More generally, a candidate with no more items,
+// packs and weight than another one must never be worse (see BranchAndBound).
+type Objective interface {
+	// Weight returns the weight that a pack of the size adds to a combination, not less than zero.
+	Weight(packSize int) int
+	// Less returns true if a is better than b. Candidates with the same items must be ranked by weight and then by
+	// packs.
+	Less(a, b Candidate) bool
+}
+
+// ItemsThenPacks sends out as few items as possible and, within those, as few packs as possible.
+type ItemsThenPacks struct{}
+
+func (ItemsThenPacks) Weight(int) int {
+	return 0
+}
+
+func (ItemsThenPacks) Less(a, b Candidate) bool {
+	if a.Items != b.Items {
+		return a.Items < b.Items
+	}
+	return a.Packs < b.Packs
+}
+
+// PacksThenItems sends out as few packs as possible and, within those, as few items as possible.
+type PacksThenItems struct{}
+
+func (PacksThenItems) Weight(int) int {
+	return 0
+}
+
+func (PacksThenItems) Less(a, b Candidate) bool {
+	if a.Packs != b.Packs {
+		return a.Packs < b.Packs
+	}
+	return a.Items < b.Items
+}
+
+// Cost minimises the total cost of the packs and, within the same cost, sends out as few items and then as few packs
+// as possible. Every pack size must have a unit cost, not less than zero.
+type Cost struct {
+	UnitCosts map[int]int
+	// HandlingFee is added once per order. It does not change which packs are the cheapest.
+	HandlingFee int
+}
+
+func (c Cost) Weight(packSize int) int {
+	return c.UnitCosts[packSize]
+}
+
+func (c Cost) Less(a, b Candidate) bool {
+	if a.Weight != b.Weight {
+		return a.Weight < b.Weight
+	}
+	return ItemsThenPacks{}.Less(a, b)
+}
+
+// Total returns the cost of the packs plus the handling fee.
+func (c Cost) Total(packs []Pack) int {
+	total := c.HandlingFee
+	for _, p := range packs {
+		total += c.UnitCosts[p.Size] * p.Quantity
+	}
+	return total
+}
+
+// ObjectiveValid returns true if name is the name of an objective, or empty for the default one.
+func ObjectiveValid(name string) bool {
+	switch name {
+	case "", ObjectiveItemsThenPacks, ObjectiveCost, ObjectivePacksThenItems:
+		return true
+	default:
+		return false
+	}
+}
+
+// ObjectiveName returns the name of the objective (see NewObjective), or an empty string if it is not one of them.
+func ObjectiveName(objective Objective) string {
+	switch objective.(type) {
+	case ItemsThenPacks:
+		return ObjectiveItemsThenPacks
+	case PacksThenItems:
+		return ObjectivePacksThenItems
+	case Cost:
+		return ObjectiveCost
+	default:
+		return ""
+	}
+}
+
+// NewObjective returns the objective with the given name, ItemsThenPacks if it is empty. The cost objective uses the
+// given cost.
+// It returns ErrInvalidInput if the name is unknown, and ErrNoCosts if it is the cost objective and a pack size has no
+// unit cost.
+func NewObjective(name string, packSizes []int, cost Cost) (Objective, error) {
+	switch name {
+	case "", ObjectiveItemsThenPacks:
+		return ItemsThenPacks{}, nil
+	case ObjectivePacksThenItems:
+		return PacksThenItems{}, nil
+	case ObjectiveCost:
+		for _, packSize := range packSizes {
+			if unitCost, ok := cost.UnitCosts[packSize]; !ok || unitCost < 0 {
+				return nil, ErrNoCosts
+			}
+		}
+		return cost, nil
+	default:
+		return nil, ErrInvalidInput
+	}
+}
@@ -0,0 +1,242 @@
+package pack
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+func TestComputer_ComputeWithObjective_Success(t *testing.T) {
+	data := []struct {
+		objective     Objective
+		stock         map[int]int
+		expectedPacks []Pack
+	}{
+		{
+			objective:     ItemsThenPacks{},
+			expectedPacks: []Pack{{Size: 5000, Quantity: 2}, {Size: 2000, Quantity: 1}, {Size: 250, Quantity: 1}},
+		},
+		{
+			objective:     PacksThenItems{},
+			expectedPacks: []Pack{{Size: 5000, Quantity: 3}},
+		},
+		{
+			objective:     PacksThenItems{},
+			stock:         map[int]int{5000: 2},
+			expectedPacks: []Pack{{Size: 5000, Quantity: 2}, {Size: 2000, Quantity: 1}, {Size: 250, Quantity: 1}},
+		},
+		{
+			objective: Cost{
+				UnitCosts:   map[int]int{250: 300, 500: 150, 1000: 250, 2000: 450, 5000: 1000},
+				HandlingFee: 500,
+			},
+			expectedPacks: []Pack{{Size: 5000, Quantity: 2}, {Size: 2000, Quantity: 1}, {Size: 500, Quantity: 1}},
+		},
+	}
+
+	for _, d := range data {
+		t.Run(fmt.Sprintf("with objective: %+v and stock: %v", d.objective, d.stock), func(t *testing.T) {
+			comp := NewComputer()
+
+			packs, err := comp.ComputePacksWithObjective(context.Background(), []int{250, 500, 1000, 2000, 5000}, d.stock,
+				12001, d.objective)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !slices.Equal(packs, d.expectedPacks) {
+				t.Errorf("unexpected packs: got '%+v' want '%+v'", packs, d.expectedPacks)
+			}
+		})
+	}
+}
+
+func TestComputer_ComputeWithObjective_MatchesBruteForceOracle(t *testing.T) {
+	rnd := rand.New(rand.NewSource(5))
+
+	for i := 0; i < 300; i++ {
+		packSizes := randomPackSizes(rnd, 4, 60)
+		stock := make(map[int]int)
+		unitCosts := make(map[int]int)
+		for _, packSize := range packSizes {
+			if rnd.Intn(3) == 0 {
+				stock[packSize] = rnd.Intn(6)
+			}
+			unitCosts[packSize] = rnd.Intn(20)
+		}
+		objective := Objective(PacksThenItems{})
+		if i%2 == 0 {
+			objective = Cost{UnitCosts: unitCosts}
+		}
+		orderSize := rnd.Intn(300) + 1
+
+		t.Run(fmt.Sprintf("with pack sizes: %v, stock: %v, objective: %+v and order size: %d", packSizes, stock,
+			objective, orderSize), func(t *testing.T) {
+			comp := NewComputer()
+
+			packs, err := comp.ComputePacksWithObjective(context.Background(), packSizes, stock, orderSize, objective)
+
+			expected, ok := bruteForceObjectiveCandidate(packSizes, stock, orderSize, objective)
+			if !ok {
+				if !errors.Is(err, ErrInsufficientStock) {
+					t.Errorf("unexpected error: got '%v' want '%v'", err, ErrInsufficientStock)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assertPacksUseSizes(t, packs, packSizes)
+			assertPacksInStock(t, packs, stock)
+			items, numPacks := countItemsAndPacks(packs)
+			candidate := Candidate{Items: items, Packs: numPacks, Weight: weightOf(packs, objective)}
+			if candidate != expected {
+				t.Errorf("unexpected packs: got '%+v' want '%+v'", candidate, expected)
+			}
+		})
+	}
+}
+
+func TestComputer_ComputeWithObjective_InputTooLarge(t *testing.T) {
+	comp := NewComputerWithMaxTableSize(1000)
+
+	_, err := comp.ComputePacksWithObjective(context.Background(), []int{23, 31}, nil, 990, PacksThenItems{})
+
+	if !errors.Is(err, ErrInputTooLarge) {
+		t.Errorf("unexpected error: got '%v' want '%v'", err, ErrInputTooLarge)
+	}
+}
+
+func TestComputer_ComputeWithObjective_CostInputTooLarge(t *testing.T) {
+	comp := NewComputerWithMaxTableSize(1000)
+	cost := Cost{UnitCosts: map[int]int{2: 1, 3: 1}}
+
+	// the quantities (2*303 ints) fit in 1000 totals (2000 ints), but not along with the scratch rows (7*303 ints)
+	_, err := comp.ComputePacksWithObjective(context.Background(), []int{2, 3}, nil, 300, cost)
+
+	if !errors.Is(err, ErrInputTooLarge) {
+		t.Errorf("unexpected error: got '%v' want '%v'", err, ErrInputTooLarge)
+	}
+}
+
+func TestNewObjective(t *testing.T) {
+	cost := Cost{UnitCosts: map[int]int{250: 1, 500: 2}, HandlingFee: 3}
+	data := []struct {
+		name              string
+		packSizes         []int
+		expectedObjective Objective
+		expectedErr       error
+	}{
+		{
+			name:              "",
+			expectedObjective: ItemsThenPacks{},
+		},
+		{
+			name:              ObjectiveItemsThenPacks,
+			expectedObjective: ItemsThenPacks{},
+		},
+		{
+			name:              ObjectivePacksThenItems,
+			expectedObjective: PacksThenItems{},
+		},
+		{
+			name:              ObjectiveCost,
+			packSizes:         []int{250, 500},
+			expectedObjective: cost,
+		},
+		{
+			name:        ObjectiveCost,
+			packSizes:   []int{250, 500, 1000},
+			expectedErr: ErrNoCosts,
+		},
+		{
+			name:        "fastest",
+			expectedErr: ErrInvalidInput,
+		},
+	}
+
+	for _, d := range data {
+		t.Run(fmt.Sprintf("with name: '%s' and pack sizes: %v", d.name, d.packSizes), func(t *testing.T) {
+			objective, err := NewObjective(d.name, d.packSizes, cost)
+
+			if !errors.Is(err, d.expectedErr) {
+				t.Fatalf("unexpected error: got '%v' want '%v'", err, d.expectedErr)
+			}
+			if d.expectedErr == nil && fmt.Sprint(objective) != fmt.Sprint(d.expectedObjective) {
+				t.Errorf("unexpected objective: got '%+v' want '%+v'", objective, d.expectedObjective)
+			}
+		})
+	}
+}
+
+func TestCost_Total(t *testing.T) {
+	cost := Cost{UnitCosts: map[int]int{250: 3, 500: 5}, HandlingFee: 7}
+
+	total := cost.Total([]Pack{{Size: 250, Quantity: 2}, {Size: 500, Quantity: 1}})
+
+	if total != 18 {
+		t.Errorf("unexpected total: got '%d' want '%d'", total, 18)
+	}
+}
+
+// bruteForceObjectiveCandidate searches every combination of packs in stock below orderSize+largestPackSize and
+// returns the best by the objective. It returns false if the stock cannot fulfill the order size.
+func bruteForceObjectiveCandidate(packSizes []int, stock map[int]int, orderSize int, objective Objective) (Candidate, bool) {
+	packSizes = slices.Clone(packSizes)
+	slices.Sort(packSizes)
+	packSizes = slices.Compact(packSizes)
+	limit := orderSize + slices.Max(packSizes)
+	var best Candidate
+	found := false
+
+	var search func(i int, candidate Candidate)
+	search = func(i int, candidate Candidate) {
+		if candidate.Items >= limit {
+			return
+		}
+		if i == len(packSizes) {
+			if candidate.Items >= orderSize && (!found || objective.Less(candidate, best)) {
+				best, found = candidate, true
+			}
+			return
+		}
+		maxQuantity, limited := stock[packSizes[i]]
+		for quantity := 0; candidate.Items+quantity*packSizes[i] < limit && (!limited || quantity <= maxQuantity); quantity++ {
+			search(i+1, Candidate{
+				Items:  candidate.Items + quantity*packSizes[i],
+				Packs:  candidate.Packs + quantity,
+				Weight: candidate.Weight + quantity*objective.Weight(packSizes[i]),
+			})
+		}
+	}
+	search(0, Candidate{})
+
+	return best, found
+}
+
+func weightOf(packs []Pack, objective Objective) int {
+	weight := 0
+	for _, p := range packs {
+		weight += p.Quantity * objective.Weight(p.Size)
+	}
+	return weight
+}
+
+func TestObjectiveName(t *testing.T) {
+	for _, name := range []string{ObjectiveItemsThenPacks, ObjectiveCost, ObjectivePacksThenItems} {
+		objective, err := NewObjective(name, nil, Cost{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ObjectiveName(objective) != name {
+			t.Errorf("unexpected name: got '%s' want '%s'", ObjectiveName(objective), name)
+		}
+	}
+	if name := ObjectiveName(nil); name != "" {
+		t.Errorf("unexpected name of a nil objective: got '%s'", name)
+	}
+}
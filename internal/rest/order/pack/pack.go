@@ -1,5 +1,7 @@
 package pack
 
+import "slices"
+
 // SizesValid return true if there are pack sizes and all are greater than zero, false otherwise.
 func SizesValid(packSizes []int) bool {
 	if packSizes == nil || len(packSizes) == 0 {
@@ -15,25 +17,12 @@ func SizesValid(packSizes []int) bool {
 	return true
 }
 
+// RemoveDuplicateSizes returns the pack sizes without duplicates, sorted from the smallest, so that configs are saved
+// and returned in a deterministic order.
 func RemoveDuplicateSizes(packSizes []int) []int {
-	packSizesSet := sliceToSet(packSizes)
-	return setToSlice(packSizesSet)
-}
-
-func sliceToSet(packSizes []int) map[int]bool {
-	result := make(map[int]bool)
-	for _, packSize := range packSizes {
-		result[packSize] = true
-	}
-	return result
-}
-
-func setToSlice(packSizes map[int]bool) []int {
-	var result []int
-	for packSize := range packSizes {
-		result = append(result, packSize)
-	}
-	return result
+	result := slices.Clone(packSizes)
+	slices.Sort(result)
+	return slices.Compact(result)
 }
 
 // EqualSlice returns true if both pack slices have the exact same packs (even if unordered), false otherwise.
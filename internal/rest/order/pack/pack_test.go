@@ -45,11 +45,10 @@ func TestSizesValid_ValidPackSizes(t *testing.T) {
 }
 
 func TestRemoveDuplicateSizes(t *testing.T) {
-	packSizes := []int{250, 250, 500, 500, 1000}
+	packSizes := []int{500, 250, 1000, 250, 500}
 
 	result := RemoveDuplicateSizes(packSizes)
 
-	slices.Sort(result)
 	expectedResult := []int{250, 500, 1000}
 	if !slices.Equal(result, expectedResult) {
 		t.Errorf("unexpected packs: got '%+v' want '%+v'", result, expectedResult)
@@ -0,0 +1,29 @@
+package pack
+
+// The names of the solvers, i.e. the computers of the packs (see SolverValid).
+const (
+	// SolverDP is the Computer, which computes the best packs with dynamic programming.
+	SolverDP = "dp"
+	// SolverBranchAndBound is the BranchAndBound computer, which searches the best packs exactly.
+	SolverBranchAndBound = "branch_and_bound"
+	// SolverGreedy is the Greedy computer, which computes good but not always the best packs.
+	SolverGreedy = "greedy"
+	// DefaultSolver is the solver used unless another one is configured.
+	DefaultSolver = SolverDP
+)
+
+// SolverExact returns true if name is the name of a solver that always computes the best packs by the objective, false
+// otherwise.
+func SolverExact(name string) bool {
+	return name == SolverDP || name == SolverBranchAndBound
+}
+
+// SolverValid returns true if name is the name of a solver, false otherwise.
+func SolverValid(name string) bool {
+	switch name {
+	case SolverDP, SolverBranchAndBound, SolverGreedy:
+		return true
+	default:
+		return false
+	}
+}
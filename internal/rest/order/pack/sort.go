@@ -0,0 +1,46 @@
+package pack
+
+import (
+	"cmp"
+	"slices"
+)
+
+// The orders of the packs (see SortPacks).
+const (
+	// SortSizeDesc sorts the packs by size, largest first.
+	SortSizeDesc = "size_desc"
+	// SortSizeAsc sorts the packs by size, smallest first.
+	SortSizeAsc = "size_asc"
+	// SortQuantityDesc sorts the packs by quantity, most first, and then by size, largest first.
+	SortQuantityDesc = "quantity_desc"
+	// DefaultSort is the order of the packs computed by the computers.
+	DefaultSort = SortSizeDesc
+)
+
+// SortValid returns true if by is empty, meaning DefaultSort, or the name of an order of the packs, false otherwise.
+func SortValid(by string) bool {
+	switch by {
+	case "", SortSizeDesc, SortSizeAsc, SortQuantityDesc:
+		return true
+	default:
+		return false
+	}
+}
+
+// SortPacks sorts the packs in the order by, which must be valid. Every pack has a different size, so the order is
+// deterministic.
+func SortPacks(packs []Pack, by string) {
+	slices.SortFunc(packs, func(a, b Pack) int {
+		switch by {
+		case SortSizeAsc:
+			return cmp.Compare(a.Size, b.Size)
+		case SortQuantityDesc:
+			if a.Quantity != b.Quantity {
+				return cmp.Compare(b.Quantity, a.Quantity)
+			}
+			return cmp.Compare(b.Size, a.Size)
+		default:
+			return cmp.Compare(b.Size, a.Size)
+		}
+	})
+}
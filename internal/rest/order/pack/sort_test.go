@@ -0,0 +1,54 @@
+package pack
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestSortPacks(t *testing.T) {
+	data := []struct {
+		by            string
+		expectedPacks []Pack
+	}{
+		{
+			by:            "",
+			expectedPacks: []Pack{{Size: 5000, Quantity: 2}, {Size: 2000, Quantity: 1}, {Size: 250, Quantity: 2}},
+		},
+		{
+			by:            SortSizeDesc,
+			expectedPacks: []Pack{{Size: 5000, Quantity: 2}, {Size: 2000, Quantity: 1}, {Size: 250, Quantity: 2}},
+		},
+		{
+			by:            SortSizeAsc,
+			expectedPacks: []Pack{{Size: 250, Quantity: 2}, {Size: 2000, Quantity: 1}, {Size: 5000, Quantity: 2}},
+		},
+		{
+			by:            SortQuantityDesc,
+			expectedPacks: []Pack{{Size: 5000, Quantity: 2}, {Size: 250, Quantity: 2}, {Size: 2000, Quantity: 1}},
+		},
+	}
+
+	for _, d := range data {
+		t.Run(fmt.Sprintf("by: '%s'", d.by), func(t *testing.T) {
+			packs := []Pack{{Size: 250, Quantity: 2}, {Size: 5000, Quantity: 2}, {Size: 2000, Quantity: 1}}
+
+			SortPacks(packs, d.by)
+
+			if !reflect.DeepEqual(packs, d.expectedPacks) {
+				t.Errorf("unexpected packs: got '%+v' want '%+v'", packs, d.expectedPacks)
+			}
+		})
+	}
+}
+
+func TestSortValid(t *testing.T) {
+	for _, by := range []string{"", SortSizeDesc, SortSizeAsc, SortQuantityDesc} {
+		if !SortValid(by) {
+			t.Errorf("unexpected invalid sort: '%s'", by)
+		}
+	}
+	if SortValid("random") {
+		t.Errorf("unexpected valid sort: 'random'")
+	}
+}
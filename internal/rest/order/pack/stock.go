@@ -0,0 +1,232 @@
+package pack
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+)
+
+// StockValid returns true if no pack size has a negative stock, false otherwise.
+func StockValid(stock map[int]int) bool {
+	for _, quantity := range stock {
+		if quantity < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// ComputePacksWithStock computes the packs like ComputePacks, with at most stock[size] packs of every pack size in
+// stock. Pack sizes missing from the stock are unlimited.
+// The packs computed without stock are returned if they are in stock, otherwise the packs are computed with a table of
+// (orderSize+largestPackSize)*(len(packSizes)+5) ints (see computeObjectiveQuantityByPack).
+// It returns ErrInsufficientStock if the stock cannot fulfill the order, besides the errors of ComputePacks.
+func (comp *Computer) ComputePacksWithStock(ctx context.Context, packSizes []int, stock map[int]int, orderSize int) ([]Pack, error) {
+	return comp.ComputePacksWithObjective(ctx, packSizes, stock, orderSize, ItemsThenPacks{})
+}
+
+// ComputePacksWithObjective computes the packs like ComputePacksWithStock, choosing the best packs by the objective
+// instead of by the fewest items and then the fewest packs.
+// Objectives other than ItemsThenPacks (e.g. Cost) are always computed with a table of
+// (orderSize+largestPackSize)*(len(packSizes)+5) ints, so they return ErrInputTooLarge for smaller orders.
+func (comp *Computer) ComputePacksWithObjective(ctx context.Context, packSizes []int, stock map[int]int, orderSize int, objective Objective) ([]Pack, error) {
+	if !comp.isValidInput(packSizes, orderSize) || !StockValid(stock) {
+		return nil, ErrInvalidInput
+	}
+	sortedPackSizes := slices.Compact(comp.cloneAndSort(packSizes))
+
+	if _, ok := objective.(ItemsThenPacks); ok {
+		packs, err := comp.compute(ctx, sortedPackSizes, orderSize)
+		if err != nil {
+			return nil, err
+		}
+		if InStock(packs, stock) {
+			return packs, nil
+		}
+	}
+
+	var availablePackSizes []int
+	for _, packSize := range sortedPackSizes {
+		if quantity, limited := stock[packSize]; !limited || quantity > 0 {
+			availablePackSizes = append(availablePackSizes, packSize)
+		}
+	}
+	if comp.stockCapacity(availablePackSizes, stock) < orderSize {
+		return nil, ErrInsufficientStock
+	}
+
+	quantityByPack, err := comp.computeObjectiveQuantityByPack(ctx, availablePackSizes, stock, orderSize, objective)
+	if err != nil {
+		return nil, err
+	}
+	return comp.toPackModelSlice(quantityByPack), nil
+}
+
+// InStock returns true if there are at least as many packs of every pack size in stock as in the packs, false otherwise.
+// Pack sizes missing from the stock are unlimited.
+func InStock(packs []Pack, stock map[int]int) bool {
+	for _, p := range packs {
+		if quantity, limited := stock[p.Size]; limited && p.Quantity > quantity {
+			return false
+		}
+	}
+	return true
+}
+
+// stockCapacity returns the items of all the packs in stock, or the largest int if any of the pack sizes is unlimited
+// (or the items overflow).
+func (comp *Computer) stockCapacity(packSizes []int, stock map[int]int) int {
+	capacity := 0
+	for _, packSize := range packSizes {
+		quantity, limited := stock[packSize]
+		if !limited || quantity > (math.MaxInt-capacity)/packSize {
+			return math.MaxInt
+		}
+		capacity += quantity * packSize
+	}
+	return capacity
+}
+
+// computeObjectiveQuantityByPack computes the quantity of each pack size that fulfills the order size within the
+// stock and is the best by the objective.
+// Like computeDirectQuantityByPack, only the totals below orderSize+largestPackSize need to be searched: a solution
+// with more items can always drop one of its packs, which is always in stock, and the objective never ranks it worse.
+// The pack sizes and the order size are first divided by the pack sizes' greatest common divisor.
+// It returns ErrInsufficientStock if no total can be reached, and ErrInputTooLarge if the table, with its scratch rows,
+// would use more memory than the computer's maximum table size allows (see boundedScratchRows).
+func (comp *Computer) computeObjectiveQuantityByPack(ctx context.Context, packSizes []int, stock map[int]int, orderSize int, objective Objective) (map[int]int, error) {
+	divisor := comp.gcd(packSizes)
+	reducedPackSizes := comp.divideSizes(packSizes, divisor)
+	reducedOrderSize := (orderSize-1)/divisor + 1
+
+	bounds := make([]int, len(packSizes))
+	weights := make([]int, len(packSizes))
+	for i, packSize := range packSizes {
+		bounds[i] = math.MaxInt
+		if quantity, limited := stock[packSize]; limited {
+			bounds[i] = quantity
+		}
+		weights[i] = objective.Weight(packSize)
+	}
+
+	largestPackSize := reducedPackSizes[len(reducedPackSizes)-1]
+	size := reducedOrderSize + largestPackSize
+	if !comp.tableFits(size, len(reducedPackSizes)+boundedScratchRows) {
+		return nil, ErrInputTooLarge
+	}
+
+	table, err := comp.computeBoundedMinPacksTable(ctx, reducedPackSizes, bounds, weights, size)
+	if err != nil {
+		return nil, err
+	}
+
+	total := -1
+	var best Candidate
+	for t := reducedOrderSize; t < size; t++ {
+		if table.numPacks[t] >= size {
+			continue
+		}
+		candidate := Candidate{Items: t * divisor, Packs: table.numPacks[t], Weight: table.weights[t]}
+		if total < 0 || objective.Less(candidate, best) {
+			total, best = t, candidate
+		}
+	}
+	if total < 0 {
+		return nil, ErrInsufficientStock
+	}
+	quantities := table.quantities
+
+	result := make(map[int]int, len(packSizes))
+	for i := len(reducedPackSizes) - 1; i >= 0; i-- {
+		quantity := quantities[i][total]
+		result[packSizes[i]] = quantity
+		total -= quantity * reducedPackSizes[i]
+	}
+	return result, nil
+}
+
+// boundedScratchRows is the number of ints kept for every total by computeBoundedMinPacksTable besides a row of
+// quantities per pack size: the weights and the numbers of packs, their previous rows and the queue.
+const boundedScratchRows = 5
+
+// boundedMinPacksTable is the table computed by computeBoundedMinPacksTable.
+type boundedMinPacksTable struct {
+	weights, numPacks []int
+	quantities        [][]int
+}
+
+// computeBoundedMinPacksTable computes, for every total in [0, size), the minimum weight and, within it, the minimum
+// number of packs that add up to exactly that total with at most bounds[i] packs of packSizes[i], each of weights[i]
+// (weights and numPacks), and the quantity of each pack size used to reach it with the first i+1 pack sizes
+// (quantities[i]).
+// Unreachable totals have a number of packs greater than or equal to size.
+// Every pack size is added in turn: the totals with the same remainder modulo the pack size form a sequence where
+// adding k packs moves k positions, so the minimum over the last bounds[i]+1 positions is kept with a monotonic queue.
+// It returns ErrCancelled if the context is done before the table is computed.
+func (comp *Computer) computeBoundedMinPacksTable(ctx context.Context, packSizes, bounds, weights []int, size int) (boundedMinPacksTable, error) {
+	placeholder := size
+	// unreachable totals are never lighter than reachable ones, and adding packs to them cannot overflow
+	unreachableWeight := math.MaxInt / 2
+	numPacks := make([]int, size)
+	numPacks = comp.fillWithPlaceholderFromIndex(numPacks, placeholder, 1)
+	totalWeights := make([]int, size)
+	totalWeights = comp.fillWithPlaceholderFromIndex(totalWeights, unreachableWeight, 1)
+	previous := make([]int, size)
+	previousWeights := make([]int, size)
+	quantities := make([][]int, len(packSizes))
+	queue := make([]int, 0, size)
+
+	// key returns the weight and packs of the position j of a sequence, minus those of j packs
+	key := func(total, j, weight int) (int, int) {
+		return previousWeights[total] - j*weight, previous[total] - j
+	}
+
+	computed := 0
+	for i, packSize := range packSizes {
+		copy(previous, numPacks)
+		copy(previousWeights, totalWeights)
+		quantities[i] = make([]int, size)
+		weight := weights[i]
+
+		for remainder := 0; remainder < packSize && remainder < size; remainder++ {
+			// queue has the positions j (total remainder+j*packSize) in the window, by increasing key
+			queue = queue[:0]
+			head := 0
+			for j, total := 0, remainder; total < size; j, total = j+1, total+packSize {
+				// checked on the first total too, so that an already cancelled context never computes the table
+				if computed%cancelCheckInterval == 0 && ctx.Err() != nil {
+					return boundedMinPacksTable{}, fmt.Errorf("%w: %w", ErrCancelled, ctx.Err())
+				}
+				computed++
+
+				valueWeight, valuePacks := key(total, j, weight)
+				for len(queue) > head {
+					last := queue[len(queue)-1]
+					lastWeight, lastPacks := key(remainder+last*packSize, last, weight)
+					if lastWeight < valueWeight || lastWeight == valueWeight && lastPacks < valuePacks {
+						break
+					}
+					queue = queue[:len(queue)-1]
+				}
+				queue = append(queue, j)
+				if queue[head] < j-bounds[i] {
+					head++
+				}
+
+				best := queue[head]
+				bestTotal := remainder + best*packSize
+				packs := previous[bestTotal] + j - best
+				packsWeight := previousWeights[bestTotal] + (j-best)*weight
+				if packs < placeholder && (packsWeight < totalWeights[total] ||
+					packsWeight == totalWeights[total] && packs < numPacks[total]) {
+					numPacks[total] = packs
+					totalWeights[total] = packsWeight
+					quantities[i][total] = j - best
+				}
+			}
+		}
+	}
+
+	return boundedMinPacksTable{weights: totalWeights, numPacks: numPacks, quantities: quantities}, nil
+}
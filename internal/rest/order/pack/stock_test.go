@@ -0,0 +1,241 @@
+package pack
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+func TestComputer_ComputeWithStock_Success(t *testing.T) {
+	data := []struct {
+		packSizes     []int
+		stock         map[int]int
+		orderSize     int
+		expectedPacks []Pack
+	}{
+		{
+			packSizes:     []int{250, 500, 1000, 2000, 5000},
+			stock:         map[int]int{5000: 3},
+			orderSize:     12001,
+			expectedPacks: []Pack{{Size: 5000, Quantity: 2}, {Size: 2000, Quantity: 1}, {Size: 250, Quantity: 1}},
+		},
+		{
+			packSizes:     []int{250, 500, 1000, 2000, 5000},
+			stock:         map[int]int{5000: 1},
+			orderSize:     12001,
+			expectedPacks: []Pack{{Size: 5000, Quantity: 1}, {Size: 2000, Quantity: 3}, {Size: 1000, Quantity: 1}, {Size: 250, Quantity: 1}},
+		},
+		{
+			packSizes:     []int{250, 500, 1000, 2000, 5000},
+			stock:         map[int]int{250: 0, 500: 0},
+			orderSize:     1,
+			expectedPacks: []Pack{{Size: 1000, Quantity: 1}},
+		},
+		{
+			packSizes:     []int{3, 5},
+			stock:         map[int]int{5: 1},
+			orderSize:     10,
+			expectedPacks: []Pack{{Size: 5, Quantity: 1}, {Size: 3, Quantity: 2}},
+		},
+	}
+
+	for _, d := range data {
+		t.Run(fmt.Sprintf("with stock: %v and order size: %d", d.stock, d.orderSize), func(t *testing.T) {
+			comp := NewComputer()
+
+			packs, err := comp.ComputePacksWithStock(context.Background(), d.packSizes, d.stock, d.orderSize)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !slices.Equal(packs, d.expectedPacks) {
+				t.Errorf("unexpected packs: got '%+v' want '%+v'", packs, d.expectedPacks)
+			}
+		})
+	}
+}
+
+func TestComputer_ComputeWithStock_MatchesBruteForceOracle(t *testing.T) {
+	rnd := rand.New(rand.NewSource(3))
+
+	for i := 0; i < 300; i++ {
+		packSizes := randomPackSizes(rnd, 4, 60)
+		stock := make(map[int]int)
+		for _, packSize := range packSizes {
+			if rnd.Intn(3) > 0 {
+				stock[packSize] = rnd.Intn(6)
+			}
+		}
+		orderSize := rnd.Intn(300) + 1
+
+		t.Run(fmt.Sprintf("with pack sizes: %v, stock: %v and order size: %d", packSizes, stock, orderSize), func(t *testing.T) {
+			comp := NewComputer()
+
+			packs, err := comp.ComputePacksWithStock(context.Background(), packSizes, stock, orderSize)
+
+			expectedItems, expectedPacks, ok := bruteForceStockItemsAndPacks(packSizes, stock, orderSize)
+			if !ok {
+				if !errors.Is(err, ErrInsufficientStock) {
+					t.Errorf("unexpected error: got '%v' want '%v'", err, ErrInsufficientStock)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assertPacksUseSizes(t, packs, packSizes)
+			assertPacksInStock(t, packs, stock)
+			items, numPacks := countItemsAndPacks(packs)
+			if items != expectedItems || numPacks != expectedPacks {
+				t.Errorf("unexpected items and packs: got '%d' items in '%d' packs want '%d' items in '%d' packs",
+					items, numPacks, expectedItems, expectedPacks)
+			}
+		})
+	}
+}
+
+func TestComputer_ComputeWithStock_InsufficientStock(t *testing.T) {
+	data := []struct {
+		packSizes []int
+		stock     map[int]int
+		orderSize int
+	}{
+		{
+			packSizes: []int{250, 500},
+			stock:     map[int]int{250: 1, 500: 1},
+			orderSize: 751,
+		},
+		{
+			packSizes: []int{250, 500},
+			stock:     map[int]int{250: 0, 500: 0},
+			orderSize: 1,
+		},
+	}
+
+	for _, d := range data {
+		t.Run(fmt.Sprintf("with stock: %v and order size: %d", d.stock, d.orderSize), func(t *testing.T) {
+			comp := NewComputer()
+
+			_, err := comp.ComputePacksWithStock(context.Background(), d.packSizes, d.stock, d.orderSize)
+
+			if !errors.Is(err, ErrInsufficientStock) {
+				t.Errorf("unexpected error: got '%v' want '%v'", err, ErrInsufficientStock)
+			}
+		})
+	}
+}
+
+func TestComputer_ComputeWithStock_InvalidInput(t *testing.T) {
+	comp := NewComputer()
+
+	_, err := comp.ComputePacksWithStock(context.Background(), []int{250, 500}, map[int]int{250: -1}, 1)
+
+	if !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("unexpected error: got '%v' want '%v'", err, ErrInvalidInput)
+	}
+}
+
+func TestComputer_ComputeWithStock_InputTooLarge(t *testing.T) {
+	comp := NewComputerWithMaxTableSize(1000)
+
+	_, err := comp.ComputePacksWithStock(context.Background(), []int{23, 31}, map[int]int{31: 1}, 990)
+
+	if !errors.Is(err, ErrInputTooLarge) {
+		t.Errorf("unexpected error: got '%v' want '%v'", err, ErrInputTooLarge)
+	}
+}
+
+func TestComputer_ComputeWithStock_InputTooLargeWithScratchRows(t *testing.T) {
+	comp := NewComputerWithMaxTableSize(1000)
+
+	// the quantities (2*303 ints) fit in 1000 totals (2000 ints), but not along with the scratch rows (7*303 ints)
+	_, err := comp.ComputePacksWithStock(context.Background(), []int{2, 3}, map[int]int{3: 1}, 300)
+
+	if !errors.Is(err, ErrInputTooLarge) {
+		t.Errorf("unexpected error: got '%v' want '%v'", err, ErrInputTooLarge)
+	}
+}
+
+func TestComputer_ComputeWithStock_Cancelled(t *testing.T) {
+	comp := NewComputer()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := comp.ComputePacksWithStock(ctx, []int{23, 31, 53}, map[int]int{53: 10}, 500000)
+
+	if !errors.Is(err, ErrCancelled) {
+		t.Errorf("unexpected error: got '%v' want '%v'", err, ErrCancelled)
+	}
+}
+
+// bruteForceStockItemsAndPacks is bruteForceItemsAndPacks within the stock. It returns false if the stock cannot
+// fulfill the order size.
+func bruteForceStockItemsAndPacks(packSizes []int, stock map[int]int, orderSize int) (int, int, bool) {
+	packSizes = slices.Clone(packSizes)
+	slices.Sort(packSizes)
+	packSizes = slices.Compact(packSizes)
+	limit := orderSize + slices.Max(packSizes)
+	bestItems, bestPacks := limit, 0
+
+	var search func(i, items, packs int)
+	search = func(i, items, packs int) {
+		if items >= limit {
+			return
+		}
+		if i == len(packSizes) {
+			if items >= orderSize && (items < bestItems || items == bestItems && packs < bestPacks) {
+				bestItems, bestPacks = items, packs
+			}
+			return
+		}
+		maxQuantity, limited := stock[packSizes[i]]
+		for quantity := 0; items+quantity*packSizes[i] < limit && (!limited || quantity <= maxQuantity); quantity++ {
+			search(i+1, items+quantity*packSizes[i], packs+quantity)
+		}
+	}
+	search(0, 0, 0)
+
+	return bestItems, bestPacks, bestItems < limit
+}
+
+func assertPacksInStock(t *testing.T, packs []Pack, stock map[int]int) {
+	for _, p := range packs {
+		if quantity, limited := stock[p.Size]; limited && p.Quantity > quantity {
+			t.Errorf("unexpected pack: got '%+v' for stock '%+v'", p, stock)
+		}
+	}
+}
+
+func TestInStock(t *testing.T) {
+	data := []struct {
+		stock    map[int]int
+		expected bool
+	}{
+		{
+			stock:    nil,
+			expected: true,
+		},
+		{
+			stock:    map[int]int{500: 1, 250: 2},
+			expected: true,
+		},
+		{
+			stock:    map[int]int{250: 1},
+			expected: false,
+		},
+	}
+
+	for _, d := range data {
+		t.Run(fmt.Sprintf("with stock: %v", d.stock), func(t *testing.T) {
+			inStock := InStock([]Pack{{Size: 500, Quantity: 1}, {Size: 250, Quantity: 2}}, d.stock)
+
+			if inStock != d.expected {
+				t.Errorf("unexpected in stock: got '%t' want '%t'", inStock, d.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package pack
+
+import (
+	"context"
+	"slices"
+	"strconv"
+)
+
+// Table is the solution table of some pack sizes: it answers the packs of every order size like ComputePacks, without
+// computing them again.
+// Past L*(L-1), being L the largest pack size divided by the pack sizes' greatest common divisor, the packs of an order
+// are the packs of the order size with the same residue modulo L in the table, plus packs of L
+// (see computeBoundedQuantityByPack), so a table of L*L totals answers any order size.
+type Table struct {
+	comp            *Computer
+	divisor         int
+	largestPackSize int
+	// usedPackSizes has the last reduced pack size used to reach every total, or 0 if the total cannot be reached.
+	usedPackSizes []int
+}
+
+// bytesPerTotal is the memory used by every total of a solution table, in bytes.
+const bytesPerTotal = strconv.IntSize / 8
+
+// TableBytes returns the memory the solution table of the pack sizes uses (see ComputeTable), in bytes, without
+// computing it.
+// It returns ErrInvalidInput, or ErrInputTooLarge if the table has more totals than the computer's maximum table size.
+func (comp *Computer) TableBytes(packSizes []int) (int, error) {
+	if !SizesValid(packSizes) {
+		return 0, ErrInvalidInput
+	}
+	size := comp.tableBound(slices.Max(packSizes) / comp.gcd(packSizes))
+	if size > comp.maxTableSize {
+		return 0, ErrInputTooLarge
+	}
+	return size * bytesPerTotal, nil
+}
+
+// ComputeTable computes the solution table of the pack sizes.
+// It returns ErrInvalidInput, ErrInputTooLarge if the table has more totals than the computer's maximum table size, or
+// ErrCancelled if the table cannot be computed.
+func (comp *Computer) ComputeTable(ctx context.Context, packSizes []int) (*Table, error) {
+	if !SizesValid(packSizes) {
+		return nil, ErrInvalidInput
+	}
+
+	divisor := comp.gcd(packSizes)
+	reducedPackSizes := slices.Compact(comp.cloneAndSort(comp.divideSizes(packSizes, divisor)))
+	largestPackSize := reducedPackSizes[len(reducedPackSizes)-1]
+	_, usedPackSizes, err := comp.computeMinPacksTable(ctx, reducedPackSizes, comp.tableBound(largestPackSize))
+	if err != nil {
+		return nil, err
+	}
+
+	return &Table{
+		comp:            comp,
+		divisor:         divisor,
+		largestPackSize: largestPackSize,
+		usedPackSizes:   usedPackSizes,
+	}, nil
+}
+
+// Bytes returns the memory used by the table, in bytes.
+func (t *Table) Bytes() int {
+	return len(t.usedPackSizes) * bytesPerTotal
+}
+
+// Packs returns the packs of the order size, sorted by DefaultSort, in a time that depends on the number of packs
+// smaller than the largest one and not on the order size.
+// It returns ErrInvalidInput if the order size is not greater than zero.
+func (t *Table) Packs(orderSize int) ([]Pack, error) {
+	if orderSize <= 0 {
+		return nil, ErrInvalidInput
+	}
+
+	reducedOrderSize := (orderSize-1)/t.divisor + 1
+	bound := len(t.usedPackSizes)
+	var reducedQuantityByPack map[int]int
+	if reducedOrderSize <= bound-t.largestPackSize {
+		total := reducedOrderSize
+		for t.usedPackSizes[total] == 0 {
+			total++
+		}
+		reducedQuantityByPack = t.comp.computeQuantityByUsedPackSizes(t.usedPackSizes, total)
+	} else {
+		remainder := bound - t.largestPackSize + reducedOrderSize%t.largestPackSize
+		reducedQuantityByPack = t.comp.computeQuantityByUsedPackSizes(t.usedPackSizes, remainder)
+		reducedQuantityByPack[t.largestPackSize] += (reducedOrderSize - remainder) / t.largestPackSize
+	}
+
+	quantityByPack := make(map[int]int, len(reducedQuantityByPack))
+	for packSize, quantity := range reducedQuantityByPack {
+		quantityByPack[packSize*t.divisor] = quantity
+	}
+	return t.comp.toPackModelSlice(quantityByPack), nil
+}
@@ -0,0 +1,151 @@
+package pack
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"slices"
+	"strconv"
+	"testing"
+)
+
+func TestTable_Packs_MatchesComputer(t *testing.T) {
+	rnd := rand.New(rand.NewSource(7))
+
+	for i := 0; i < 200; i++ {
+		packSizes := randomPackSizes(rnd, 5, 60)
+		for j := range packSizes {
+			packSizes[j] *= rnd.Intn(3) + 1
+		}
+		orderSizes := []int{1, rnd.Intn(500) + 1, rnd.Intn(100_000) + 1, rnd.Intn(1_000_000_000) + 1}
+
+		t.Run(fmt.Sprintf("with pack sizes: %v", packSizes), func(t *testing.T) {
+			comp := NewComputer()
+			table, err := comp.ComputeTable(context.Background(), packSizes)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for _, orderSize := range orderSizes {
+				expectedPacks, err := comp.ComputePacks(context.Background(), packSizes, orderSize)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				packs, err := table.Packs(orderSize)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if !slices.Equal(packs, expectedPacks) {
+					t.Errorf("unexpected packs of order size %d: got '%+v' want '%+v'", orderSize, packs, expectedPacks)
+				}
+			}
+		})
+	}
+}
+
+func TestTable_Packs_InvalidInput(t *testing.T) {
+	comp := NewComputer()
+	table, err := comp.ComputeTable(context.Background(), []int{250, 500})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = table.Packs(0)
+
+	if !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("unexpected error: got '%v' want '%v'", err, ErrInvalidInput)
+	}
+}
+
+func TestTable_Bytes(t *testing.T) {
+	comp := NewComputer()
+	table, err := comp.ComputeTable(context.Background(), []int{23, 31, 53})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedBytes := 53 * 53 * strconv.IntSize / 8
+	if table.Bytes() != expectedBytes {
+		t.Errorf("unexpected bytes: got '%d' want '%d'", table.Bytes(), expectedBytes)
+	}
+}
+
+func TestComputer_TableBytes(t *testing.T) {
+	data := []struct {
+		packSizes     []int
+		expectedBytes int
+	}{
+		{
+			packSizes:     []int{250, 500, 1000, 2000, 5000},
+			expectedBytes: 400 * strconv.IntSize / 8,
+		},
+		{
+			packSizes:     []int{23, 31, 53},
+			expectedBytes: 2809 * strconv.IntSize / 8,
+		},
+		{
+			packSizes:     []int{250},
+			expectedBytes: strconv.IntSize / 8,
+		},
+	}
+
+	for _, d := range data {
+		t.Run(fmt.Sprintf("with pack sizes: %v", d.packSizes), func(t *testing.T) {
+			comp := NewComputer()
+
+			bytes, err := comp.TableBytes(d.packSizes)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if bytes != d.expectedBytes {
+				t.Errorf("unexpected bytes: got '%d' want '%d'", bytes, d.expectedBytes)
+			}
+		})
+	}
+}
+
+func TestComputer_TableBytes_InputTooLarge(t *testing.T) {
+	comp := NewComputerWithMaxTableSize(1000)
+
+	_, err := comp.TableBytes([]int{23, 31, 53})
+
+	if !errors.Is(err, ErrInputTooLarge) {
+		t.Errorf("unexpected error: got '%v' want '%v'", err, ErrInputTooLarge)
+	}
+}
+
+func TestComputer_ComputeTable_InvalidInput(t *testing.T) {
+	comp := NewComputer()
+
+	_, err := comp.ComputeTable(context.Background(), []int{})
+
+	if !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("unexpected error: got '%v' want '%v'", err, ErrInvalidInput)
+	}
+}
+
+func TestComputer_ComputeTable_InputTooLarge(t *testing.T) {
+	comp := NewComputerWithMaxTableSize(1000)
+
+	_, err := comp.ComputeTable(context.Background(), []int{23, 31, 53})
+
+	if !errors.Is(err, ErrInputTooLarge) {
+		t.Errorf("unexpected error: got '%v' want '%v'", err, ErrInputTooLarge)
+	}
+}
+
+func TestComputer_ComputeTable_Cancelled(t *testing.T) {
+	comp := NewComputer()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := comp.ComputeTable(ctx, []int{23, 31, 53})
+
+	if !errors.Is(err, ErrCancelled) {
+		t.Errorf("unexpected error: got '%v' want '%v'", err, ErrCancelled)
+	}
+}
@@ -0,0 +1,202 @@
+package pack
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"math"
+	"slices"
+)
+
+// The names of the tie-breakers (see SortTies).
+const (
+	// TieBreakerLargerPacks prefers more packs of the larger pack sizes.
+	TieBreakerLargerPacks = "larger_packs"
+	// TieBreakerSmallerPacks prefers more packs of the smaller pack sizes.
+	TieBreakerSmallerPacks = "smaller_packs"
+	// TieBreakerFewerSizes prefers fewer distinct pack sizes and then larger packs.
+	TieBreakerFewerSizes = "fewer_sizes"
+	// DefaultTieBreaker is the tie-breaker used unless another one is chosen.
+	DefaultTieBreaker = TieBreakerLargerPacks
+)
+
+// TieBreakerValid returns true if name is empty, meaning DefaultTieBreaker, or the name of a tie-breaker, false
+// otherwise.
+func TieBreakerValid(name string) bool {
+	switch name {
+	case "", TieBreakerLargerPacks, TieBreakerSmallerPacks, TieBreakerFewerSizes:
+		return true
+	default:
+		return false
+	}
+}
+
+// SortTies sorts the tied packs by the tie-breaker, best first. The packs must be sorted by size, largest first, like
+// the ones returned by ComputeTies, and the tie-breaker must be valid.
+func SortTies(ties [][]Pack, tieBreaker string) {
+	slices.SortStableFunc(ties, func(a, b []Pack) int {
+		switch tieBreaker {
+		case TieBreakerSmallerPacks:
+			return compareLargerPacks(b, a)
+		case TieBreakerFewerSizes:
+			if len(a) != len(b) {
+				return cmp.Compare(len(a), len(b))
+			}
+			return compareLargerPacks(a, b)
+		default:
+			return compareLargerPacks(a, b)
+		}
+	})
+}
+
+// compareLargerPacks compares the packs by the quantity of every pack size, largest first: the packs with more of the
+// first different one come first.
+func compareLargerPacks(a, b []Pack) int {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i].Size != b[i].Size {
+			return cmp.Compare(b[i].Size, a[i].Size)
+		}
+		if a[i].Quantity != b[i].Quantity {
+			return cmp.Compare(b[i].Quantity, a[i].Quantity)
+		}
+	}
+	return cmp.Compare(len(b), len(a))
+}
+
+// ComputeTies computes the ties of the packs computed by the computer, see ComputeTies.
+func (comp *Computer) ComputeTies(ctx context.Context, packSizes []int, stock map[int]int, packs []Pack, objective Objective, limit int) ([][]Pack, error) {
+	return ComputeTies(ctx, packSizes, stock, packs, objective, limit)
+}
+
+// ComputeTies computes the ties of the packs computed by the solver, see ComputeTies.
+func (bb *BranchAndBound) ComputeTies(ctx context.Context, packSizes []int, stock map[int]int, packs []Pack, objective Objective, limit int) ([][]Pack, error) {
+	return ComputeTies(ctx, packSizes, stock, packs, objective, limit)
+}
+
+// ComputeTies computes up to limit combinations of the pack sizes, within the stock, that tie with the packs by the
+// objective: the same items, number of packs and weight. The packs themselves are one of them.
+// They are searched depth first with the larger pack sizes first and the most packs of each first, so they are
+// returned by TieBreakerLargerPacks, each with its packs sorted by size, largest first.
+// It returns ErrInvalidInput, ErrInputTooLarge if the search visits more than DefaultMaxNodes nodes, or ErrCancelled
+// if the ties cannot be computed.
+func ComputeTies(ctx context.Context, packSizes []int, stock map[int]int, packs []Pack, objective Objective, limit int) ([][]Pack, error) {
+	if !SizesValid(packSizes) || !StockValid(stock) || len(packs) == 0 {
+		return nil, ErrInvalidInput
+	}
+
+	sortedPackSizes := slices.Clone(packSizes)
+	slices.Sort(sortedPackSizes)
+	sortedPackSizes = slices.Compact(sortedPackSizes)
+	slices.Reverse(sortedPackSizes)
+
+	s := tiesSearch{
+		ctx:        ctx,
+		packSizes:  sortedPackSizes,
+		bounds:     make([]int, len(sortedPackSizes)),
+		weights:    make([]int, len(sortedPackSizes)),
+		quantities: make([]int, len(sortedPackSizes)),
+		limit:      limit,
+	}
+	for i, packSize := range sortedPackSizes {
+		s.bounds[i] = math.MaxInt
+		if quantity, limited := stock[packSize]; limited {
+			s.bounds[i] = quantity
+		}
+		s.weights[i] = objective.Weight(packSize)
+	}
+
+	var target Candidate
+	for _, p := range packs {
+		if !slices.Contains(sortedPackSizes, p.Size) || p.Quantity <= 0 {
+			return nil, ErrInvalidInput
+		}
+		target.Items += p.Size * p.Quantity
+		target.Packs += p.Quantity
+		target.Weight += objective.Weight(p.Size) * p.Quantity
+	}
+
+	if err := s.search(0, target); err != nil {
+		return nil, err
+	}
+	return s.ties, nil
+}
+
+// tiesSearch is the state of a search for the ties of some packs.
+type tiesSearch struct {
+	ctx        context.Context
+	packSizes  []int
+	bounds     []int
+	weights    []int
+	quantities []int
+	ties       [][]Pack
+	nodes      int
+	limit      int
+}
+
+// search tries every quantity of the pack size i, most first, that leaves the rest of the target within reach of the
+// smaller pack sizes.
+func (s *tiesSearch) search(i int, rest Candidate) error {
+	if len(s.ties) == s.limit {
+		return nil
+	}
+	if i == len(s.packSizes) {
+		if rest == (Candidate{}) {
+			s.ties = append(s.ties, s.packs())
+		}
+		return nil
+	}
+
+	// checked on the first node too, so that an already cancelled context never searches
+	if s.nodes%cancelCheckInterval == 0 && s.ctx.Err() != nil {
+		return fmt.Errorf("%w: %w", ErrCancelled, s.ctx.Err())
+	}
+	s.nodes++
+	if s.nodes > DefaultMaxNodes {
+		return ErrInputTooLarge
+	}
+
+	// the rest packs ship at least as many items as if all of them were of the smallest pack size
+	if rest.Items < rest.Packs*s.packSizes[len(s.packSizes)-1] {
+		return nil
+	}
+
+	packSize, weight := s.packSizes[i], s.weights[i]
+	maxQuantity := min(rest.Items/packSize, rest.Packs, s.bounds[i])
+	if weight > 0 {
+		maxQuantity = min(maxQuantity, rest.Weight/weight)
+	}
+	minQuantity := 0
+	// the smallest pack size takes all the rest packs
+	if i+1 == len(s.packSizes) {
+		minQuantity = rest.Packs
+	}
+	for quantity := maxQuantity; quantity >= minQuantity; quantity-- {
+		next := Candidate{
+			Items:  rest.Items - quantity*packSize,
+			Packs:  rest.Packs - quantity,
+			Weight: rest.Weight - quantity*weight,
+		}
+		// fewer packs of this size leave even more items for the smaller ones
+		if i+1 < len(s.packSizes) && next.Items > next.Packs*s.packSizes[i+1] {
+			break
+		}
+
+		s.quantities[i] = quantity
+		if err := s.search(i+1, next); err != nil {
+			return err
+		}
+	}
+	s.quantities[i] = 0
+	return nil
+}
+
+// packs returns the packs of the current quantities, largest first.
+func (s *tiesSearch) packs() []Pack {
+	var result []Pack
+	for i, packSize := range s.packSizes {
+		if s.quantities[i] > 0 {
+			result = append(result, Pack{Size: packSize, Quantity: s.quantities[i]})
+		}
+	}
+	return result
+}
@@ -0,0 +1,223 @@
+package pack
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+func TestComputeTies_Success(t *testing.T) {
+	data := []struct {
+		description  string
+		packSizes    []int
+		stock        map[int]int
+		packs        []Pack
+		objective    Objective
+		limit        int
+		expectedTies [][]Pack
+	}{
+		{
+			description: "items then packs",
+			packSizes:   []int{2, 3, 4},
+			packs:       []Pack{{Size: 3, Quantity: 2}},
+			objective:   ItemsThenPacks{},
+			limit:       10,
+			expectedTies: [][]Pack{
+				{{Size: 4, Quantity: 1}, {Size: 2, Quantity: 1}},
+				{{Size: 3, Quantity: 2}},
+			},
+		},
+		{
+			description:  "limit",
+			packSizes:    []int{2, 3, 4},
+			packs:        []Pack{{Size: 3, Quantity: 2}},
+			objective:    ItemsThenPacks{},
+			limit:        1,
+			expectedTies: [][]Pack{{{Size: 4, Quantity: 1}, {Size: 2, Quantity: 1}}},
+		},
+		{
+			description:  "stock",
+			packSizes:    []int{2, 3, 4},
+			stock:        map[int]int{4: 0},
+			packs:        []Pack{{Size: 3, Quantity: 2}},
+			objective:    ItemsThenPacks{},
+			limit:        10,
+			expectedTies: [][]Pack{{{Size: 3, Quantity: 2}}},
+		},
+		{
+			description:  "cost",
+			packSizes:    []int{2, 3, 4},
+			packs:        []Pack{{Size: 4, Quantity: 1}, {Size: 2, Quantity: 1}},
+			objective:    Cost{UnitCosts: map[int]int{2: 1, 3: 5, 4: 1}},
+			limit:        10,
+			expectedTies: [][]Pack{{{Size: 4, Quantity: 1}, {Size: 2, Quantity: 1}}},
+		},
+		{
+			description: "large order",
+			packSizes:   []int{23, 31, 53},
+			packs:       []Pack{{Size: 53, Quantity: 37735846}, {Size: 31, Quantity: 3}, {Size: 23, Quantity: 3}},
+			objective:   ItemsThenPacks{},
+			limit:       10,
+			expectedTies: [][]Pack{
+				{{Size: 53, Quantity: 37735846}, {Size: 31, Quantity: 3}, {Size: 23, Quantity: 3}},
+			},
+		},
+	}
+
+	for _, d := range data {
+		t.Run(fmt.Sprintf("with %s", d.description), func(t *testing.T) {
+			ties, err := ComputeTies(context.Background(), d.packSizes, d.stock, d.packs, d.objective, d.limit)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(ties, d.expectedTies) {
+				t.Errorf("unexpected ties: got '%+v' want '%+v'", ties, d.expectedTies)
+			}
+		})
+	}
+}
+
+func TestComputeTies_MatchesBruteForceOracle(t *testing.T) {
+	rnd := rand.New(rand.NewSource(6))
+
+	for i := 0; i < 200; i++ {
+		packSizes := randomPackSizes(rnd, 5, 30)
+		orderSize := rnd.Intn(300) + 1
+
+		t.Run(fmt.Sprintf("with pack sizes: %v and order size: %d", packSizes, orderSize), func(t *testing.T) {
+			comp := NewComputer()
+			packs, err := comp.ComputePacks(context.Background(), packSizes, orderSize)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			ties, err := ComputeTies(context.Background(), packSizes, nil, packs, ItemsThenPacks{}, 1000)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			items, numPacks := countItemsAndPacks(packs)
+			expectedCount := bruteForceTieCount(packSizes, items, numPacks)
+			if len(ties) != expectedCount {
+				t.Errorf("unexpected number of ties: got '%d' want '%d'", len(ties), expectedCount)
+			}
+			if !slices.ContainsFunc(ties, func(tie []Pack) bool { return EqualSlice(tie, packs) }) {
+				t.Errorf("unexpected ties: got '%+v' without the packs '%+v'", ties, packs)
+			}
+			for _, tie := range ties {
+				assertPacksUseSizes(t, tie, packSizes)
+				tieItems, tiePacks := countItemsAndPacks(tie)
+				if tieItems != items || tiePacks != numPacks {
+					t.Errorf("unexpected tie: got '%+v' want '%d' items in '%d' packs", tie, items, numPacks)
+				}
+			}
+		})
+	}
+}
+
+func TestComputeTies_InvalidInput(t *testing.T) {
+	_, err := ComputeTies(context.Background(), []int{250, 500}, nil, []Pack{{Size: 1000, Quantity: 1}}, ItemsThenPacks{}, 10)
+
+	if !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("unexpected error: got '%v' want '%v'", err, ErrInvalidInput)
+	}
+}
+
+func TestComputeTies_Cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := ComputeTies(ctx, []int{250, 500}, nil, []Pack{{Size: 500, Quantity: 1}}, ItemsThenPacks{}, 10)
+
+	if !errors.Is(err, ErrCancelled) {
+		t.Errorf("unexpected error: got '%v' want '%v'", err, ErrCancelled)
+	}
+}
+
+func TestSortTies(t *testing.T) {
+	data := []struct {
+		tieBreaker   string
+		expectedTies [][]Pack
+	}{
+		{
+			tieBreaker: TieBreakerLargerPacks,
+			expectedTies: [][]Pack{
+				{{Size: 6, Quantity: 1}, {Size: 2, Quantity: 1}, {Size: 1, Quantity: 1}},
+				{{Size: 5, Quantity: 1}, {Size: 2, Quantity: 2}},
+				{{Size: 3, Quantity: 3}},
+			},
+		},
+		{
+			tieBreaker: TieBreakerSmallerPacks,
+			expectedTies: [][]Pack{
+				{{Size: 3, Quantity: 3}},
+				{{Size: 5, Quantity: 1}, {Size: 2, Quantity: 2}},
+				{{Size: 6, Quantity: 1}, {Size: 2, Quantity: 1}, {Size: 1, Quantity: 1}},
+			},
+		},
+		{
+			tieBreaker: TieBreakerFewerSizes,
+			expectedTies: [][]Pack{
+				{{Size: 3, Quantity: 3}},
+				{{Size: 5, Quantity: 1}, {Size: 2, Quantity: 2}},
+				{{Size: 6, Quantity: 1}, {Size: 2, Quantity: 1}, {Size: 1, Quantity: 1}},
+			},
+		},
+	}
+
+	for _, d := range data {
+		t.Run(fmt.Sprintf("with tie-breaker: %s", d.tieBreaker), func(t *testing.T) {
+			ties := [][]Pack{
+				{{Size: 5, Quantity: 1}, {Size: 2, Quantity: 2}},
+				{{Size: 3, Quantity: 3}},
+				{{Size: 6, Quantity: 1}, {Size: 2, Quantity: 1}, {Size: 1, Quantity: 1}},
+			}
+
+			SortTies(ties, d.tieBreaker)
+
+			if !reflect.DeepEqual(ties, d.expectedTies) {
+				t.Errorf("unexpected ties: got '%+v' want '%+v'", ties, d.expectedTies)
+			}
+		})
+	}
+}
+
+func TestTieBreakerValid(t *testing.T) {
+	for _, name := range []string{"", TieBreakerLargerPacks, TieBreakerSmallerPacks, TieBreakerFewerSizes} {
+		if !TieBreakerValid(name) {
+			t.Errorf("unexpected invalid tie-breaker: '%s'", name)
+		}
+	}
+	if TieBreakerValid("random") {
+		t.Errorf("unexpected valid tie-breaker: 'random'")
+	}
+}
+
+// bruteForceTieCount returns the number of combinations of the pack sizes with exactly the items and packs.
+func bruteForceTieCount(packSizes []int, items, numPacks int) int {
+	packSizes = slices.Clone(packSizes)
+	slices.Sort(packSizes)
+	packSizes = slices.Compact(packSizes)
+
+	count := 0
+	var search func(i, restItems, restPacks int)
+	search = func(i, restItems, restPacks int) {
+		if i == len(packSizes) {
+			if restItems == 0 && restPacks == 0 {
+				count++
+			}
+			return
+		}
+		for quantity := 0; quantity*packSizes[i] <= restItems && quantity <= restPacks; quantity++ {
+			search(i+1, restItems-quantity*packSizes[i], restPacks-quantity)
+		}
+	}
+	search(0, items, numPacks)
+
+	return count
+}
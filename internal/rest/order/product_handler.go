@@ -0,0 +1,237 @@
+package order
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"packer/internal/rest/order/pack"
+	"packer/internal/rest/order/repository"
+	"regexp"
+	"strings"
+)
+
+const ProductsPath = "/products"
+
+// skuPattern keeps SKUs usable as a path segment.
+var skuPattern = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+type ProductRepository interface {
+	CreateProduct(ctx context.Context, product repository.Product, cfg repository.Config) (repository.Product, error)
+	FindProduct(ctx context.Context, sku string) (repository.Product, error)
+	FindProducts(ctx context.Context) ([]repository.Product, error)
+	UpdateProduct(ctx context.Context, product repository.Product) (repository.Product, error)
+	DeleteProduct(ctx context.Context, sku string) error
+}
+
+// ProductHandler handles the products and their configs, which are handled like the default product's config of the
+// order handler (e.g. "/products/{sku}/config" like "/orders/config").
+type ProductHandler struct {
+	handler    *Handler
+	repository ProductRepository
+}
+
+func NewProductHandler(handler *Handler, repository ProductRepository) ProductHandler {
+	return ProductHandler{
+		handler:    handler,
+		repository: repository,
+	}
+}
+
+func (h *ProductHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	setHeaders(w) // could be more granular
+
+	sku, subPath := parseProductPath(r.URL.Path)
+
+	switch {
+	case sku == "" && r.Method == http.MethodGet:
+		h.handleListProducts(w, r)
+	case sku == "" && r.Method == http.MethodPost:
+		h.handleCreateProduct(w, r)
+	case sku == "":
+		// no other methods on the products' collection
+	case subPath == "" && r.Method == http.MethodGet:
+		h.handleGetProduct(w, r, sku)
+	case subPath == "" && r.Method == http.MethodPut:
+		h.handleUpdateProduct(w, r, sku)
+	case subPath == "" && r.Method == http.MethodDelete:
+		h.handleDeleteProduct(w, r, sku)
+	case subPath != "" && r.Method != http.MethodOptions:
+		h.handleProductConfig(w, r, sku, subPath)
+	}
+}
+
+// parseProductPath parses the SKU and the path after it (e.g. "tea" and "/config" for "/tea/config").
+func parseProductPath(path string) (string, string) {
+	path = strings.TrimPrefix(strings.TrimPrefix(path, ProductsPath), "/")
+	sku, subPath, found := strings.Cut(path, "/")
+	if found {
+		subPath = "/" + subPath
+	}
+	return sku, subPath
+}
+
+// handleProductConfig handles the config paths of the product, if it exists.
+func (h *ProductHandler) handleProductConfig(w http.ResponseWriter, r *http.Request, sku, subPath string) {
+	ctx := r.Context()
+
+	if _, err := h.repository.FindProduct(ctx, sku); err != nil {
+		h.writeFindProductErrorResponse(ctx, w, err)
+		return
+	}
+
+	switch {
+	case r.Method == http.MethodPost && isRestoreConfigVersionPath(subPath):
+		h.handler.handleRestoreConfigVersion(w, r, sku)
+	case r.Method == http.MethodPut && subPath == configPath:
+		h.handler.handleSetConfig(w, r, sku)
+	case r.Method == http.MethodGet && subPath == configPath:
+		h.handler.handleGetConfig(w, r, sku)
+	case r.Method == http.MethodGet && isConfigVersionsPath(subPath):
+		h.handler.handleListConfigVersions(w, r, sku)
+	}
+}
+
+func (h *ProductHandler) handleListProducts(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	products, err := h.repository.FindProducts(ctx)
+	if err != nil {
+		slog.ErrorContext(ctx, "unable to find the products", "error", err)
+		h.handler.writeInternalServerErrorResponse(w)
+		return
+	}
+
+	h.writeJSONResponse(ctx, w, newProducts(products))
+}
+
+// handleCreateProduct creates the product with the first version of its config.
+func (h *ProductHandler) handleCreateProduct(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var productReq CreateProductRequest
+	if err := json.NewDecoder(r.Body).Decode(&productReq); err != nil {
+		h.handler.writeBadRequestResponse(w, errRespInvalidPayload)
+		return
+	}
+
+	if !skuPattern.MatchString(productReq.SKU) {
+		h.handler.writeBadRequestResponse(w, errRespInvalidSKU)
+		return
+	}
+
+	if strings.TrimSpace(productReq.Name) == "" {
+		h.handler.writeBadRequestResponse(w, errRespInvalidProductName)
+		return
+	}
+
+	if !pack.SizesValid(productReq.PackSizes) {
+		h.handler.writeBadRequestResponse(w, errRespInvalidPackSizes)
+		return
+	}
+
+	product := repository.Product{
+		SKU:  productReq.SKU,
+		Name: productReq.Name,
+	}
+	cfg := repository.Config{
+		PackSizes: pack.RemoveDuplicateSizes(productReq.PackSizes),
+		Author:    productReq.Author,
+		Reason:    productReq.Reason,
+	}
+	if cfg.Author == "" {
+		cfg.Author = defaultConfigAuthor
+	}
+
+	product, err := h.repository.CreateProduct(ctx, product, cfg)
+	if errors.Is(err, repository.ErrAlreadyExists) {
+		h.handler.writeErrorResponse(w, errRespProductAlreadyExists, http.StatusConflict)
+		return
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "unable to create the product", "error", err)
+		h.handler.writeInternalServerErrorResponse(w)
+		return
+	}
+
+	h.writeJSONResponse(ctx, w, newProduct(product))
+}
+
+func (h *ProductHandler) handleGetProduct(w http.ResponseWriter, r *http.Request, sku string) {
+	ctx := r.Context()
+
+	product, err := h.repository.FindProduct(ctx, sku)
+	if err != nil {
+		h.writeFindProductErrorResponse(ctx, w, err)
+		return
+	}
+
+	h.writeJSONResponse(ctx, w, newProduct(product))
+}
+
+func (h *ProductHandler) handleUpdateProduct(w http.ResponseWriter, r *http.Request, sku string) {
+	ctx := r.Context()
+
+	var productReq UpdateProductRequest
+	if err := json.NewDecoder(r.Body).Decode(&productReq); err != nil {
+		h.handler.writeBadRequestResponse(w, errRespInvalidPayload)
+		return
+	}
+
+	if strings.TrimSpace(productReq.Name) == "" {
+		h.handler.writeBadRequestResponse(w, errRespInvalidProductName)
+		return
+	}
+
+	product, err := h.repository.UpdateProduct(ctx, repository.Product{SKU: sku, Name: productReq.Name})
+	if err != nil {
+		h.writeFindProductErrorResponse(ctx, w, err)
+		return
+	}
+
+	h.writeJSONResponse(ctx, w, newProduct(product))
+}
+
+// handleDeleteProduct deletes the product, unless it is the default product or it was ordered.
+func (h *ProductHandler) handleDeleteProduct(w http.ResponseWriter, r *http.Request, sku string) {
+	ctx := r.Context()
+
+	if sku == repository.DefaultProduct {
+		h.handler.writeErrorResponse(w, errRespDefaultProduct, http.StatusConflict)
+		return
+	}
+
+	err := h.repository.DeleteProduct(ctx, sku)
+	if errors.Is(err, repository.ErrInUse) {
+		h.handler.writeErrorResponse(w, errRespProductInUse, http.StatusConflict)
+		return
+	}
+	if err != nil {
+		h.writeFindProductErrorResponse(ctx, w, err)
+		return
+	}
+
+	h.handler.invalidateCache(sku)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *ProductHandler) writeFindProductErrorResponse(ctx context.Context, w http.ResponseWriter, err error) {
+	if errors.Is(err, repository.ErrNotFound) {
+		h.handler.writeErrorResponse(w, errRespProductNotFound, http.StatusNotFound)
+		return
+	}
+	slog.ErrorContext(ctx, "unable to find the product", "error", err)
+	h.handler.writeInternalServerErrorResponse(w)
+}
+
+func (h *ProductHandler) writeJSONResponse(ctx context.Context, w http.ResponseWriter, v any) {
+	jsonBytes, err := json.Marshal(v)
+	if err != nil {
+		slog.ErrorContext(ctx, "unable to encode the response", "error", err)
+		h.handler.writeInternalServerErrorResponse(w)
+		return
+	}
+
+	h.handler.writeMessageWithStatusResponse(w, jsonBytes, http.StatusOK)
+}
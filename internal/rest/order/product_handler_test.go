@@ -0,0 +1,344 @@
+package order
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"packer/internal/rest/order/repository"
+	"slices"
+	"testing"
+	"time"
+)
+
+// TestProductRepository records the passed products and returns productResult, productsResult and err.
+type TestProductRepository struct {
+	passedProduct  repository.Product
+	passedCfg      repository.Config
+	passedSKU      string
+	productResult  repository.Product
+	productsResult []repository.Product
+	err            error
+}
+
+func (repo *TestProductRepository) CreateProduct(_ context.Context, product repository.Product, cfg repository.Config) (repository.Product, error) {
+	repo.passedProduct = product
+	repo.passedCfg = cfg
+	product.CreatedAt = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	return product, repo.err
+}
+
+func (repo *TestProductRepository) FindProduct(_ context.Context, sku string) (repository.Product, error) {
+	repo.passedSKU = sku
+	return repo.productResult, repo.err
+}
+
+func (repo *TestProductRepository) FindProducts(_ context.Context) ([]repository.Product, error) {
+	return repo.productsResult, repo.err
+}
+
+func (repo *TestProductRepository) UpdateProduct(_ context.Context, product repository.Product) (repository.Product, error) {
+	repo.passedProduct = product
+	product.CreatedAt = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	return product, repo.err
+}
+
+func (repo *TestProductRepository) DeleteProduct(_ context.Context, sku string) error {
+	repo.passedSKU = sku
+	return repo.err
+}
+
+func TestProductHandler_HandleListProducts_Success(t *testing.T) {
+	productRepo := TestProductRepository{
+		productsResult: []repository.Product{
+			{
+				SKU:       "default",
+				Name:      "Default product",
+				CreatedAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			},
+			{
+				SKU:       "tea",
+				Name:      "Tea",
+				CreatedAt: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC),
+			},
+		},
+	}
+	handler := newTestProductHandler(&TestSuccessRepository{}, &productRepo)
+
+	rr := httptest.NewRecorder()
+	req := newProductRequest(t, http.MethodGet, "", "")
+
+	handler.ServeHTTP(rr, req)
+
+	assertStatusOk(t, rr)
+	assertBody(t, rr, `{"products":[`+
+		`{"sku":"default","name":"Default product","created_at":"2024-01-01T00:00:00Z"},`+
+		`{"sku":"tea","name":"Tea","created_at":"2024-01-02T00:00:00Z"}]}`)
+}
+
+func TestProductHandler_HandleCreateProduct_Success(t *testing.T) {
+	productRepo := TestProductRepository{}
+	handler := newTestProductHandler(&TestSuccessRepository{}, &productRepo)
+
+	rr := httptest.NewRecorder()
+	req := newProductRequest(t, http.MethodPost, "", `{"sku": "tea", "name": "Tea", "pack_sizes": [10, 10, 50]}`)
+
+	handler.ServeHTTP(rr, req)
+
+	assertStatusOk(t, rr)
+	assertBody(t, rr, `{"sku":"tea","name":"Tea","created_at":"2024-01-01T00:00:00Z"}`)
+	if productRepo.passedProduct.SKU != "tea" || productRepo.passedProduct.Name != "Tea" {
+		t.Errorf("unexpected product passed to repository: got '%+v'", productRepo.passedProduct)
+	}
+	slices.Sort(productRepo.passedCfg.PackSizes)
+	if !slices.Equal(productRepo.passedCfg.PackSizes, []int{10, 50}) {
+		t.Errorf("unexpected config pack sizes: got '%+v' want '%+v'", productRepo.passedCfg.PackSizes, []int{10, 50})
+	}
+	if productRepo.passedCfg.Author != defaultConfigAuthor {
+		t.Errorf("unexpected config author: got '%s' want '%s'", productRepo.passedCfg.Author, defaultConfigAuthor)
+	}
+}
+
+func TestProductHandler_HandleCreateProduct_BadRequest(t *testing.T) {
+	data := []struct {
+		payload         string
+		expectedErrCode string
+	}{
+		{
+			payload:         "{sku}",
+			expectedErrCode: "invalid_payload",
+		},
+		{
+			payload:         `{"sku": "", "name": "Tea", "pack_sizes": [10]}`,
+			expectedErrCode: "invalid_sku",
+		},
+		{
+			payload:         `{"sku": "green/tea", "name": "Tea", "pack_sizes": [10]}`,
+			expectedErrCode: "invalid_sku",
+		},
+		{
+			payload:         `{"sku": "tea", "name": " ", "pack_sizes": [10]}`,
+			expectedErrCode: "invalid_product_name",
+		},
+		{
+			payload:         `{"sku": "tea", "name": "Tea", "pack_sizes": []}`,
+			expectedErrCode: "invalid_pack_sizes",
+		},
+	}
+
+	for _, d := range data {
+		t.Run(fmt.Sprintf("with payload: '%s'", d.payload), func(t *testing.T) {
+			handler := newTestProductHandler(&TestSuccessRepository{}, &TestProductRepository{})
+
+			rr := httptest.NewRecorder()
+			req := newProductRequest(t, http.MethodPost, "", d.payload)
+
+			handler.ServeHTTP(rr, req)
+
+			assertErrorResponse(t, rr, http.StatusBadRequest, d.expectedErrCode)
+		})
+	}
+}
+
+func TestProductHandler_HandleCreateProduct_AlreadyExists(t *testing.T) {
+	productRepo := TestProductRepository{err: repository.ErrAlreadyExists}
+	handler := newTestProductHandler(&TestSuccessRepository{}, &productRepo)
+
+	rr := httptest.NewRecorder()
+	req := newProductRequest(t, http.MethodPost, "", `{"sku": "tea", "name": "Tea", "pack_sizes": [10]}`)
+
+	handler.ServeHTTP(rr, req)
+
+	assertErrorResponse(t, rr, http.StatusConflict, "product_already_exists")
+}
+
+func TestProductHandler_HandleGetProduct_Success(t *testing.T) {
+	productRepo := TestProductRepository{
+		productResult: repository.Product{
+			SKU:       "tea",
+			Name:      "Tea",
+			CreatedAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		},
+	}
+	handler := newTestProductHandler(&TestSuccessRepository{}, &productRepo)
+
+	rr := httptest.NewRecorder()
+	req := newProductRequest(t, http.MethodGet, "/tea", "")
+
+	handler.ServeHTTP(rr, req)
+
+	assertStatusOk(t, rr)
+	assertBody(t, rr, `{"sku":"tea","name":"Tea","created_at":"2024-01-01T00:00:00Z"}`)
+	if productRepo.passedSKU != "tea" {
+		t.Errorf("unexpected sku passed to repository: got '%s' want '%s'", productRepo.passedSKU, "tea")
+	}
+}
+
+func TestProductHandler_HandleGetProduct_Errors(t *testing.T) {
+	data := []struct {
+		err             error
+		expectedStatus  int
+		expectedErrCode string
+	}{
+		{
+			err:             repository.ErrNotFound,
+			expectedStatus:  http.StatusNotFound,
+			expectedErrCode: "product_not_found",
+		},
+		{
+			err:             errors.New("test error"),
+			expectedStatus:  http.StatusInternalServerError,
+			expectedErrCode: "internal_server_error",
+		},
+	}
+
+	for _, d := range data {
+		t.Run(fmt.Sprintf("with error: %v", d.err), func(t *testing.T) {
+			handler := newTestProductHandler(&TestSuccessRepository{}, &TestProductRepository{err: d.err})
+
+			rr := httptest.NewRecorder()
+			req := newProductRequest(t, http.MethodGet, "/tea", "")
+
+			handler.ServeHTTP(rr, req)
+
+			assertErrorResponse(t, rr, d.expectedStatus, d.expectedErrCode)
+		})
+	}
+}
+
+func TestProductHandler_HandleUpdateProduct_Success(t *testing.T) {
+	productRepo := TestProductRepository{}
+	handler := newTestProductHandler(&TestSuccessRepository{}, &productRepo)
+
+	rr := httptest.NewRecorder()
+	req := newProductRequest(t, http.MethodPut, "/tea", `{"name": "Green tea"}`)
+
+	handler.ServeHTTP(rr, req)
+
+	assertStatusOk(t, rr)
+	assertBody(t, rr, `{"sku":"tea","name":"Green tea","created_at":"2024-01-01T00:00:00Z"}`)
+}
+
+func TestProductHandler_HandleDeleteProduct(t *testing.T) {
+	data := []struct {
+		sku             string
+		err             error
+		expectedStatus  int
+		expectedErrCode string
+	}{
+		{
+			sku:            "tea",
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			sku:             repository.DefaultProduct,
+			expectedStatus:  http.StatusConflict,
+			expectedErrCode: "default_product",
+		},
+		{
+			sku:             "tea",
+			err:             repository.ErrInUse,
+			expectedStatus:  http.StatusConflict,
+			expectedErrCode: "product_in_use",
+		},
+		{
+			sku:             "tea",
+			err:             repository.ErrNotFound,
+			expectedStatus:  http.StatusNotFound,
+			expectedErrCode: "product_not_found",
+		},
+	}
+
+	for _, d := range data {
+		t.Run(fmt.Sprintf("with sku '%s' and error: %v", d.sku, d.err), func(t *testing.T) {
+			handler := newTestProductHandler(&TestSuccessRepository{}, &TestProductRepository{err: d.err})
+
+			rr := httptest.NewRecorder()
+			req := newProductRequest(t, http.MethodDelete, "/"+d.sku, "")
+
+			handler.ServeHTTP(rr, req)
+
+			if d.expectedErrCode == "" {
+				if rr.Code != d.expectedStatus {
+					t.Errorf("unexpected status code: got '%d' want '%d'", rr.Code, d.expectedStatus)
+				}
+				return
+			}
+			assertErrorResponse(t, rr, d.expectedStatus, d.expectedErrCode)
+		})
+	}
+}
+
+func TestProductHandler_HandleProductConfig(t *testing.T) {
+	data := []struct {
+		method string
+		path   string
+		body   string
+	}{
+		{
+			method: http.MethodGet,
+			path:   "/tea/config",
+		},
+		{
+			method: http.MethodPut,
+			path:   "/tea/config",
+			body:   `{"pack_sizes": [10]}`,
+		},
+		{
+			method: http.MethodGet,
+			path:   "/tea/config/versions",
+		},
+		{
+			method: http.MethodPost,
+			path:   "/tea/config/versions/1/restore",
+		},
+	}
+
+	for _, d := range data {
+		t.Run(fmt.Sprintf("with %s %s", d.method, d.path), func(t *testing.T) {
+			repo := TestSuccessRepository{versionsResult: []repository.Config{{Version: 1, PackSizes: []int{10}}}}
+			handler := newTestProductHandler(&repo, &TestProductRepository{})
+
+			rr := httptest.NewRecorder()
+			req := newProductRequest(t, d.method, d.path, d.body)
+			req.Header.Set("If-Match", "*")
+
+			handler.ServeHTTP(rr, req)
+
+			assertStatusOk(t, rr)
+			product := repo.passedProduct
+			if d.method == http.MethodPut {
+				product = repo.passedCfg.Product
+			}
+			if product != "tea" {
+				t.Errorf("unexpected product passed to repository: got '%s' want '%s'", product, "tea")
+			}
+		})
+	}
+}
+
+func TestProductHandler_HandleProductConfig_ProductNotFound(t *testing.T) {
+	handler := newTestProductHandler(&TestSuccessRepository{}, &TestProductRepository{err: repository.ErrNotFound})
+
+	rr := httptest.NewRecorder()
+	req := newProductRequest(t, http.MethodGet, "/tea/config", "")
+
+	handler.ServeHTTP(rr, req)
+
+	assertErrorResponse(t, rr, http.StatusNotFound, "product_not_found")
+}
+
+func newTestProductHandler(repo Repository, productRepo ProductRepository) ProductHandler {
+	handler := NewHandler(&TestPackComputer{}, repo)
+	return NewProductHandler(&handler, productRepo)
+}
+
+func newProductRequest(t *testing.T, method, path, payload string) *http.Request {
+	req, err := http.NewRequest(method, ProductsPath+path, bytes.NewReader([]byte(payload)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return req
+}
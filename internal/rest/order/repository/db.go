@@ -3,8 +3,34 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/jackc/pgx/v5/pgtype"
+	"time"
+)
+
+// SetConfigStatement and FindConfigStatement are the SQL statements of Database.SetConfig and Database.FindConfig, so
+// that they can be traced along with them.
+const (
+	SetConfigStatement = `WITH updated AS (
+			UPDATE orders_config
+			SET pack_sizes = $1, pack_costs = $7, handling_fee = $8, version = version + 1, updated_at = now()
+			WHERE product_sku = $6 AND ($2::bigint[] IS NULL OR version = ANY($2))
+			RETURNING product_sku, pack_sizes, pack_costs, handling_fee, version, updated_at
+		)
+		INSERT INTO orders_config_versions (product_sku, version, pack_sizes, pack_costs, handling_fee, created_at,
+			effective_from, author, reason)
+		SELECT product_sku, version, pack_sizes, pack_costs, handling_fee, updated_at,
+			COALESCE($5::timestamptz, updated_at), $3, $4
+		FROM updated
+		RETURNING version, created_at, effective_from`
+
+	FindConfigStatement = `SELECT ` + configVersionColumns + `, c.version
+		FROM orders_config_versions v JOIN orders_config c ON c.product_sku = v.product_sku
+		WHERE v.product_sku = $1 AND v.effective_from <= $2
+		ORDER BY v.effective_from DESC, v.version DESC
+		LIMIT 1`
 )
 
 // Database can communicate with the persistent repository.
@@ -16,26 +42,318 @@ func NewDatabase(handler *sql.DB) Database {
 	return Database{handler: handler}
 }
 
-func (db *Database) SetConfig(ctx context.Context, cfg Config) error {
-	stmt, err := db.handler.PrepareContext(ctx, `UPDATE orders_config SET pack_sizes = $1`)
+// Ping checks that a connection to the database can be established or reused.
+func (db *Database) Ping(ctx context.Context) error {
+	if err := db.handler.PingContext(ctx); err != nil {
+		return fmt.Errorf("error pinging the database: %w", err)
+	}
+	return nil
+}
+
+// SetConfig sets the config and increments its version, returning the new version and timestamp.
+// The config takes effect at cfg.EffectiveFrom, or immediately if it is zero.
+// Every version is also kept in the config history (see FindConfigVersions).
+// The config is only set if the latest version of the product's config is one of the given versions, otherwise it
+// returns ErrVersionConflict.
+// Nil versions set the config unconditionally.
+// The version is checked, incremented and kept by a single statement, so concurrent writers cannot overwrite each
+// other.
+func (db *Database) SetConfig(ctx context.Context, cfg Config, versions []int64) (Config, error) {
+	packCosts, err := marshalPackCosts(cfg.PackCosts)
 	if err != nil {
-		return fmt.Errorf("error preparing statment to set the config: %w", err)
+		return Config{}, err
 	}
 
-	_, err = stmt.ExecContext(ctx, cfg.PackSizes)
+	stmt, err := db.handler.PrepareContext(ctx, SetConfigStatement)
 	if err != nil {
-		return fmt.Errorf("error updating config: %w", err)
+		return Config{}, fmt.Errorf("error preparing statment to set the config: %w", err)
 	}
+	defer stmt.Close()
 
-	return nil
+	effectiveFrom := sql.NullTime{Time: cfg.EffectiveFrom, Valid: !cfg.EffectiveFrom.IsZero()}
+	err = stmt.QueryRowContext(ctx, cfg.PackSizes, versions, cfg.Author, cfg.Reason, effectiveFrom, cfg.Product,
+		packCosts, cfg.HandlingFee).Scan(&cfg.Version, &cfg.UpdatedAt, &cfg.EffectiveFrom)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Config{}, ErrVersionConflict
+	}
+	if err != nil {
+		return Config{}, fmt.Errorf("error updating config: %w", err)
+	}
+
+	cfg.LatestVersion = cfg.Version
+	return cfg, nil
+}
+
+// FindConfig returns the config of the product in effect at the given time, or ErrNotFound if the product does not
+// exist or no version was in effect yet.
+func (db *Database) FindConfig(ctx context.Context, product string, at time.Time) (Config, error) {
+	row := db.handler.QueryRowContext(ctx, FindConfigStatement, product, at)
+
+	var latestVersion int64
+	cfg, err := scanConfigVersionAnd(row, &latestVersion)
+	cfg.LatestVersion = latestVersion
+	if errors.Is(err, sql.ErrNoRows) {
+		return Config{}, ErrNotFound
+	}
+	if err != nil {
+		return Config{}, fmt.Errorf("error querying config: %w", err)
+	}
+	return cfg, nil
+}
+
+// FindConfigs returns the configs of the products in effect at the given time, in any order.
+// Products that do not exist or had no version in effect yet are left out.
+// The configs are found by a single statement, so they are a consistent snapshot even if they are set concurrently.
+func (db *Database) FindConfigs(ctx context.Context, products []string, at time.Time) ([]Config, error) {
+	rows, err := db.handler.QueryContext(ctx, `SELECT DISTINCT ON (v.product_sku) `+configVersionColumns+`, c.version
+		FROM orders_config_versions v JOIN orders_config c ON c.product_sku = v.product_sku
+		WHERE v.product_sku = ANY($1) AND v.effective_from <= $2
+		ORDER BY v.product_sku, v.effective_from DESC, v.version DESC`, products, at)
+	if err != nil {
+		return nil, fmt.Errorf("error querying configs: %w", err)
+	}
+	defer rows.Close()
+
+	var result []Config
+	for rows.Next() {
+		var latestVersion int64
+		cfg, err := scanConfigVersionAnd(rows, &latestVersion)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning config: %w", err)
+		}
+		cfg.LatestVersion = latestVersion
+		result = append(result, cfg)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating configs: %w", err)
+	}
+
+	return result, nil
+}
+
+// FindConfigVersions returns every version of the product's config, newest first.
+func (db *Database) FindConfigVersions(ctx context.Context, product string) ([]Config, error) {
+	rows, err := db.handler.QueryContext(ctx,
+		"SELECT "+configVersionColumns+" FROM orders_config_versions v WHERE v.product_sku = $1 ORDER BY v.version DESC",
+		product)
+	if err != nil {
+		return nil, fmt.Errorf("error querying config versions: %w", err)
+	}
+	defer rows.Close()
+
+	var result []Config
+	for rows.Next() {
+		cfg, err := scanConfigVersion(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning config version: %w", err)
+		}
+		result = append(result, cfg)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating config versions: %w", err)
+	}
+
+	return result, nil
 }
 
-func (db *Database) FindConfig(ctx context.Context) (Config, error) {
+// FindConfigVersion returns the given version of the product's config, or ErrNotFound if it does not exist.
+func (db *Database) FindConfigVersion(ctx context.Context, product string, version int64) (Config, error) {
+	row := db.handler.QueryRowContext(ctx,
+		"SELECT "+configVersionColumns+" FROM orders_config_versions v WHERE v.product_sku = $1 AND v.version = $2",
+		product, version)
+	cfg, err := scanConfigVersion(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Config{}, ErrNotFound
+	}
+	if err != nil {
+		return Config{}, fmt.Errorf("error querying config version: %w", err)
+	}
+	return cfg, nil
+}
+
+// configVersionColumns are the columns scanned by scanConfigVersion.
+const configVersionColumns = "v.product_sku, v.pack_sizes, v.version, v.created_at, v.effective_from, v.author, " +
+	"v.reason, v.pack_costs, v.handling_fee"
+
+func scanConfigVersion(s scanner) (Config, error) {
+	return scanConfigVersionAnd(s)
+}
+
+// scanConfigVersionAnd scans the configVersionColumns followed by the columns scanned into dest.
+func scanConfigVersionAnd(s scanner, dest ...any) (Config, error) {
 	var cfg Config
+	var packCosts []byte
 	m := pgtype.NewMap()
-	err := db.handler.QueryRowContext(ctx, "SELECT pack_sizes FROM orders_config").Scan(m.SQLScanner(&cfg.PackSizes))
+	err := s.Scan(append([]any{&cfg.Product, m.SQLScanner(&cfg.PackSizes), &cfg.Version, &cfg.UpdatedAt,
+		&cfg.EffectiveFrom, &cfg.Author, &cfg.Reason, &packCosts, &cfg.HandlingFee}, dest...)...)
 	if err != nil {
-		return Config{}, fmt.Errorf("error querying config: %w", err)
+		return Config{}, err
+	}
+
+	if packCosts != nil {
+		if err = json.Unmarshal(packCosts, &cfg.PackCosts); err != nil {
+			return Config{}, fmt.Errorf("error unmarshalling pack costs: %w", err)
+		}
 	}
 	return cfg, nil
 }
+
+// marshalPackCosts returns the pack costs as a JSON object by pack size, or nil (NULL) if there are none.
+func marshalPackCosts(packCosts map[int]int) (any, error) {
+	if len(packCosts) == 0 {
+		return nil, nil
+	}
+	packCostsJSON, err := json.Marshal(packCosts)
+	if err != nil {
+		return nil, fmt.Errorf("error marshalling pack costs: %w", err)
+	}
+	return string(packCostsJSON), nil
+}
+
+// SaveOrder inserts the order like SaveOrders and returns it with the id and the creation timestamp set by the
+// database.
+func (db *Database) SaveOrder(ctx context.Context, order Order) (Order, error) {
+	orders, err := db.SaveOrders(ctx, []Order{order})
+	if err != nil {
+		return Order{}, err
+	}
+	return orders[0], nil
+}
+
+// SaveOrders inserts the orders in a single transaction, so that either all or none are saved, and reserves their
+// packs of the pack sizes with a stock level until they are confirmed, cancelled or expired (see ConfirmOrder).
+// The stock levels of the orders' products are locked while the packs are reserved, so concurrent orders cannot
+// reserve the same packs. It returns ErrInsufficientStock, saving none, if the packs are not available.
+// It returns the orders with the ids and the creation timestamp (the same for all) set by the database.
+func (db *Database) SaveOrders(ctx context.Context, orders []Order) ([]Order, error) {
+	tx, err := db.handler.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error beginning transaction to insert orders: %w", err)
+	}
+	defer tx.Rollback()
+
+	products := make([]string, len(orders))
+	for i, order := range orders {
+		products[i] = order.Product
+	}
+	stocks, err := lockAvailableStock(ctx, tx, products)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]Order, len(orders))
+	for i, order := range orders {
+		reservation, err := reservePacks(stocks[order.Product], order.Packs)
+		if err != nil {
+			return nil, err
+		}
+		if result[i], err = insertOrder(ctx, tx, order); err != nil {
+			return nil, err
+		}
+		if err = insertReservation(ctx, tx, result[i], reservation); err != nil {
+			return nil, err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("error committing orders: %w", err)
+	}
+	return result, nil
+}
+
+// queryRower is implemented by both sql.DB and sql.Tx.
+type queryRower interface {
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+func insertOrder(ctx context.Context, q queryRower, order Order) (Order, error) {
+	packs, err := json.Marshal(order.Packs)
+	if err != nil {
+		return Order{}, fmt.Errorf("error marshalling order packs: %w", err)
+	}
+
+	err = q.QueryRowContext(ctx,
+		`INSERT INTO orders (product_sku, size, packs, pack_sizes, config_version, status, expires_at, objective, cost,
+			solver)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING id, created_at`,
+		order.Product, order.Size, string(packs), order.PackSizes, order.ConfigVersion, order.Status, order.ExpiresAt,
+		order.Objective, order.Cost, order.Solver,
+	).Scan(&order.ID, &order.CreatedAt)
+	if err != nil {
+		return Order{}, fmt.Errorf("error inserting order: %w", err)
+	}
+
+	return order, nil
+}
+
+// FindOrder returns the order with the given id, or ErrNotFound if it does not exist.
+func (db *Database) FindOrder(ctx context.Context, id int64) (Order, error) {
+	row := db.handler.QueryRowContext(ctx, "SELECT "+orderColumns+" FROM orders WHERE id = $1", id)
+	order, err := scanOrder(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Order{}, ErrNotFound
+	}
+	if err != nil {
+		return Order{}, fmt.Errorf("error querying order: %w", err)
+	}
+	return order, nil
+}
+
+// FindOrders returns the orders that match the filter, sorted by creation timestamp and id, newest first.
+func (db *Database) FindOrders(ctx context.Context, filter OrderFilter) ([]Order, error) {
+	query, args, err := buildFindOrdersQuery(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.handler.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error querying orders: %w", err)
+	}
+	defer rows.Close()
+
+	var result []Order
+	for rows.Next() {
+		order, err := scanOrder(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning order: %w", err)
+		}
+		result = append(result, order)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating orders: %w", err)
+	}
+
+	return result, nil
+}
+
+// scanner is implemented by both sql.Row and sql.Rows.
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+// orderColumns are the columns scanned by scanOrder.
+const orderColumns = "id, product_sku, size, packs, pack_sizes, config_version, created_at, status, expires_at, " +
+	"objective, cost, solver"
+
+func scanOrder(s scanner) (Order, error) {
+	var order Order
+	var packs []byte
+	m := pgtype.NewMap()
+	var configVersion sql.NullInt64
+	err := s.Scan(&order.ID, &order.Product, &order.Size, &packs, m.SQLScanner(&order.PackSizes), &configVersion,
+		&order.CreatedAt, &order.Status, &order.ExpiresAt, &order.Objective, &order.Cost, &order.Solver)
+	if err != nil {
+		return Order{}, err
+	}
+
+	// orders created before the config history have no config version
+	order.ConfigVersion = configVersion.Int64
+
+	if err = json.Unmarshal(packs, &order.Packs); err != nil {
+		return Order{}, fmt.Errorf("error unmarshalling order packs: %w", err)
+	}
+
+	return order, nil
+}
@@ -0,0 +1,31 @@
+package repository
+
+import "errors"
+
+var (
+	// ErrNotFound is returned when the requested entity does not exist.
+	ErrNotFound = errors.New("not found")
+
+	// ErrAlreadyExists is returned when an entity with the same key already exists.
+	ErrAlreadyExists = errors.New("already exists")
+
+	// ErrInUse is returned when the entity cannot be deleted because other entities reference it.
+	ErrInUse = errors.New("in use")
+
+	// ErrVersionConflict is returned when the entity was changed since the version the caller expected.
+	ErrVersionConflict = errors.New("version conflict")
+
+	// ErrInsufficientStock is returned when the packs of an order are not available, e.g. because other orders reserved
+	// them since the stock was found.
+	ErrInsufficientStock = errors.New("insufficient stock")
+
+	// ErrStockReserved is returned when a stock level would be lower than the quantity reserved by quoted orders.
+	ErrStockReserved = errors.New("stock reserved")
+
+	// ErrNotQuoted is returned when an order is not a quote (e.g. it was already confirmed) and cannot be confirmed or
+	// cancelled.
+	ErrNotQuoted = errors.New("not quoted")
+
+	// ErrExpired is returned when a quote cannot be confirmed because it expired.
+	ErrExpired = errors.New("expired")
+)
@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// queryer is implemented by both sql.DB and sql.Tx.
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+// FindStockLevels returns the stock levels of the given products, or of every product if there are none, with the
+// quantities reserved by quoted orders, sorted by product and pack size.
+func (db *Database) FindStockLevels(ctx context.Context, products []string) ([]StockLevel, error) {
+	return findStockLevels(ctx, db.handler, products)
+}
+
+// SetStockLevels sets the stock levels of the product's pack sizes and returns every stock level of the product.
+// It returns ErrNotFound if the product does not exist, and ErrStockReserved if a stock level would be lower than its
+// reserved quantity.
+func (db *Database) SetStockLevels(ctx context.Context, product string, levels []StockLevel) ([]StockLevel, error) {
+	packSizes := make([]int, len(levels))
+	quantities := make([]int, len(levels))
+	for i, level := range levels {
+		packSizes[i] = level.PackSize
+		quantities[i] = level.Quantity
+	}
+
+	tx, err := db.handler.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error beginning transaction to set stock levels: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `INSERT INTO inventory (product_sku, pack_size, quantity)
+		SELECT $1, pack_size, quantity FROM unnest($2::integer[], $3::integer[]) AS level (pack_size, quantity)
+		ON CONFLICT (product_sku, pack_size) DO UPDATE SET quantity = EXCLUDED.quantity, updated_at = now()`,
+		product, packSizes, quantities)
+	if isPgError(err, pgForeignKeyViolation) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error setting stock levels: %w", err)
+	}
+
+	// the updated stock levels are locked, so no packs can be reserved until the transaction ends
+	var packSize int
+	err = tx.QueryRowContext(ctx, `SELECT i.pack_size
+		FROM inventory i JOIN reservations r ON r.product_sku = i.product_sku AND r.pack_size = i.pack_size
+		WHERE i.product_sku = $1 AND i.pack_size = ANY($2)
+		GROUP BY i.product_sku, i.pack_size
+		HAVING i.quantity < SUM(r.quantity)
+		LIMIT 1`, product, packSizes).Scan(&packSize)
+	if err == nil {
+		return nil, ErrStockReserved
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("error checking reserved stock levels: %w", err)
+	}
+
+	result, err := findStockLevels(ctx, tx, []string{product})
+	if err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("error committing stock levels: %w", err)
+	}
+	return result, nil
+}
+
+// DeleteStockLevel deletes the stock level of the product's pack size, which becomes unlimited, with its reservations.
+// It returns ErrNotFound if the pack size has no stock level.
+func (db *Database) DeleteStockLevel(ctx context.Context, product string, packSize int) error {
+	result, err := db.handler.ExecContext(ctx, "DELETE FROM inventory WHERE product_sku = $1 AND pack_size = $2",
+		product, packSize)
+	if err != nil {
+		return fmt.Errorf("error deleting stock level: %w", err)
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error deleting stock level: %w", err)
+	}
+	if deleted == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func findStockLevels(ctx context.Context, q queryer, products []string) ([]StockLevel, error) {
+	query := `SELECT i.product_sku, i.pack_size, i.quantity, COALESCE(SUM(r.quantity), 0), i.updated_at
+		FROM inventory i LEFT JOIN reservations r ON r.product_sku = i.product_sku AND r.pack_size = i.pack_size`
+	var args []any
+	if len(products) > 0 {
+		query += " WHERE i.product_sku = ANY($1)"
+		args = append(args, products)
+	}
+	query += " GROUP BY i.product_sku, i.pack_size ORDER BY i.product_sku, i.pack_size"
+
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error querying stock levels: %w", err)
+	}
+	defer rows.Close()
+
+	var result []StockLevel
+	for rows.Next() {
+		var level StockLevel
+		err = rows.Scan(&level.Product, &level.PackSize, &level.Quantity, &level.Reserved, &level.UpdatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning stock level: %w", err)
+		}
+		result = append(result, level)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating stock levels: %w", err)
+	}
+
+	return result, nil
+}
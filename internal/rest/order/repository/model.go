@@ -1,5 +1,103 @@
 package repository
 
+import "time"
+
+// DefaultProduct is the SKU of the product that existed before products, used when no product is given.
+const DefaultProduct = "default"
+
+// The statuses of an order. Orders are saved as quotes that reserve their packs in stock until they are confirmed,
+// cancelled or expired.
+const (
+	OrderStatusQuoted    = "quoted"
+	OrderStatusConfirmed = "confirmed"
+	OrderStatusCancelled = "cancelled"
+	OrderStatusExpired   = "expired"
+)
+
+type Product struct {
+	SKU       string
+	Name      string
+	CreatedAt time.Time
+}
+
 type Config struct {
+	// Product is the SKU of the product the config belongs to.
+	Product   string
 	PackSizes []int
+	// PackCosts is the unit cost of every pack size, or nil if the config has no costs.
+	PackCosts map[int]int
+	// HandlingFee is added once to the cost of every order.
+	HandlingFee int
+	// Version is incremented every time the config is set.
+	Version   int64
+	UpdatedAt time.Time
+	// EffectiveFrom is when this version takes effect (it can be scheduled in the future).
+	EffectiveFrom time.Time
+	// LatestVersion is the latest version of the config, in effect or scheduled.
+	// It is the version checked by SetConfig.
+	LatestVersion int64
+	// Author and Reason describe who set this version of the config and why.
+	Author, Reason string
+}
+
+type Order struct {
+	ID int64
+	// Product is the SKU of the ordered product.
+	Product   string
+	Size      int
+	Packs     []Pack
+	PackSizes []int
+	// ConfigVersion is the version of the config the order was computed with.
+	ConfigVersion int64
+	CreatedAt     time.Time
+	Status        string
+	// ExpiresAt is when a quoted order expires, releasing its reserved packs. Orders created before quotes have none.
+	ExpiresAt *time.Time
+	// Objective is the name of the objective the packs were computed with (see pack.NewObjective).
+	Objective string
+	// Cost is the cost of the packs plus the handling fee, or nil if the config has no costs.
+	Cost *int
+	// Solver is the name of the solver that computed the packs (e.g. pack.SolverDP).
+	Solver string
+}
+
+// StockLevel is the quantity in stock of a pack size of a product.
+type StockLevel struct {
+	Product  string
+	PackSize int
+	Quantity int
+	// Reserved is the quantity reserved by quoted orders, which is not available to other orders.
+	Reserved  int
+	UpdatedAt time.Time
+}
+
+// Available returns the quantity not reserved by quoted orders.
+func (level StockLevel) Available() int {
+	return max(level.Quantity-level.Reserved, 0)
+}
+
+type Pack struct {
+	Size     int `json:"size"`
+	Quantity int `json:"quantity"`
+}
+
+// OrderFilter filters and paginates orders. Zero values do not filter.
+type OrderFilter struct {
+	// Product keeps the orders of this product.
+	Product string
+	// CreatedFrom is inclusive and CreatedTo exclusive.
+	CreatedFrom, CreatedTo time.Time
+	// MinSize and MaxSize are inclusive.
+	MinSize, MaxSize int
+	// PackSize keeps the orders that were shipped with at least one pack of this size.
+	PackSize int
+	// After keeps the orders that come after the cursor in the sort order (newest first).
+	After *OrderCursor
+	Limit int
+}
+
+// OrderCursor is the position of an order in the sort order (newest first).
+type OrderCursor struct {
+	CreatedAt time.Time
+	ID        int64
 }
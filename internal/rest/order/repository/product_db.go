@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+const (
+	pgUniqueViolation     = "23505"
+	pgForeignKeyViolation = "23503"
+)
+
+// CreateProduct inserts the product with the first version of its config, which takes effect immediately.
+// It returns ErrAlreadyExists if a product with the same SKU exists.
+func (db *Database) CreateProduct(ctx context.Context, product Product, cfg Config) (Product, error) {
+	err := db.handler.QueryRowContext(ctx, `WITH product AS (
+			INSERT INTO products (sku, name) VALUES ($1, $2)
+			RETURNING sku, name, created_at
+		), config AS (
+			INSERT INTO orders_config (product_sku, pack_sizes, version, updated_at)
+			SELECT sku, $3, 1, created_at FROM product
+			RETURNING product_sku, pack_sizes, version, updated_at
+		), config_version AS (
+			INSERT INTO orders_config_versions (product_sku, version, pack_sizes, created_at, effective_from, author, reason)
+			SELECT product_sku, version, pack_sizes, updated_at, updated_at, $4, $5 FROM config
+		)
+		SELECT sku, name, created_at FROM product`,
+		product.SKU, product.Name, cfg.PackSizes, cfg.Author, cfg.Reason,
+	).Scan(&product.SKU, &product.Name, &product.CreatedAt)
+	if isPgError(err, pgUniqueViolation) {
+		return Product{}, ErrAlreadyExists
+	}
+	if err != nil {
+		return Product{}, fmt.Errorf("error inserting product: %w", err)
+	}
+	return product, nil
+}
+
+// FindProduct returns the product with the given SKU, or ErrNotFound if it does not exist.
+func (db *Database) FindProduct(ctx context.Context, sku string) (Product, error) {
+	var product Product
+	err := db.handler.QueryRowContext(ctx, "SELECT sku, name, created_at FROM products WHERE sku = $1", sku).
+		Scan(&product.SKU, &product.Name, &product.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Product{}, ErrNotFound
+	}
+	if err != nil {
+		return Product{}, fmt.Errorf("error querying product: %w", err)
+	}
+	return product, nil
+}
+
+// FindProducts returns every product, sorted by SKU.
+func (db *Database) FindProducts(ctx context.Context) ([]Product, error) {
+	rows, err := db.handler.QueryContext(ctx, "SELECT sku, name, created_at FROM products ORDER BY sku")
+	if err != nil {
+		return nil, fmt.Errorf("error querying products: %w", err)
+	}
+	defer rows.Close()
+
+	var result []Product
+	for rows.Next() {
+		var product Product
+		if err = rows.Scan(&product.SKU, &product.Name, &product.CreatedAt); err != nil {
+			return nil, fmt.Errorf("error scanning product: %w", err)
+		}
+		result = append(result, product)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating products: %w", err)
+	}
+
+	return result, nil
+}
+
+// UpdateProduct updates the name of the product, or returns ErrNotFound if it does not exist.
+func (db *Database) UpdateProduct(ctx context.Context, product Product) (Product, error) {
+	err := db.handler.QueryRowContext(ctx, "UPDATE products SET name = $2 WHERE sku = $1 RETURNING created_at",
+		product.SKU, product.Name).Scan(&product.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Product{}, ErrNotFound
+	}
+	if err != nil {
+		return Product{}, fmt.Errorf("error updating product: %w", err)
+	}
+	return product, nil
+}
+
+// DeleteProduct deletes the product with its config history.
+// It returns ErrNotFound if it does not exist and ErrInUse if it was ordered, as orders are kept.
+func (db *Database) DeleteProduct(ctx context.Context, sku string) error {
+	result, err := db.handler.ExecContext(ctx, "DELETE FROM products WHERE sku = $1", sku)
+	if isPgError(err, pgForeignKeyViolation) {
+		return ErrInUse
+	}
+	if err != nil {
+		return fmt.Errorf("error deleting product: %w", err)
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error deleting product: %w", err)
+	}
+	if deleted == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func isPgError(err error, code string) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == code
+}
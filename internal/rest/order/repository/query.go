@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// buildFindOrdersQuery builds the query and its arguments to find the orders that match the filter, sorted by creation
+// timestamp and id, newest first.
+func buildFindOrdersQuery(filter OrderFilter) (string, []any, error) {
+	var conditions []string
+	var args []any
+
+	addCondition := func(condition string, values ...any) {
+		placeholders := make([]any, len(values))
+		for i, value := range values {
+			args = append(args, value)
+			placeholders[i] = fmt.Sprintf("$%d", len(args))
+		}
+		conditions = append(conditions, fmt.Sprintf(condition, placeholders...))
+	}
+
+	if filter.Product != "" {
+		addCondition("product_sku = %s", filter.Product)
+	}
+	if !filter.CreatedFrom.IsZero() {
+		addCondition("created_at >= %s", filter.CreatedFrom)
+	}
+	if !filter.CreatedTo.IsZero() {
+		addCondition("created_at < %s", filter.CreatedTo)
+	}
+	if filter.MinSize > 0 {
+		addCondition("size >= %s", filter.MinSize)
+	}
+	if filter.MaxSize > 0 {
+		addCondition("size <= %s", filter.MaxSize)
+	}
+	if filter.PackSize > 0 {
+		packs, err := json.Marshal([]map[string]int{{"size": filter.PackSize}})
+		if err != nil {
+			return "", nil, fmt.Errorf("error marshalling pack size filter: %w", err)
+		}
+		addCondition("packs @> %s::jsonb", string(packs))
+	}
+	if filter.After != nil {
+		addCondition("(created_at, id) < (%s, %s)", filter.After.CreatedAt, filter.After.ID)
+	}
+
+	var query strings.Builder
+	query.WriteString("SELECT " + orderColumns + " FROM orders")
+	if len(conditions) > 0 {
+		query.WriteString(" WHERE ")
+		query.WriteString(strings.Join(conditions, " AND "))
+	}
+	query.WriteString(" ORDER BY created_at DESC, id DESC")
+	if filter.Limit > 0 {
+		args = append(args, filter.Limit)
+		query.WriteString(fmt.Sprintf(" LIMIT $%d", len(args)))
+	}
+
+	return query.String(), args, nil
+}
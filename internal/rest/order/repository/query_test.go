@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestBuildFindOrdersQuery_NoFilter(t *testing.T) {
+	query, args, err := buildFindOrdersQuery(OrderFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedQuery := "SELECT id, product_sku, size, packs, pack_sizes, config_version, created_at, status, expires_at, objective, cost, solver FROM orders" +
+		" ORDER BY created_at DESC, id DESC"
+	if query != expectedQuery {
+		t.Errorf("unexpected query: got '%s' want '%s'", query, expectedQuery)
+	}
+	if len(args) != 0 {
+		t.Errorf("unexpected args: got '%+v' want none", args)
+	}
+}
+
+func TestBuildFindOrdersQuery_AllFilters(t *testing.T) {
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	after := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	filter := OrderFilter{
+		Product:     "tea",
+		CreatedFrom: from,
+		CreatedTo:   to,
+		MinSize:     10,
+		MaxSize:     1000,
+		PackSize:    250,
+		After:       &OrderCursor{CreatedAt: after, ID: 42},
+		Limit:       50,
+	}
+
+	query, args, err := buildFindOrdersQuery(filter)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedQuery := "SELECT id, product_sku, size, packs, pack_sizes, config_version, created_at, status, expires_at, objective, cost, solver FROM orders" +
+		" WHERE product_sku = $1 AND created_at >= $2 AND created_at < $3 AND size >= $4 AND size <= $5" +
+		" AND packs @> $6::jsonb AND (created_at, id) < ($7, $8)" +
+		" ORDER BY created_at DESC, id DESC LIMIT $9"
+	if query != expectedQuery {
+		t.Errorf("unexpected query: got '%s' want '%s'", query, expectedQuery)
+	}
+
+	expectedArgs := []any{"tea", from, to, 10, 1000, `[{"size":250}]`, after, int64(42), 50}
+	if !slices.Equal(args, expectedArgs) {
+		t.Errorf("unexpected args: got '%+v' want '%+v'", args, expectedArgs)
+	}
+}
@@ -0,0 +1,198 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// lockAvailableStock locks the stock levels of the products until the transaction ends and returns the quantity
+// available of every pack size with a stock level, by product.
+// The stock levels are locked in a fixed order, so concurrent transactions cannot deadlock.
+func lockAvailableStock(ctx context.Context, tx *sql.Tx, products []string) (map[string]map[int]int, error) {
+	_, err := tx.ExecContext(ctx,
+		"SELECT 1 FROM inventory WHERE product_sku = ANY($1) ORDER BY product_sku, pack_size FOR UPDATE", products)
+	if err != nil {
+		return nil, fmt.Errorf("error locking stock levels: %w", err)
+	}
+
+	levels, err := findStockLevels(ctx, tx, products)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]map[int]int)
+	for _, level := range levels {
+		if result[level.Product] == nil {
+			result[level.Product] = make(map[int]int)
+		}
+		result[level.Product][level.PackSize] = level.Available()
+	}
+	return result, nil
+}
+
+// reservePacks takes the packs of the pack sizes with a stock level from the available stock and returns their
+// quantities by pack size, or ErrInsufficientStock if they are not available.
+func reservePacks(stock map[int]int, packs []Pack) (map[int]int, error) {
+	result := make(map[int]int)
+	for _, p := range packs {
+		available, limited := stock[p.Size]
+		if !limited || p.Quantity == 0 {
+			continue
+		}
+		if p.Quantity > available {
+			return nil, ErrInsufficientStock
+		}
+		stock[p.Size] -= p.Quantity
+		result[p.Size] += p.Quantity
+	}
+	return result, nil
+}
+
+func insertReservation(ctx context.Context, tx *sql.Tx, order Order, reservation map[int]int) error {
+	if len(reservation) == 0 {
+		return nil
+	}
+
+	packSizes := make([]int, 0, len(reservation))
+	quantities := make([]int, 0, len(reservation))
+	for packSize, quantity := range reservation {
+		packSizes = append(packSizes, packSize)
+		quantities = append(quantities, quantity)
+	}
+
+	_, err := tx.ExecContext(ctx, `INSERT INTO reservations (order_id, product_sku, pack_size, quantity)
+		SELECT $1, $2, pack_size, quantity FROM unnest($3::integer[], $4::integer[]) AS reservation (pack_size, quantity)`,
+		order.ID, order.Product, packSizes, quantities)
+	if err != nil {
+		return fmt.Errorf("error inserting reservation: %w", err)
+	}
+	return nil
+}
+
+// ConfirmOrder confirms the quoted order, taking its reserved packs from the stock.
+// It returns ErrNotFound if the order does not exist and ErrNotQuoted if it is not quoted. A quoted order that is past
+// its expiry is expired instead, releasing its reserved packs, and ErrExpired is returned.
+func (db *Database) ConfirmOrder(ctx context.Context, id int64) (Order, error) {
+	tx, err := db.handler.BeginTx(ctx, nil)
+	if err != nil {
+		return Order{}, fmt.Errorf("error beginning transaction to confirm order: %w", err)
+	}
+	defer tx.Rollback()
+
+	order, err := lockQuotedOrder(ctx, tx, id)
+	if err != nil {
+		return Order{}, err
+	}
+
+	if order.ExpiresAt != nil && !time.Now().Before(*order.ExpiresAt) {
+		if err = releaseOrder(ctx, tx, id, OrderStatusExpired); err != nil {
+			return Order{}, err
+		}
+		if err = tx.Commit(); err != nil {
+			return Order{}, fmt.Errorf("error committing expired order: %w", err)
+		}
+		return Order{}, ErrExpired
+	}
+
+	// the stock levels are locked in the same order as lockAvailableStock does
+	_, err = tx.ExecContext(ctx, `SELECT 1 FROM inventory i
+		JOIN reservations r ON r.product_sku = i.product_sku AND r.pack_size = i.pack_size
+		WHERE r.order_id = $1
+		ORDER BY i.product_sku, i.pack_size
+		FOR UPDATE OF i`, id)
+	if err != nil {
+		return Order{}, fmt.Errorf("error locking reserved stock levels: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `UPDATE inventory i SET quantity = i.quantity - r.quantity, updated_at = now()
+		FROM reservations r
+		WHERE r.order_id = $1 AND i.product_sku = r.product_sku AND i.pack_size = r.pack_size`, id)
+	if err != nil {
+		return Order{}, fmt.Errorf("error taking reserved stock: %w", err)
+	}
+
+	if err = releaseOrder(ctx, tx, id, OrderStatusConfirmed); err != nil {
+		return Order{}, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return Order{}, fmt.Errorf("error committing confirmed order: %w", err)
+	}
+
+	order.Status = OrderStatusConfirmed
+	return order, nil
+}
+
+// CancelOrder cancels the quoted order, releasing its reserved packs.
+// It returns ErrNotFound if the order does not exist and ErrNotQuoted if it is not quoted.
+func (db *Database) CancelOrder(ctx context.Context, id int64) (Order, error) {
+	tx, err := db.handler.BeginTx(ctx, nil)
+	if err != nil {
+		return Order{}, fmt.Errorf("error beginning transaction to cancel order: %w", err)
+	}
+	defer tx.Rollback()
+
+	order, err := lockQuotedOrder(ctx, tx, id)
+	if err != nil {
+		return Order{}, err
+	}
+
+	if err = releaseOrder(ctx, tx, id, OrderStatusCancelled); err != nil {
+		return Order{}, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return Order{}, fmt.Errorf("error committing cancelled order: %w", err)
+	}
+
+	order.Status = OrderStatusCancelled
+	return order, nil
+}
+
+// ExpireOrders expires the quoted orders past their expiry, releasing their reserved packs, and returns how many were
+// expired.
+// Orders being confirmed or cancelled are locked, so they are only expired if they are still quoted afterwards.
+func (db *Database) ExpireOrders(ctx context.Context) (int64, error) {
+	var expired int64
+	err := db.handler.QueryRowContext(ctx, `WITH expired AS (
+			UPDATE orders SET status = $1 WHERE status = $2 AND expires_at <= now()
+			RETURNING id
+		), released AS (
+			DELETE FROM reservations WHERE order_id IN (SELECT id FROM expired)
+		)
+		SELECT count(*) FROM expired`, OrderStatusExpired, OrderStatusQuoted).Scan(&expired)
+	if err != nil {
+		return 0, fmt.Errorf("error expiring orders: %w", err)
+	}
+	return expired, nil
+}
+
+// lockQuotedOrder locks the order until the transaction ends. It returns ErrNotFound if the order does not exist and
+// ErrNotQuoted if it is not quoted.
+func lockQuotedOrder(ctx context.Context, tx *sql.Tx, id int64) (Order, error) {
+	order, err := scanOrder(tx.QueryRowContext(ctx, "SELECT "+orderColumns+" FROM orders WHERE id = $1 FOR UPDATE", id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return Order{}, ErrNotFound
+	}
+	if err != nil {
+		return Order{}, fmt.Errorf("error querying order: %w", err)
+	}
+	if order.Status != OrderStatusQuoted {
+		return Order{}, ErrNotQuoted
+	}
+	return order, nil
+}
+
+// releaseOrder deletes the reservation of the order and sets its status.
+func releaseOrder(ctx context.Context, tx *sql.Tx, id int64, status string) error {
+	if _, err := tx.ExecContext(ctx, "DELETE FROM reservations WHERE order_id = $1", id); err != nil {
+		return fmt.Errorf("error deleting reservation: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, "UPDATE orders SET status = $2 WHERE id = $1", id, status); err != nil {
+		return fmt.Errorf("error updating order status: %w", err)
+	}
+	return nil
+}
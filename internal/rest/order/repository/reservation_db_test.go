@@ -0,0 +1,30 @@
+package repository
+
+import (
+	"errors"
+	"maps"
+	"testing"
+)
+
+func TestReservePacks(t *testing.T) {
+	stock := map[int]int{50: 3, 10: 1}
+
+	reservation, err := reservePacks(stock, []Pack{{Size: 50, Quantity: 2}, {Size: 20, Quantity: 4}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedReservation := map[int]int{50: 2}
+	if !maps.Equal(reservation, expectedReservation) {
+		t.Errorf("unexpected reservation: got '%+v' want '%+v'", reservation, expectedReservation)
+	}
+	expectedStock := map[int]int{50: 1, 10: 1}
+	if !maps.Equal(stock, expectedStock) {
+		t.Errorf("unexpected stock: got '%+v' want '%+v'", stock, expectedStock)
+	}
+
+	_, err = reservePacks(stock, []Pack{{Size: 50, Quantity: 2}})
+	if !errors.Is(err, ErrInsufficientStock) {
+		t.Errorf("unexpected error: got '%v' want '%v'", err, ErrInsufficientStock)
+	}
+}
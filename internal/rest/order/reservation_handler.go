@@ -0,0 +1,149 @@
+package order
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"packer/internal/rest/order/repository"
+	"strings"
+	"time"
+)
+
+const (
+	confirmOrderSuffix = "/confirm"
+	cancelOrderSuffix  = "/cancel"
+	// DefaultQuoteTTL is how long the packs of a quoted order are reserved until it is confirmed.
+	DefaultQuoteTTL = 15 * time.Minute
+	// maxReserveAttempts is the number of times the packs of an order are computed again when the stock changed
+	// before they could be reserved.
+	maxReserveAttempts = 3
+)
+
+// ReservationRepository confirms, cancels and expires the quoted orders that reserve packs in stock.
+type ReservationRepository interface {
+	ConfirmOrder(ctx context.Context, id int64) (repository.Order, error)
+	CancelOrder(ctx context.Context, id int64) (repository.Order, error)
+	ExpireOrders(ctx context.Context) (int64, error)
+}
+
+// parseOrderActionPath parses the order id before the action's suffix (e.g. "1" for "/1/confirm").
+func parseOrderActionPath(path, suffix string) (string, bool) {
+	return strings.CutSuffix(strings.TrimSuffix(path, "/"), suffix)
+}
+
+func isConfirmOrderPath(path string) bool {
+	_, ok := parseOrderActionPath(path, confirmOrderSuffix)
+	return ok
+}
+
+func isCancelOrderPath(path string) bool {
+	_, ok := parseOrderActionPath(path, cancelOrderSuffix)
+	return ok
+}
+
+// handleConfirmOrder confirms a quoted order before it expires, taking its reserved packs from the stock.
+func (h *Handler) handleConfirmOrder(w http.ResponseWriter, r *http.Request) {
+	idPath, _ := parseOrderActionPath(r.URL.Path, confirmOrderSuffix)
+	h.handleOrderAction(w, r, idPath, h.repository.ConfirmOrder)
+}
+
+// handleCancelOrder cancels a quoted order, releasing its reserved packs.
+func (h *Handler) handleCancelOrder(w http.ResponseWriter, r *http.Request) {
+	idPath, _ := parseOrderActionPath(r.URL.Path, cancelOrderSuffix)
+	h.handleOrderAction(w, r, idPath, h.repository.CancelOrder)
+}
+
+func (h *Handler) handleOrderAction(w http.ResponseWriter, r *http.Request, idPath string,
+	action func(ctx context.Context, id int64) (repository.Order, error)) {
+	ctx := r.Context()
+
+	id, ok := parseOrderID(idPath)
+	if !ok {
+		h.writeBadRequestResponse(w, errRespInvalidOrderID)
+		return
+	}
+
+	packSort, ok := parsePackSort(r.URL.Query())
+	if !ok {
+		h.writeBadRequestResponse(w, errRespInvalidSort)
+		return
+	}
+
+	order, err := action(ctx, id)
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		h.writeErrorResponse(w, errRespOrderNotFound, http.StatusNotFound)
+		return
+	case errors.Is(err, repository.ErrNotQuoted):
+		h.writeErrorResponse(w, errRespOrderNotQuoted, http.StatusConflict)
+		return
+	case errors.Is(err, repository.ErrExpired):
+		h.writeErrorResponse(w, errRespQuoteExpired, http.StatusConflict)
+		return
+	case err != nil:
+		slog.ErrorContext(ctx, "unable to update the order", "error", err)
+		h.writeInternalServerErrorResponse(w)
+		return
+	}
+
+	updated := newOrder(order)
+	updated.sortPacks(packSort)
+	jsonBytes, err := json.Marshal(updated)
+	if err != nil {
+		slog.ErrorContext(ctx, "unable to encode the response", "error", err)
+		h.writeInternalServerErrorResponse(w)
+		return
+	}
+
+	h.writeMessageWithStatusResponse(w, jsonBytes, http.StatusOK)
+}
+
+// newQuote sets the order as quoted until the quote TTL elapses.
+func (h *Handler) newQuote(order repository.Order) repository.Order {
+	expiresAt := time.Now().Add(h.quoteTTL)
+	order.Status = repository.OrderStatusQuoted
+	order.ExpiresAt = &expiresAt
+	return order
+}
+
+// QuoteReaper expires the quoted orders past their expiry, so that their reserved packs are available again.
+// Confirming an expired quote fails even before the reaper expires it.
+type QuoteReaper struct {
+	repository ReservationRepository
+	interval   time.Duration
+}
+
+func NewQuoteReaper(repository ReservationRepository, interval time.Duration) QuoteReaper {
+	return QuoteReaper{
+		repository: repository,
+		interval:   interval,
+	}
+}
+
+// Run expires the quoted orders every interval until the context is done. Errors are logged and retried on the next
+// interval. The reaper is disabled if the interval is not greater than zero.
+func (reaper *QuoteReaper) Run(ctx context.Context) {
+	if reaper.interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(reaper.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			expired, err := reaper.repository.ExpireOrders(ctx)
+			if err != nil && ctx.Err() == nil {
+				slog.ErrorContext(ctx, "unable to expire the quoted orders", "error", err)
+			}
+			if expired > 0 {
+				slog.InfoContext(ctx, "expired the quoted orders", "count", expired)
+			}
+		}
+	}
+}
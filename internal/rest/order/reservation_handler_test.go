@@ -0,0 +1,228 @@
+package order
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"packer/internal/rest/order/pack"
+	"packer/internal/rest/order/repository"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestExpiringRepository counts the calls to expire orders and cancels the context after the given number of calls.
+type TestExpiringRepository struct {
+	calls       atomic.Int64
+	cancelAfter int64
+	cancel      context.CancelFunc
+}
+
+func (repo *TestExpiringRepository) ConfirmOrder(_ context.Context, _ int64) (repository.Order, error) {
+	return repository.Order{}, nil
+}
+
+func (repo *TestExpiringRepository) CancelOrder(_ context.Context, _ int64) (repository.Order, error) {
+	return repository.Order{}, nil
+}
+
+func (repo *TestExpiringRepository) ExpireOrders(_ context.Context) (int64, error) {
+	if repo.calls.Add(1) == repo.cancelAfter {
+		repo.cancel()
+	}
+	return 1, nil
+}
+
+func TestServeHTTP_HandleOrderAction_Success(t *testing.T) {
+	data := []struct {
+		action         string
+		expectedStatus string
+	}{
+		{
+			action:         "confirm",
+			expectedStatus: repository.OrderStatusConfirmed,
+		},
+		{
+			action:         "cancel",
+			expectedStatus: repository.OrderStatusCancelled,
+		},
+	}
+
+	for _, d := range data {
+		t.Run(fmt.Sprintf("with action: %s", d.action), func(t *testing.T) {
+			repo := TestSuccessRepository{
+				orderResult: repository.Order{
+					ID:        7,
+					Product:   repository.DefaultProduct,
+					Size:      251,
+					Packs:     []repository.Pack{{Size: 500, Quantity: 1}},
+					PackSizes: []int{250, 500},
+					CreatedAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+				},
+			}
+			handler := NewHandler(&TestPackComputer{}, &repo)
+
+			rr := httptest.NewRecorder()
+			req := newOrderActionRequest(t, "7", d.action)
+
+			handler.ServeHTTP(rr, req)
+
+			assertStatusOk(t, rr)
+			assertBody(t, rr, `{"id":7,"product":"default","size":251,"packs":[{"size":500,"quantity":1}],`+
+				`"pack_sizes":[250,500],"config_version":0,"created_at":"2024-01-01T00:00:00Z","status":"`+d.expectedStatus+`"}`)
+			if repo.passedOrderID != 7 {
+				t.Errorf("unexpected order id passed to repository: got '%d' want '%d'", repo.passedOrderID, 7)
+			}
+		})
+	}
+}
+
+func TestServeHTTP_HandleOrderAction_Errors(t *testing.T) {
+	data := []struct {
+		id              string
+		err             error
+		expectedStatus  int
+		expectedErrCode string
+	}{
+		{
+			id:              "a",
+			expectedStatus:  http.StatusBadRequest,
+			expectedErrCode: "invalid_order_id",
+		},
+		{
+			id:              "7",
+			err:             repository.ErrNotFound,
+			expectedStatus:  http.StatusNotFound,
+			expectedErrCode: "order_not_found",
+		},
+		{
+			id:              "7",
+			err:             repository.ErrNotQuoted,
+			expectedStatus:  http.StatusConflict,
+			expectedErrCode: "order_not_quoted",
+		},
+		{
+			id:              "7",
+			err:             repository.ErrExpired,
+			expectedStatus:  http.StatusConflict,
+			expectedErrCode: "quote_expired",
+		},
+		{
+			id:              "7",
+			err:             errors.New("test error"),
+			expectedStatus:  http.StatusInternalServerError,
+			expectedErrCode: "internal_server_error",
+		},
+	}
+
+	for _, action := range []string{"confirm", "cancel"} {
+		for _, d := range data {
+			t.Run(fmt.Sprintf("with action %s, id '%s' and error: %v", action, d.id, d.err), func(t *testing.T) {
+				handler := NewHandler(&TestPackComputer{}, &TestSuccessRepository{orderActionErr: d.err})
+
+				rr := httptest.NewRecorder()
+				req := newOrderActionRequest(t, d.id, action)
+
+				handler.ServeHTTP(rr, req)
+
+				assertErrorResponse(t, rr, d.expectedStatus, d.expectedErrCode)
+			})
+		}
+	}
+}
+
+func TestServeHTTP_HandleCreateOrder_ReservesQuote(t *testing.T) {
+	comp := TestPackComputer{result: []pack.Pack{{Size: 500, Quantity: 1}}}
+	repo := TestSuccessRepository{result: repository.Config{PackSizes: []int{250, 500}}}
+	handler := NewHandlerWithQuoteTTL(&comp, &repo, time.Minute)
+
+	rr := httptest.NewRecorder()
+	req := newCreateOrderRequestWithPayload(t, `{"size": 251}`)
+
+	before := time.Now()
+	handler.ServeHTTP(rr, req)
+
+	assertStatusOk(t, rr)
+	if repo.passedOrder.Status != repository.OrderStatusQuoted {
+		t.Errorf("unexpected order status: got '%s' want '%s'", repo.passedOrder.Status, repository.OrderStatusQuoted)
+	}
+	expiresAt := repo.passedOrder.ExpiresAt
+	if expiresAt == nil || expiresAt.Before(before.Add(time.Minute)) || expiresAt.After(time.Now().Add(time.Minute)) {
+		t.Errorf("unexpected order expiry: got '%v' want a minute after the request", expiresAt)
+	}
+}
+
+func TestServeHTTP_HandleCreateOrder_StockReservedConcurrently(t *testing.T) {
+	data := []struct {
+		saveOrderErrs   []error
+		expectedStatus  int
+		expectedErrCode string
+	}{
+		{
+			saveOrderErrs:  []error{repository.ErrInsufficientStock, repository.ErrInsufficientStock},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			saveOrderErrs: []error{repository.ErrInsufficientStock, repository.ErrInsufficientStock,
+				repository.ErrInsufficientStock},
+			expectedStatus:  http.StatusUnprocessableEntity,
+			expectedErrCode: "insufficient_stock",
+		},
+	}
+
+	for _, payload := range []string{`{"size": 1}`, `{"lines": [{"quantity": 1}]}`} {
+		for _, d := range data {
+			t.Run(fmt.Sprintf("with payload '%s' and %d errors", payload, len(d.saveOrderErrs)), func(t *testing.T) {
+				comp := TestPackComputer{result: []pack.Pack{{Size: 250, Quantity: 1}}}
+				repo := TestSuccessRepository{
+					result:        repository.Config{PackSizes: []int{250}},
+					configsResult: []repository.Config{{Product: repository.DefaultProduct, PackSizes: []int{250}}},
+					saveOrderErrs: d.saveOrderErrs,
+				}
+				handler := NewHandler(&comp, &repo)
+
+				rr := httptest.NewRecorder()
+				req := newCreateOrderRequestWithPayload(t, payload)
+
+				handler.ServeHTTP(rr, req)
+
+				if d.expectedErrCode == "" {
+					assertStatusOk(t, rr)
+					return
+				}
+				assertErrorResponse(t, rr, d.expectedStatus, d.expectedErrCode)
+			})
+		}
+	}
+}
+
+func TestQuoteReaper_Run(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	repo := TestExpiringRepository{cancelAfter: 3, cancel: cancel}
+	reaper := NewQuoteReaper(&repo, time.Millisecond)
+
+	done := make(chan struct{})
+	go func() {
+		reaper.Run(ctx)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the reaper did not stop when the context was cancelled")
+	}
+	if calls := repo.calls.Load(); calls != 3 {
+		t.Errorf("unexpected number of calls to expire orders: got '%d' want '%d'", calls, 3)
+	}
+}
+
+func newOrderActionRequest(t *testing.T, id, action string) *http.Request {
+	req, err := http.NewRequest(http.MethodPost, Path+"/"+id+"/"+action, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return req
+}
@@ -0,0 +1,31 @@
+package order
+
+// Solvers is a registry of the packs computers by name (e.g. pack.SolverDP), with the one used when a request does
+// not name any.
+type Solvers struct {
+	computers   map[string]PacksComputer
+	defaultName string
+}
+
+// NewSolvers creates a registry of the computers by name. The default name should be one of them, otherwise the
+// requests must name a solver.
+func NewSolvers(computers map[string]PacksComputer, defaultName string) Solvers {
+	return Solvers{
+		computers:   computers,
+		defaultName: defaultName,
+	}
+}
+
+// resolve returns the given name, or the default one if it is empty, and true if there is a computer with that name.
+func (s Solvers) resolve(name string) (string, bool) {
+	if name == "" {
+		name = s.defaultName
+	}
+	_, ok := s.computers[name]
+	return name, ok
+}
+
+// get returns the computer of a resolved name.
+func (s Solvers) get(name string) PacksComputer {
+	return s.computers[name]
+}
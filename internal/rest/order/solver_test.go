@@ -0,0 +1,92 @@
+package order
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"packer/internal/rest/order/pack"
+	"packer/internal/rest/order/repository"
+	"testing"
+)
+
+func TestServeHTTP_HandleCreateOrder_Solver(t *testing.T) {
+	data := []struct {
+		query          string
+		expectedSolver string
+	}{
+		{
+			query:          "",
+			expectedSolver: pack.SolverDP,
+		},
+		{
+			query:          "?solver=greedy",
+			expectedSolver: pack.SolverGreedy,
+		},
+	}
+
+	for _, d := range data {
+		t.Run(fmt.Sprintf("with query: '%s'", d.query), func(t *testing.T) {
+			computers := map[string]*TestPackComputer{
+				pack.SolverDP:     {result: []pack.Pack{{Size: 500, Quantity: 1}}},
+				pack.SolverGreedy: {result: []pack.Pack{{Size: 500, Quantity: 1}}},
+			}
+			solvers := NewSolvers(map[string]PacksComputer{
+				pack.SolverDP:     computers[pack.SolverDP],
+				pack.SolverGreedy: computers[pack.SolverGreedy],
+			}, pack.SolverDP)
+			repo := TestSuccessRepository{result: repository.Config{PackSizes: []int{250, 500}}}
+			handler := NewHandlerWithSolvers(solvers, &repo, DefaultQuoteTTL)
+
+			rr := httptest.NewRecorder()
+			req, err := http.NewRequest(http.MethodPost, Path+d.query, bytes.NewReader([]byte(`{"size": 251}`)))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			handler.ServeHTTP(rr, req)
+
+			assertStatusOk(t, rr)
+			for name, comp := range computers {
+				if called := comp.passedOrderSize != 0; called != (name == d.expectedSolver) {
+					t.Errorf("unexpected call of solver '%s': got '%t'", name, called)
+				}
+			}
+			if repo.passedOrder.Solver != d.expectedSolver {
+				t.Errorf("unexpected order solver: got '%s' want '%s'", repo.passedOrder.Solver, d.expectedSolver)
+			}
+			assertBody(t, rr, `{"id":1,"product":"default","size":251,"packs":[{"size":500,"quantity":1}],`+
+				`"pack_sizes":[250,500],"config_version":0,"created_at":"2024-01-01T00:00:00Z","status":"quoted",`+
+				`"expires_at":"2024-01-01T00:15:00Z","objective":"items_then_packs","solver":"`+d.expectedSolver+`"}`)
+		})
+	}
+}
+
+func TestServeHTTP_HandleCreateOrder_InvalidSolver(t *testing.T) {
+	comp := TestPackComputer{}
+	repo := TestSuccessRepository{}
+	handler := NewHandler(&comp, &repo)
+
+	rr := httptest.NewRecorder()
+	req, err := http.NewRequest(http.MethodPost, Path+"?solver=simplex", bytes.NewReader([]byte(`{"size": 251}`)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	handler.ServeHTTP(rr, req)
+
+	assertErrorResponse(t, rr, http.StatusBadRequest, "invalid_solver")
+}
+
+func TestServeHTTP_HandleQuoteBatch_InvalidSolver(t *testing.T) {
+	comp := TestPackComputer{}
+	repo := TestSuccessRepository{}
+	handler := NewHandler(&comp, &repo)
+
+	rr := httptest.NewRecorder()
+	req := newQuoteBatchRequest(t, "", "?solver=simplex", `[1]`)
+
+	handler.ServeHTTP(rr, req)
+
+	assertErrorResponse(t, rr, http.StatusBadRequest, "invalid_solver")
+}
@@ -0,0 +1,41 @@
+package order
+
+import (
+	"net/url"
+	"packer/internal/rest/order/pack"
+)
+
+// parsePackSort parses the order of the packs in a response from the sort query parameter, pack.DefaultSort if it is
+// missing. It returns false if it is invalid.
+func parsePackSort(query url.Values) (string, bool) {
+	by := query.Get("sort")
+	if by == "" {
+		by = pack.DefaultSort
+	}
+	return by, pack.SortValid(by)
+}
+
+// sortPacks sorts the packs of the order, of its explanation and of its ties.
+func (o *Order) sortPacks(by string) {
+	pack.SortPacks(o.Packs, by)
+	if o.Explanation != nil {
+		for _, runnerUp := range o.Explanation.RunnersUp {
+			pack.SortPacks(runnerUp.Packs, by)
+		}
+	}
+	for _, tie := range o.Ties {
+		pack.SortPacks(tie, by)
+	}
+}
+
+func (o *LinesOrder) sortPacks(by string) {
+	for _, line := range o.Lines {
+		pack.SortPacks(line.Packs, by)
+	}
+}
+
+func (p *OrderPage) sortPacks(by string) {
+	for i := range p.Orders {
+		p.Orders[i].sortPacks(by)
+	}
+}
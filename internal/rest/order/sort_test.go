@@ -0,0 +1,144 @@
+package order
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"packer/internal/rest/order/pack"
+	"packer/internal/rest/order/repository"
+	"reflect"
+	"testing"
+)
+
+func TestServeHTTP_HandleCreateOrder_SortPacks(t *testing.T) {
+	data := []struct {
+		query         string
+		expectedPacks []pack.Pack
+	}{
+		{
+			query:         "",
+			expectedPacks: []pack.Pack{{Size: 5000, Quantity: 2}, {Size: 2000, Quantity: 1}, {Size: 250, Quantity: 1}},
+		},
+		{
+			query:         "?sort=size_asc",
+			expectedPacks: []pack.Pack{{Size: 250, Quantity: 1}, {Size: 2000, Quantity: 1}, {Size: 5000, Quantity: 2}},
+		},
+		{
+			query:         "?sort=quantity_desc",
+			expectedPacks: []pack.Pack{{Size: 5000, Quantity: 2}, {Size: 2000, Quantity: 1}, {Size: 250, Quantity: 1}},
+		},
+	}
+
+	for _, d := range data {
+		t.Run(fmt.Sprintf("with query: '%s'", d.query), func(t *testing.T) {
+			comp := TestPackComputer{
+				result: []pack.Pack{{Size: 2000, Quantity: 1}, {Size: 250, Quantity: 1}, {Size: 5000, Quantity: 2}},
+			}
+			repo := TestSuccessRepository{result: repository.Config{PackSizes: []int{250, 2000, 5000}}}
+			handler := NewHandler(&comp, &repo)
+
+			rr := httptest.NewRecorder()
+			req, err := http.NewRequest(http.MethodPost, Path+d.query, bytes.NewReader([]byte(`{"size": 12001}`)))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			handler.ServeHTTP(rr, req)
+
+			assertStatusOk(t, rr)
+			var order Order
+			if err = json.NewDecoder(rr.Body).Decode(&order); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(order.Packs, d.expectedPacks) {
+				t.Errorf("unexpected packs: got '%+v' want '%+v'", order.Packs, d.expectedPacks)
+			}
+		})
+	}
+}
+
+func TestServeHTTP_HandleGetOrder_SortPacks(t *testing.T) {
+	comp := TestPackComputer{}
+	repo := TestSuccessRepository{
+		orderResult: repository.Order{
+			ID:    1,
+			Size:  12001,
+			Packs: []repository.Pack{{Size: 250, Quantity: 1}, {Size: 5000, Quantity: 2}, {Size: 2000, Quantity: 1}},
+		},
+	}
+	handler := NewHandler(&comp, &repo)
+
+	rr := httptest.NewRecorder()
+	req, err := http.NewRequest(http.MethodGet, Path+"/1", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	handler.ServeHTTP(rr, req)
+
+	assertStatusOk(t, rr)
+	var order Order
+	if err = json.NewDecoder(rr.Body).Decode(&order); err != nil {
+		t.Fatal(err)
+	}
+	expectedPacks := []pack.Pack{{Size: 5000, Quantity: 2}, {Size: 2000, Quantity: 1}, {Size: 250, Quantity: 1}}
+	if !reflect.DeepEqual(order.Packs, expectedPacks) {
+		t.Errorf("unexpected packs: got '%+v' want '%+v'", order.Packs, expectedPacks)
+	}
+}
+
+func TestServeHTTP_InvalidSort(t *testing.T) {
+	data := []struct {
+		method  string
+		path    string
+		payload string
+	}{
+		{
+			method:  http.MethodPost,
+			path:    Path,
+			payload: `{"size": 251}`,
+		},
+		{
+			method:  http.MethodPost,
+			path:    Path,
+			payload: `{"lines": [{"quantity": 251}]}`,
+		},
+		{
+			method: http.MethodGet,
+			path:   Path + "/1",
+		},
+		{
+			method: http.MethodGet,
+			path:   Path,
+		},
+		{
+			method:  http.MethodPost,
+			path:    batchPath,
+			payload: `[251]`,
+		},
+		{
+			method: http.MethodPost,
+			path:   Path + "/1" + cancelOrderSuffix,
+		},
+	}
+
+	for _, d := range data {
+		t.Run(fmt.Sprintf("with %s %s", d.method, d.path), func(t *testing.T) {
+			comp := TestPackComputer{}
+			repo := TestSuccessRepository{}
+			handler := NewHandler(&comp, &repo)
+
+			rr := httptest.NewRecorder()
+			req, err := http.NewRequest(d.method, d.path+"?sort=random", bytes.NewReader([]byte(d.payload)))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			handler.ServeHTTP(rr, req)
+
+			assertErrorResponse(t, rr, http.StatusBadRequest, "invalid_sort")
+		})
+	}
+}
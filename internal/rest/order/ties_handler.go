@@ -0,0 +1,70 @@
+package order
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"packer/internal/rest/order/pack"
+	"packer/internal/rest/order/repository"
+)
+
+// maxTies is the maximum number of ties of the packs of an order, returned or sorted by a tie-breaker.
+const maxTies = 100
+
+// errInvalidTies is returned when the ties query parameter is not an integer between 1 and maxTies.
+var errInvalidTies = errors.New("invalid ties")
+
+// parseTies parses the number of ties returned with the packs of an order, zero if the ties query parameter is
+// missing. It returns errInvalidTies if it is invalid.
+func parseTies(query url.Values) (int, error) {
+	ties, err := parsePositiveIntParam(query, "ties", errInvalidTies)
+	if err != nil {
+		return 0, err
+	}
+	if ties > maxTies {
+		return 0, errInvalidTies
+	}
+	return ties, nil
+}
+
+// TiesComputer computes the ties of the packs of an order by the objective (see pack.ComputeTies), so that the
+// exact solvers' ties can be instrumented along with their packs.
+type TiesComputer interface {
+	ComputeTies(ctx context.Context, packSizes []int, stock map[int]int, packs []pack.Pack, objective pack.Objective, limit int) ([][]pack.Pack, error)
+}
+
+// tiesComputable returns true if the ties of the packs of the order can be computed: a single order computed by an
+// exact solver, so that the packs are the best ones.
+func tiesComputable(orderReq Request, opts computeOptions) bool {
+	return orderReq.Lines == nil && pack.SolverExact(opts.solver)
+}
+
+// breakTies computes the ties of the packs by the objective of the options, up to the number of ties asked for (or
+// maxTies with a tie-breaker only), sorted by their tie-breaker (pack.DefaultTieBreaker if empty).
+// The ties are computed by the solver of the options if it is a TiesComputer, by pack.ComputeTies otherwise.
+func (h *Handler) breakTies(ctx context.Context, cfg repository.Config, stock map[int]int, packs []pack.Pack, opts computeOptions) ([][]pack.Pack, error) {
+	objective, err := pack.NewObjective(opts.objective, cfg.PackSizes, configCost(cfg))
+	if err != nil {
+		return nil, err
+	}
+
+	limit := opts.ties
+	if limit == 0 {
+		limit = maxTies
+	}
+	computeTies := pack.ComputeTies
+	if computer, ok := h.solvers.get(opts.solver).(TiesComputer); ok {
+		computeTies = computer.ComputeTies
+	}
+	ties, err := computeTies(ctx, cfg.PackSizes, stock, packs, objective, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	tieBreaker := opts.tieBreaker
+	if tieBreaker == "" {
+		tieBreaker = pack.DefaultTieBreaker
+	}
+	pack.SortTies(ties, tieBreaker)
+	return ties, nil
+}
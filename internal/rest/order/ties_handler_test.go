@@ -0,0 +1,175 @@
+package order
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"packer/internal/rest/order/pack"
+	"packer/internal/rest/order/repository"
+	"reflect"
+	"testing"
+)
+
+// TestTiesComputer computes the packs like TestPackComputer and returns the result of the ties.
+type TestTiesComputer struct {
+	TestPackComputer
+	tiesResult  [][]pack.Pack
+	passedLimit int
+}
+
+func (comp *TestTiesComputer) ComputeTies(_ context.Context, _ []int, _ map[int]int, _ []pack.Pack, _ pack.Objective, limit int) ([][]pack.Pack, error) {
+	comp.passedLimit = limit
+	return comp.tiesResult, nil
+}
+
+func TestServeHTTP_HandleCreateOrder_Ties(t *testing.T) {
+	data := []struct {
+		query         string
+		expectedPacks []pack.Pack
+		expectedTies  [][]pack.Pack
+	}{
+		{
+			query:         "?ties=10",
+			expectedPacks: []pack.Pack{{Size: 4, Quantity: 1}, {Size: 2, Quantity: 1}},
+			expectedTies: [][]pack.Pack{
+				{{Size: 4, Quantity: 1}, {Size: 2, Quantity: 1}},
+				{{Size: 3, Quantity: 2}},
+			},
+		},
+		{
+			query:         "?ties=10&tie_breaker=fewer_sizes",
+			expectedPacks: []pack.Pack{{Size: 3, Quantity: 2}},
+			expectedTies: [][]pack.Pack{
+				{{Size: 3, Quantity: 2}},
+				{{Size: 4, Quantity: 1}, {Size: 2, Quantity: 1}},
+			},
+		},
+		{
+			query:         "?tie_breaker=fewer_sizes",
+			expectedPacks: []pack.Pack{{Size: 3, Quantity: 2}},
+		},
+	}
+
+	for _, d := range data {
+		t.Run(fmt.Sprintf("with query: '%s'", d.query), func(t *testing.T) {
+			comp := TestPackComputer{result: []pack.Pack{{Size: 3, Quantity: 2}}}
+			repo := TestSuccessRepository{result: repository.Config{PackSizes: []int{2, 3, 4}}}
+			handler := NewHandler(&comp, &repo)
+
+			rr := httptest.NewRecorder()
+			req, err := http.NewRequest(http.MethodPost, Path+d.query, bytes.NewReader([]byte(`{"size": 5}`)))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			handler.ServeHTTP(rr, req)
+
+			assertStatusOk(t, rr)
+			var order Order
+			if err = json.NewDecoder(rr.Body).Decode(&order); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(order.Packs, d.expectedPacks) {
+				t.Errorf("unexpected packs: got '%+v' want '%+v'", order.Packs, d.expectedPacks)
+			}
+			if !reflect.DeepEqual(order.Ties, d.expectedTies) {
+				t.Errorf("unexpected ties: got '%+v' want '%+v'", order.Ties, d.expectedTies)
+			}
+			savedPacks := make([]pack.Pack, len(repo.passedOrder.Packs))
+			for i, p := range repo.passedOrder.Packs {
+				savedPacks[i] = pack.Pack{Size: p.Size, Quantity: p.Quantity}
+			}
+			if !reflect.DeepEqual(savedPacks, d.expectedPacks) {
+				t.Errorf("unexpected saved packs: got '%+v' want '%+v'", savedPacks, d.expectedPacks)
+			}
+		})
+	}
+}
+
+func TestServeHTTP_HandleCreateOrder_TiesComputer(t *testing.T) {
+	ties := [][]pack.Pack{{{Size: 3, Quantity: 2}}}
+	comp := TestTiesComputer{TestPackComputer: TestPackComputer{result: ties[0]}, tiesResult: ties}
+	repo := TestSuccessRepository{result: repository.Config{PackSizes: []int{2, 3, 4}}}
+	handler := NewHandler(&comp, &repo)
+
+	rr := httptest.NewRecorder()
+	req, err := http.NewRequest(http.MethodPost, Path+"?ties=10", bytes.NewReader([]byte(`{"size": 5}`)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	handler.ServeHTTP(rr, req)
+
+	assertStatusOk(t, rr)
+	var order Order
+	if err = json.NewDecoder(rr.Body).Decode(&order); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(order.Ties, ties) || comp.passedLimit != 10 {
+		t.Errorf("unexpected ties of the solver: got '%+v' with limit '%d' want '%+v' with limit '%d'", order.Ties,
+			comp.passedLimit, ties, 10)
+	}
+}
+
+func TestServeHTTP_HandleCreateOrder_InvalidTies(t *testing.T) {
+	data := []struct {
+		query           string
+		payload         string
+		expectedErrCode string
+	}{
+		{
+			query:           "?ties=0",
+			payload:         `{"size": 5}`,
+			expectedErrCode: "invalid_ties",
+		},
+		{
+			query:           "?ties=101",
+			payload:         `{"size": 5}`,
+			expectedErrCode: "invalid_ties",
+		},
+		{
+			query:           "?ties=all",
+			payload:         `{"size": 5}`,
+			expectedErrCode: "invalid_ties",
+		},
+		{
+			query:           "?tie_breaker=random",
+			payload:         `{"size": 5}`,
+			expectedErrCode: "invalid_tie_breaker",
+		},
+		{
+			query:           "?ties=10&solver=greedy",
+			payload:         `{"size": 5}`,
+			expectedErrCode: "ties_unsupported",
+		},
+		{
+			query:           "?tie_breaker=larger_packs",
+			payload:         `{"lines": [{"quantity": 5}]}`,
+			expectedErrCode: "ties_unsupported",
+		},
+	}
+
+	for _, d := range data {
+		t.Run(fmt.Sprintf("with query: '%s'", d.query), func(t *testing.T) {
+			solvers := NewSolvers(map[string]PacksComputer{
+				pack.SolverDP:     &TestPackComputer{},
+				pack.SolverGreedy: &TestPackComputer{},
+			}, pack.SolverDP)
+			repo := TestSuccessRepository{}
+			handler := NewHandlerWithSolvers(solvers, &repo, DefaultQuoteTTL)
+
+			rr := httptest.NewRecorder()
+			req, err := http.NewRequest(http.MethodPost, Path+d.query, bytes.NewReader([]byte(d.payload)))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			handler.ServeHTTP(rr, req)
+
+			assertErrorResponse(t, rr, http.StatusBadRequest, d.expectedErrCode)
+		})
+	}
+}
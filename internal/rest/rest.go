@@ -1,59 +1,239 @@
 package rest
 
 import (
-	"errors"
-	"log"
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
 	"net/http"
+	"os"
+	"os/signal"
 	"packer/internal/rest/order"
 	"packer/internal/rest/order/pack"
 	"strconv"
+	"sync/atomic"
+	"syscall"
 	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
 )
 
 type Config struct {
 	ReadTimeout, WriteTimeout, IdleTimeout time.Duration
+	// QuoteTTL is how long the packs of a created order are reserved until it is confirmed.
+	QuoteTTL time.Duration
+	// ReaperInterval is how often the expired quotes release their reserved packs.
+	ReaperInterval time.Duration
+	// ShutdownDelay is how long the service keeps serving the requests, reported as not ready, before it shuts down.
+	ShutdownDelay time.Duration
+	// ShutdownTimeout is how long the in-flight requests are drained before they are cancelled.
+	ShutdownTimeout time.Duration
+	// HealthCheckTimeout is the time the readiness checks have to complete, DefaultHealthCheckTimeout if it is zero.
+	HealthCheckTimeout time.Duration
+	// DefaultSolver is the name of the solver used by the requests that do not name one (e.g. pack.SolverDP).
+	DefaultSolver string
+	// ConfigCacheTTL is how long a config found in effect is cached, zero to find it for every order. A config set on
+	// another instance may not be used for that long (see order.Cache).
+	ConfigCacheTTL time.Duration
+	// TableCacheBytes is the memory limit of the cached solution tables, zero to solve every order.
+	TableCacheBytes int
+}
+
+// Repository is the repository of the orders, the products and their inventory, which can be pinged to check that it
+// is available.
+type Repository interface {
+	order.Repository
+	order.ProductRepository
+	order.InventoryRepository
+	Ping(ctx context.Context) error
 }
 
 // ApiService handles incoming HTTP requests and can use an order's repository.
 type ApiService struct {
 	cfg  Config
-	repo order.Repository
+	repo Repository
+	// draining is true once the service stops accepting connections, while it drains the in-flight requests.
+	draining *atomic.Bool
+	cache    *order.Cache
+	registry *prometheus.Registry
+	metrics  *Metrics
+	tracing  *Tracing
+}
+
+func NewApiService(cfg Config, repo Repository) ApiService {
+	return NewApiServiceWithRegistry(cfg, repo, prometheus.NewRegistry())
 }
 
-func NewApiService(cfg Config, repo order.Repository) ApiService {
+// NewApiServiceWithRegistry creates a service whose metrics, and the ones of its cache, are registered in the registry
+// and served at /metrics along with the ones registered by the caller (e.g. the Go runtime's).
+func NewApiServiceWithRegistry(cfg Config, repo Repository, registry *prometheus.Registry) ApiService {
+	return NewApiServiceWithTracerProvider(cfg, repo, registry, noop.NewTracerProvider())
+}
+
+// NewApiServiceWithTracerProvider creates a service like NewApiServiceWithRegistry that traces the requests, the
+// solvers' computations and the configs' queries with the provider's tracers (see Tracing).
+func NewApiServiceWithTracerProvider(cfg Config, repo Repository, registry *prometheus.Registry, provider trace.TracerProvider) ApiService {
+	cache := order.NewCache(cfg.ConfigCacheTTL, cfg.TableCacheBytes)
+	registry.MustRegister(newCacheCollector(cache))
 	return ApiService{
-		cfg:  cfg,
-		repo: repo,
+		cfg:      cfg,
+		repo:     repo,
+		draining: &atomic.Bool{},
+		cache:    cache,
+		registry: registry,
+		metrics:  NewMetrics(registry),
+		tracing:  NewTracing(provider),
+	}
+}
+
+// Serve serves the requests at the port until the context is done or the process receives SIGINT or SIGTERM.
+// It then reports the service as not ready (see handleReadiness), waits for the shutdown delay so that the load
+// balancers stop sending requests, stops accepting connections and drains the in-flight requests. The requests still
+// in flight after the shutdown timeout are cancelled.
+// It returns an error if the service cannot serve the requests or drain them in time.
+func (svc *ApiService) Serve(ctx context.Context, port int) error {
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	listener, err := net.Listen("tcp", ":"+strconv.Itoa(port))
+	if err != nil {
+		return fmt.Errorf("unable to listen at port %d: %w", port, err)
 	}
+
+	reaper := order.NewQuoteReaper(svc.repo, svc.cfg.ReaperInterval)
+	go reaper.Run(ctx)
+
+	slog.Info("listening", "port", port)
+	return svc.serve(ctx, listener, svc.newHandler())
 }
 
-func (svc *ApiService) Serve(port int) {
-	s := svc.newServer(port)
+// serve serves the requests on the listener with the handler until the context is done, then drains them.
+func (svc *ApiService) serve(ctx context.Context, listener net.Listener, handler http.Handler) error {
+	// the requests' contexts are cancelled if they are still in flight after the shutdown timeout
+	requestsCtx, cancelRequests := context.WithCancel(context.Background())
+	defer cancelRequests()
+
+	s := svc.newServer(requestsCtx, handler)
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- s.Serve(listener)
+	}()
+
+	select {
+	case err := <-serveErr:
+		return fmt.Errorf("the api service stopped because an error ocurred: %w", err)
+	case <-ctx.Done():
+	}
+
+	svc.draining.Store(true)
+	if svc.cfg.ShutdownDelay > 0 {
+		slog.Info("shutting down", "delay", svc.cfg.ShutdownDelay.String())
+		time.Sleep(svc.cfg.ShutdownDelay)
+	}
 
-	log.Printf("listening at port %d...\n", port)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), svc.cfg.ShutdownTimeout)
+	defer cancel()
 
-	if err := s.ListenAndServe(); err != nil {
-		if !errors.Is(err, http.ErrServerClosed) {
-			log.Fatalf("the api service will be shutdown because an error ocurred: %v\n", err)
+	slog.Info("draining the in-flight requests")
+	if err := s.Shutdown(shutdownCtx); err != nil {
+		cancelRequests()
+		if closeErr := s.Close(); closeErr != nil {
+			slog.Error("unable to close the server", "error", closeErr)
 		}
+		return fmt.Errorf("unable to drain the in-flight requests: %w", err)
 	}
+	slog.Info("the in-flight requests have been drained")
+	return nil
 }
 
-func (svc *ApiService) newServer(port int) http.Server {
-	return http.Server{
-		Addr:         ":" + strconv.Itoa(port),
+func (svc *ApiService) newServer(ctx context.Context, handler http.Handler) *http.Server {
+	return &http.Server{
 		ReadTimeout:  svc.cfg.ReadTimeout,
 		WriteTimeout: svc.cfg.WriteTimeout,
 		IdleTimeout:  svc.cfg.IdleTimeout,
-		Handler:      svc.newServeMux(),
+		Handler:      handler,
+		BaseContext: func(net.Listener) context.Context {
+			return ctx
+		},
 	}
 }
 
+// newHandler serves the requests with the routes of newServeMux, traced and logged.
+func (svc *ApiService) newHandler() http.Handler {
+	return svc.tracing.Instrument(logRequests(svc.newServeMux()))
+}
+
 func (svc *ApiService) newServeMux() *http.ServeMux {
 	mux := http.NewServeMux()
-	computer := pack.NewComputer()
-	orderHandler := order.NewHandler(&computer, svc.repo)
-	mux.Handle(order.Path, http.StripPrefix(order.Path, &orderHandler))
-	mux.Handle(order.Path+"/", http.StripPrefix(order.Path, &orderHandler))
+	svc.handle(mux, livenessPath, http.HandlerFunc(svc.handleLiveness))
+	svc.handle(mux, readinessPath, http.HandlerFunc(svc.handleReadiness))
+	mux.Handle(metricsPath, promhttp.HandlerFor(svc.registry, promhttp.HandlerOpts{}))
+	repo := svc.tracing.InstrumentRepository(svc.metrics.InstrumentRepository(svc.repo))
+	orderHandler := order.NewHandlerWithCache(svc.newSolvers(), repo, svc.cfg.QuoteTTL, svc.cache)
+	svc.handlePrefix(mux, order.Path, &orderHandler)
+	productHandler := order.NewProductHandler(&orderHandler, repo)
+	svc.handlePrefix(mux, order.ProductsPath, &productHandler)
+	inventoryHandler := order.NewInventoryHandler(&orderHandler, repo)
+	svc.handlePrefix(mux, order.InventoryPath, &inventoryHandler)
 	return mux
 }
+
+// handle serves the requests of the path with the handler, instrumented with the path as route.
+func (svc *ApiService) handle(mux *http.ServeMux, path string, handler http.Handler) {
+	mux.Handle(path, svc.metrics.Instrument(path, handler))
+}
+
+// handlePrefix serves the requests of the path and its subpaths with the handler, without the path's prefix.
+func (svc *ApiService) handlePrefix(mux *http.ServeMux, path string, handler http.Handler) {
+	instrumented := svc.metrics.Instrument(path, http.StripPrefix(path, handler))
+	mux.Handle(path, instrumented)
+	mux.Handle(path+"/", instrumented)
+}
+
+func (svc *ApiService) newSolvers() order.Solvers {
+	computer := pack.NewComputer()
+	branchAndBound := pack.NewBranchAndBound()
+	greedy := pack.NewGreedy()
+	return order.NewSolvers(map[string]order.PacksComputer{
+		pack.SolverDP:             svc.instrumentPacksComputer(pack.SolverDP, &computer),
+		pack.SolverBranchAndBound: svc.instrumentPacksComputer(pack.SolverBranchAndBound, &branchAndBound),
+		pack.SolverGreedy:         svc.instrumentPacksComputer(pack.SolverGreedy, &greedy),
+	}, svc.cfg.DefaultSolver)
+}
+
+func (svc *ApiService) instrumentPacksComputer(solver string, computer order.PacksComputer) order.PacksComputer {
+	return svc.tracing.InstrumentPacksComputer(solver, svc.metrics.InstrumentPacksComputer(solver, computer))
+}
+
+// instrumentedSolver instruments all the methods a solver may have.
+type instrumentedSolver interface {
+	order.PacksComputer
+	order.PacksExplainer
+	order.TiesComputer
+}
+
+// withSolverMethodsOf returns the instrumented solver with only the optional methods of the computer it instruments,
+// so that it is an order.PacksExplainer or an order.TiesComputer only if the computer is one.
+func withSolverMethodsOf(instrumented instrumentedSolver, computer order.PacksComputer) order.PacksComputer {
+	_, explainer := computer.(order.PacksExplainer)
+	_, tiesComputer := computer.(order.TiesComputer)
+	switch {
+	case explainer && tiesComputer:
+		return instrumented
+	case explainer:
+		return struct {
+			order.PacksComputer
+			order.PacksExplainer
+		}{instrumented, instrumented}
+	case tiesComputer:
+		return struct {
+			order.PacksComputer
+			order.TiesComputer
+		}{instrumented, instrumented}
+	default:
+		return struct{ order.PacksComputer }{instrumented}
+	}
+}
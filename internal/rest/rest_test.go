@@ -0,0 +1,293 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"packer/internal/rest/order/pack"
+	"packer/internal/rest/order/repository"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestRepository is available and has the config of the default product without stock levels, unless it returns the
+// given errors.
+// Its other methods are not implemented.
+type TestRepository struct {
+	Repository
+	pingErr       error
+	findConfigErr error
+	// pingDelay is how long pinging takes, unless the context is done before.
+	pingDelay time.Duration
+}
+
+func (repo *TestRepository) Ping(ctx context.Context) error {
+	select {
+	case <-time.After(repo.pingDelay):
+		return repo.pingErr
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (repo *TestRepository) FindConfig(_ context.Context, product string, _ time.Time) (repository.Config, error) {
+	return repository.Config{Product: product, PackSizes: []int{250}}, repo.findConfigErr
+}
+
+func (repo *TestRepository) FindStockLevels(context.Context, []string) ([]repository.StockLevel, error) {
+	return nil, nil
+}
+
+func TestServe_DrainsSlowComputation(t *testing.T) {
+	cfg := Config{ShutdownDelay: 200 * time.Millisecond, ShutdownTimeout: 5 * time.Second}
+	svc := NewApiService(cfg, &TestRepository{})
+	started, release := make(chan struct{}), make(chan struct{})
+	mux := http.NewServeMux()
+	mux.HandleFunc(readinessPath, svc.handleReadiness)
+	mux.HandleFunc("/slow", func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		w.WriteHeader(http.StatusOK)
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	url, served := startServe(t, ctx, &svc, mux)
+
+	resp := make(chan *http.Response, 1)
+	go func() {
+		resp <- doGet(t, url+"/slow")
+	}()
+	<-started
+	cancel()
+
+	// the readiness fails while the slow computation is still running
+	if readiness := doGetReadiness(t, url); readiness.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("unexpected readiness status code: got '%d' want '%d'", readiness.StatusCode, http.StatusServiceUnavailable)
+	}
+	close(release)
+
+	if slowResp := <-resp; slowResp == nil || slowResp.StatusCode != http.StatusOK {
+		t.Errorf("unexpected response of the slow computation: got '%+v'", slowResp)
+	}
+	if err := <-served; err != nil {
+		t.Errorf("unexpected error: got '%v'", err)
+	}
+}
+
+func TestServe_CancelsComputationAfterShutdownTimeout(t *testing.T) {
+	svc := NewApiService(Config{ShutdownTimeout: 100 * time.Millisecond}, &TestRepository{})
+	started, computed := make(chan struct{}), make(chan error, 1)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/slow", func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		comp := pack.NewComputer()
+		// a computation that takes far longer than the shutdown timeout
+		var err error
+		for err == nil {
+			_, err = comp.ComputePacks(r.Context(), []int{3162, 3161}, 1_000_000_000)
+		}
+		computed <- err
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	url, served := startServe(t, ctx, &svc, mux)
+
+	go func() {
+		_, _ = http.Get(url + "/slow")
+	}()
+	<-started
+	cancel()
+
+	if err := <-served; !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("unexpected error: got '%v' want '%v'", err, context.DeadlineExceeded)
+	}
+	select {
+	case err := <-computed:
+		if !errors.Is(err, pack.ErrCancelled) {
+			t.Errorf("unexpected computation error: got '%v' want '%v'", err, pack.ErrCancelled)
+		}
+	case <-time.After(5 * time.Second):
+		t.Error("unexpected computation still running after the shutdown")
+	}
+}
+
+func TestHandleLiveness(t *testing.T) {
+	svc := NewApiService(Config{}, &TestRepository{pingErr: errors.New("connection refused")})
+
+	rr := httptest.NewRecorder()
+	svc.newServeMux().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, livenessPath, nil))
+
+	assertHealth(t, rr, http.StatusOK, Health{
+		Status: "ok",
+		Checks: map[string]HealthCheck{"server": {Status: "ok"}},
+	})
+}
+
+func TestHandleReadiness(t *testing.T) {
+	data := []struct {
+		description    string
+		repo           TestRepository
+		draining       bool
+		expectedStatus int
+		expectedHealth Health
+	}{
+		{
+			description:    "ready",
+			expectedStatus: http.StatusOK,
+			expectedHealth: Health{
+				Status: "ok",
+				Checks: map[string]HealthCheck{"database": {Status: "ok"}, "config": {Status: "ok"}, "draining": {Status: "ok"}},
+			},
+		},
+		{
+			description:    "database unavailable",
+			repo:           TestRepository{pingErr: errors.New("connection refused")},
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedHealth: Health{
+				Status: "fail",
+				Checks: map[string]HealthCheck{
+					"database": {Status: "fail"},
+					"config":   {Status: "ok"},
+					"draining": {Status: "ok"},
+				},
+			},
+		},
+		{
+			description:    "database too slow",
+			repo:           TestRepository{pingDelay: time.Minute},
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedHealth: Health{
+				Status: "fail",
+				Checks: map[string]HealthCheck{
+					"database": {Status: "fail"},
+					"config":   {Status: "ok"},
+					"draining": {Status: "ok"},
+				},
+			},
+		},
+		{
+			description:    "no config",
+			repo:           TestRepository{findConfigErr: repository.ErrNotFound},
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedHealth: Health{
+				Status: "fail",
+				Checks: map[string]HealthCheck{
+					"database": {Status: "ok"},
+					"config":   {Status: "fail"},
+					"draining": {Status: "ok"},
+				},
+			},
+		},
+		{
+			description:    "draining",
+			draining:       true,
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedHealth: Health{
+				Status: "fail",
+				Checks: map[string]HealthCheck{
+					"database": {Status: "ok"},
+					"config":   {Status: "ok"},
+					"draining": {Status: "fail"},
+				},
+			},
+		},
+	}
+
+	for _, d := range data {
+		t.Run(fmt.Sprintf("with %s", d.description), func(t *testing.T) {
+			svc := NewApiService(Config{HealthCheckTimeout: 50 * time.Millisecond}, &d.repo)
+			svc.draining.Store(d.draining)
+
+			rr := httptest.NewRecorder()
+			svc.newServeMux().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, readinessPath, nil))
+
+			assertHealth(t, rr, d.expectedStatus, d.expectedHealth)
+		})
+	}
+}
+
+func TestHandleReadiness_ErrorLogged(t *testing.T) {
+	logs := captureLogs(t)
+	svc := NewApiService(Config{}, &TestRepository{pingErr: errors.New("dial tcp 10.0.0.1:5432: connection refused")})
+
+	rr := httptest.NewRecorder()
+	svc.newServeMux().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, readinessPath, nil))
+
+	assertStatus(t, rr, http.StatusServiceUnavailable)
+	if strings.Contains(rr.Body.String(), "10.0.0.1") {
+		t.Errorf("unexpected error in the health: '%s'", rr.Body.String())
+	}
+	for _, expected := range []string{`"msg":"health check failed"`, `"check":"database"`, "10.0.0.1:5432"} {
+		if !strings.Contains(logs.String(), expected) {
+			t.Errorf("unexpected logs without '%s': '%s'", expected, logs.String())
+		}
+	}
+}
+
+// assertHealth asserts the status code and the health, regardless of the durations of its checks.
+func assertHealth(t *testing.T, rr *httptest.ResponseRecorder, expectedStatus int, expected Health) {
+	if rr.Code != expectedStatus {
+		t.Errorf("unexpected status code: got '%d' want '%d'", rr.Code, expectedStatus)
+	}
+
+	var health Health
+	if err := json.NewDecoder(rr.Body).Decode(&health); err != nil {
+		t.Fatal(err)
+	}
+	for name, check := range health.Checks {
+		check.DurationMs = 0
+		health.Checks[name] = check
+	}
+	if !reflect.DeepEqual(health, expected) {
+		t.Errorf("unexpected health: got '%+v' want '%+v'", health, expected)
+	}
+}
+
+// startServe serves the handler on a random port until the context is done. It returns the URL of the service and
+// the channel of the error returned by serve.
+func startServe(t *testing.T, ctx context.Context, svc *ApiService, handler http.Handler) (string, <-chan error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	served := make(chan error, 1)
+	go func() {
+		served <- svc.serve(ctx, listener, handler)
+	}()
+	return "http://" + listener.Addr().String(), served
+}
+
+func doGet(t *testing.T, url string) *http.Response {
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Error(err)
+		return nil
+	}
+	defer resp.Body.Close()
+
+	_, _ = io.Copy(io.Discard, resp.Body)
+	return resp
+}
+
+// doGetReadiness gets the readiness on a new connection until it is not ready, or for up to a second.
+func doGetReadiness(t *testing.T, url string) *http.Response {
+	client := http.Client{Transport: &http.Transport{DisableKeepAlives: true}}
+	deadline := time.Now().Add(time.Second)
+	for {
+		resp, err := client.Get(url + readinessPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_ = resp.Body.Close()
+		if resp.StatusCode != http.StatusOK || time.Now().After(deadline) {
+			return resp
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,174 @@
+package rest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"packer/internal/rest/order"
+	"packer/internal/rest/order/pack"
+	"packer/internal/rest/order/repository"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "packer/internal/rest"
+
+// Tracing traces the requests, the solvers' computations and the repository's queries as OpenTelemetry spans.
+type Tracing struct {
+	provider trace.TracerProvider
+	tracer   trace.Tracer
+}
+
+// NewTracing creates the tracing of the spans with the provider's tracers.
+func NewTracing(provider trace.TracerProvider) *Tracing {
+	return &Tracing{provider: provider, tracer: provider.Tracer(tracerName)}
+}
+
+// Instrument traces the requests served by the handler as server spans named by their method and route (the first
+// segment of their path), which continue the trace of their W3C traceparent header if any.
+func (t *Tracing) Instrument(handler http.Handler) http.Handler {
+	return otelhttp.NewHandler(handler, "",
+		otelhttp.WithTracerProvider(t.provider),
+		otelhttp.WithPropagators(propagation.TraceContext{}),
+		otelhttp.WithSpanNameFormatter(func(_ string, r *http.Request) string {
+			return r.Method + " " + route(r.URL.Path)
+		}),
+	)
+}
+
+// route returns the first segment of the path (e.g. /orders for /orders/42/confirm), so that the spans are not named
+// by the IDs of the paths.
+func route(path string) string {
+	segment, _, _ := strings.Cut(strings.TrimPrefix(path, "/"), "/")
+	return "/" + segment
+}
+
+// InstrumentPacksComputer traces the packs computed by the named solver as spans with the objective, the order size and
+// the pack count, and their candidates and ties if the computer computes them.
+// The instrumented computer is an order.PacksExplainer or an order.TiesComputer if the computer is one.
+func (t *Tracing) InstrumentPacksComputer(solver string, computer order.PacksComputer) order.PacksComputer {
+	return withSolverMethodsOf(&tracedPacksComputer{tracer: t.tracer, solver: solver, computer: computer}, computer)
+}
+
+type tracedPacksComputer struct {
+	tracer   trace.Tracer
+	solver   string
+	computer order.PacksComputer
+}
+
+func (c *tracedPacksComputer) ComputePacksWithObjective(ctx context.Context, packSizes []int, stock map[int]int, orderSize int, objective pack.Objective) ([]pack.Pack, error) {
+	ctx, span := c.start(ctx, "ComputePacks", pack.ObjectiveName(objective), packSizes,
+		attribute.Int("packer.order_size", orderSize))
+	defer span.End()
+
+	packs, err := c.computer.ComputePacksWithObjective(ctx, packSizes, stock, orderSize, objective)
+	if err != nil {
+		endSolver(span, err)
+		return nil, err
+	}
+
+	span.SetAttributes(attribute.Int("packer.pack_count", countPacks(packs)))
+	return packs, nil
+}
+
+// ComputeCandidates must only be called if the computer is an order.PacksExplainer (see withSolverMethodsOf).
+// The candidates are always ranked by the fewest items and then the fewest packs.
+func (c *tracedPacksComputer) ComputeCandidates(ctx context.Context, packSizes []int, stock map[int]int, orderSize int, limit int) ([]pack.Solution, error) {
+	ctx, span := c.start(ctx, "ComputeCandidates", pack.ObjectiveItemsThenPacks, packSizes,
+		attribute.Int("packer.order_size", orderSize), attribute.Int("packer.limit", limit))
+	defer span.End()
+
+	candidates, err := c.computer.(order.PacksExplainer).ComputeCandidates(ctx, packSizes, stock, orderSize, limit)
+	if err != nil {
+		endSolver(span, err)
+		return nil, err
+	}
+
+	span.SetAttributes(attribute.Int("packer.candidate_count", len(candidates)))
+	return candidates, nil
+}
+
+// ComputeTies must only be called if the computer is an order.TiesComputer (see withSolverMethodsOf).
+func (c *tracedPacksComputer) ComputeTies(ctx context.Context, packSizes []int, stock map[int]int, packs []pack.Pack, objective pack.Objective, limit int) ([][]pack.Pack, error) {
+	ctx, span := c.start(ctx, "ComputeTies", pack.ObjectiveName(objective), packSizes,
+		attribute.Int("packer.pack_count", countPacks(packs)), attribute.Int("packer.limit", limit))
+	defer span.End()
+
+	ties, err := c.computer.(order.TiesComputer).ComputeTies(ctx, packSizes, stock, packs, objective, limit)
+	if err != nil {
+		endSolver(span, err)
+		return nil, err
+	}
+
+	span.SetAttributes(attribute.Int("packer.tie_count", len(ties)))
+	return ties, nil
+}
+
+// start starts the span of a computation of the solver, with the objective, the pack sizes and the attributes.
+func (c *tracedPacksComputer) start(ctx context.Context, name, objective string, packSizes []int, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return c.tracer.Start(ctx, name, trace.WithAttributes(append(attrs,
+		attribute.String("packer.solver", c.solver),
+		attribute.String("packer.objective", objective),
+		attribute.IntSlice("packer.pack_sizes", packSizes),
+	)...))
+}
+
+func endSolver(span trace.Span, err error) {
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
+
+// InstrumentRepository traces the queries that find and set the configs as client spans with their SQL statement.
+// The errors expected by the callers (repository.ErrNotFound and repository.ErrVersionConflict) are not recorded as
+// errors.
+func (t *Tracing) InstrumentRepository(repo Repository) Repository {
+	return &tracedRepository{Repository: repo, tracer: t.tracer}
+}
+
+type tracedRepository struct {
+	Repository
+	tracer trace.Tracer
+}
+
+func (repo *tracedRepository) FindConfig(ctx context.Context, product string, at time.Time) (repository.Config, error) {
+	ctx, span := repo.startQuery(ctx, "FindConfig", repository.FindConfigStatement, product)
+	defer span.End()
+
+	cfg, err := repo.Repository.FindConfig(ctx, product, at)
+	endQuery(span, err)
+	return cfg, err
+}
+
+func (repo *tracedRepository) SetConfig(ctx context.Context, cfg repository.Config, versions []int64) (repository.Config, error) {
+	ctx, span := repo.startQuery(ctx, "SetConfig", repository.SetConfigStatement, cfg.Product)
+	defer span.End()
+
+	savedCfg, err := repo.Repository.SetConfig(ctx, cfg, versions)
+	endQuery(span, err)
+	return savedCfg, err
+}
+
+func (repo *tracedRepository) startQuery(ctx context.Context, name, statement, product string) (context.Context, trace.Span) {
+	return repo.tracer.Start(ctx, name, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(
+		semconv.DBSystemPostgreSQL,
+		semconv.DBStatement(statement),
+		attribute.String("packer.product", product),
+	))
+}
+
+func endQuery(span trace.Span, err error) {
+	if err == nil {
+		return
+	}
+	span.RecordError(err)
+	if !errors.Is(err, repository.ErrNotFound) && !errors.Is(err, repository.ErrVersionConflict) {
+		span.SetStatus(codes.Error, err.Error())
+	}
+}
@@ -0,0 +1,254 @@
+package rest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"packer/internal/rest/order"
+	"packer/internal/rest/order/pack"
+	"packer/internal/rest/order/repository"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	testTraceparent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	testTraceID     = "4bf92f3577b34da6a3ce929d0e0e4736"
+)
+
+func TestTracing_Request(t *testing.T) {
+	logs := captureLogs(t)
+	recorder, provider := newTestTracerProvider()
+	svc := NewApiServiceWithTracerProvider(Config{DefaultSolver: pack.SolverDP}, &TestRepository{}, prometheus.NewRegistry(), provider)
+
+	rr := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, order.Path, strings.NewReader(`{"size": 251, "as_of": "2024-01-01T00:00:00Z"}`))
+	req.Header.Set("traceparent", testTraceparent)
+	svc.newHandler().ServeHTTP(rr, req)
+
+	assertStatus(t, rr, http.StatusOK)
+	spans := spansByName(t, recorder, "POST /orders", "FindConfig", "ComputePacks")
+	server := spans["POST /orders"]
+	if server.SpanKind() != trace.SpanKindServer || server.Parent().SpanID().String() != "00f067aa0ba902b7" {
+		t.Errorf("unexpected server span: kind '%v' parent '%v'", server.SpanKind(), server.Parent().SpanID())
+	}
+	for name, span := range spans {
+		if span.SpanContext().TraceID().String() != testTraceID {
+			t.Errorf("unexpected trace of %s: got '%s' want '%s'", name, span.SpanContext().TraceID(), testTraceID)
+		}
+		if name != "POST /orders" && span.Parent().SpanID() != server.SpanContext().SpanID() {
+			t.Errorf("unexpected parent of %s: got '%s' want '%s'", name, span.Parent().SpanID(), server.SpanContext().SpanID())
+		}
+	}
+	assertSpanAttributes(t, spans["FindConfig"],
+		attribute.String("db.system", "postgresql"),
+		attribute.String("db.statement", repository.FindConfigStatement),
+		attribute.String("packer.product", repository.DefaultProduct),
+	)
+	assertSpanAttributes(t, spans["ComputePacks"],
+		attribute.String("packer.solver", pack.SolverDP),
+		attribute.String("packer.objective", pack.ObjectiveItemsThenPacks),
+		attribute.Int("packer.order_size", 251),
+		attribute.Int("packer.pack_count", 2),
+	)
+	assertLastLog(t, logs, map[string]any{"msg": "request", "trace_id": testTraceID})
+}
+
+func TestTracing_RequestCandidatesAndTies(t *testing.T) {
+	recorder, provider := newTestTracerProvider()
+	svc := NewApiServiceWithTracerProvider(Config{DefaultSolver: pack.SolverDP}, &TestRepository{}, prometheus.NewRegistry(), provider)
+
+	rr := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, order.Path+"?explain=true&ties=10",
+		strings.NewReader(`{"size": 251, "as_of": "2024-01-01T00:00:00Z"}`))
+	svc.newHandler().ServeHTTP(rr, req)
+
+	assertStatus(t, rr, http.StatusOK)
+	spans := spansByName(t, recorder, "POST /orders", "FindConfig", "ComputePacks", "ComputeCandidates", "ComputeTies")
+	for _, name := range []string{"ComputeCandidates", "ComputeTies"} {
+		if spans[name].Parent().SpanID() != spans["POST /orders"].SpanContext().SpanID() {
+			t.Errorf("unexpected parent of %s: got '%s'", name, spans[name].Parent().SpanID())
+		}
+	}
+	assertSpanAttributes(t, spans["ComputeCandidates"],
+		attribute.String("packer.solver", pack.SolverDP),
+		attribute.String("packer.objective", pack.ObjectiveItemsThenPacks),
+		attribute.Int("packer.order_size", 251),
+		attribute.Int("packer.limit", 6),
+	)
+	assertSpanAttributes(t, spans["ComputeTies"],
+		attribute.String("packer.solver", pack.SolverDP),
+		attribute.String("packer.objective", pack.ObjectiveItemsThenPacks),
+		attribute.Int("packer.pack_count", 2),
+		attribute.Int("packer.limit", 10),
+		attribute.Int("packer.tie_count", 1),
+	)
+}
+
+func TestTracing_Route(t *testing.T) {
+	data := []struct {
+		path     string
+		expected string
+	}{
+		{path: "/orders", expected: "/orders"},
+		{path: "/orders/", expected: "/orders"},
+		{path: "/orders/42/confirm", expected: "/orders"},
+		{path: "/products/sku-1/config", expected: "/products"},
+		{path: "/", expected: "/"},
+	}
+
+	for _, d := range data {
+		t.Run(fmt.Sprintf("with path %s", d.path), func(t *testing.T) {
+			if r := route(d.path); r != d.expected {
+				t.Errorf("unexpected route: got '%s' want '%s'", r, d.expected)
+			}
+		})
+	}
+}
+
+func TestTracing_InstrumentPacksComputer_Error(t *testing.T) {
+	recorder, provider := newTestTracerProvider()
+	computer := NewTracing(provider).InstrumentPacksComputer(pack.SolverGreedy, &TestPacksComputer{err: pack.ErrCancelled})
+
+	_, err := computer.ComputePacksWithObjective(context.Background(), []int{250}, nil, 251, nil)
+
+	if !errors.Is(err, pack.ErrCancelled) {
+		t.Errorf("unexpected error: got '%v' want '%v'", err, pack.ErrCancelled)
+	}
+	span := spansByName(t, recorder, "ComputePacks")["ComputePacks"]
+	if span.Status().Code != codes.Error {
+		t.Errorf("unexpected status: got '%v' want '%v'", span.Status().Code, codes.Error)
+	}
+	assertSpanAttributes(t, span, attribute.String("packer.solver", pack.SolverGreedy), attribute.Int("packer.order_size", 251))
+}
+
+func TestTracing_InstrumentPacksComputer_TiesError(t *testing.T) {
+	recorder, provider := newTestTracerProvider()
+	computer := pack.NewComputer()
+	traced := NewTracing(provider).InstrumentPacksComputer(pack.SolverDP, &computer)
+
+	_, err := traced.(order.TiesComputer).ComputeTies(context.Background(), []int{0}, nil, []pack.Pack{{Size: 3, Quantity: 2}},
+		pack.PacksThenItems{}, 10)
+
+	if !errors.Is(err, pack.ErrInvalidInput) {
+		t.Errorf("unexpected error: got '%v' want '%v'", err, pack.ErrInvalidInput)
+	}
+	span := spansByName(t, recorder, "ComputeTies")["ComputeTies"]
+	if span.Status().Code != codes.Error {
+		t.Errorf("unexpected status: got '%v' want '%v'", span.Status().Code, codes.Error)
+	}
+	assertSpanAttributes(t, span, attribute.String("packer.objective", pack.ObjectivePacksThenItems))
+}
+
+func TestTracing_InstrumentPacksComputer_Explainer(t *testing.T) {
+	_, provider := newTestTracerProvider()
+	tracing := NewTracing(provider)
+	computer := pack.NewComputer()
+	branchAndBound := pack.NewBranchAndBound()
+	greedy := pack.NewGreedy()
+	data := []struct {
+		solver               string
+		computer             order.PacksComputer
+		expectedExplainer    bool
+		expectedTiesComputer bool
+	}{
+		{solver: pack.SolverDP, computer: &computer, expectedExplainer: true, expectedTiesComputer: true},
+		{solver: pack.SolverBranchAndBound, computer: &branchAndBound, expectedTiesComputer: true},
+		{solver: pack.SolverGreedy, computer: &greedy},
+	}
+
+	for _, d := range data {
+		t.Run(fmt.Sprintf("with solver %s", d.solver), func(t *testing.T) {
+			traced := tracing.InstrumentPacksComputer(d.solver, d.computer)
+
+			if _, ok := traced.(order.PacksExplainer); ok != d.expectedExplainer {
+				t.Errorf("unexpected explainer: got '%t' want '%t'", ok, d.expectedExplainer)
+			}
+			if _, ok := traced.(order.TiesComputer); ok != d.expectedTiesComputer {
+				t.Errorf("unexpected ties computer: got '%t' want '%t'", ok, d.expectedTiesComputer)
+			}
+		})
+	}
+}
+
+func TestTracing_InstrumentRepository(t *testing.T) {
+	data := []struct {
+		err            error
+		expectedStatus codes.Code
+	}{
+		{err: nil, expectedStatus: codes.Unset},
+		{err: repository.ErrNotFound, expectedStatus: codes.Unset},
+		{err: repository.ErrVersionConflict, expectedStatus: codes.Unset},
+		{err: errors.New("connection refused"), expectedStatus: codes.Error},
+	}
+
+	for _, d := range data {
+		t.Run(fmt.Sprintf("with error %v", d.err), func(t *testing.T) {
+			recorder, provider := newTestTracerProvider()
+			repo := NewTracing(provider).InstrumentRepository(&TestConfigRepository{err: d.err})
+
+			_, _ = repo.FindConfig(context.Background(), "sku-1", time.Now())
+			_, _ = repo.SetConfig(context.Background(), repository.Config{Product: "sku-1"}, nil)
+
+			spans := spansByName(t, recorder, "FindConfig", "SetConfig")
+			for name, statement := range map[string]string{
+				"FindConfig": repository.FindConfigStatement,
+				"SetConfig":  repository.SetConfigStatement,
+			} {
+				if spans[name].SpanKind() != trace.SpanKindClient || spans[name].Status().Code != d.expectedStatus {
+					t.Errorf("unexpected %s span: kind '%v' status '%v'", name, spans[name].SpanKind(), spans[name].Status())
+				}
+				assertSpanAttributes(t, spans[name],
+					attribute.String("db.statement", statement),
+					attribute.String("packer.product", "sku-1"),
+				)
+			}
+		})
+	}
+}
+
+// newTestTracerProvider creates a provider whose spans are recorded in memory once they end.
+func newTestTracerProvider() (*tracetest.SpanRecorder, trace.TracerProvider) {
+	recorder := tracetest.NewSpanRecorder()
+	return recorder, sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+}
+
+// spansByName returns the ended spans by name, which must be the expected ones.
+func spansByName(t *testing.T, recorder *tracetest.SpanRecorder, expected ...string) map[string]sdktrace.ReadOnlySpan {
+	spans := make(map[string]sdktrace.ReadOnlySpan)
+	for _, span := range recorder.Ended() {
+		spans[span.Name()] = span
+	}
+	if len(spans) != len(expected) {
+		t.Errorf("unexpected spans: got '%d' want '%d'", len(spans), len(expected))
+	}
+	for _, name := range expected {
+		if _, ok := spans[name]; !ok {
+			t.Fatalf("unexpected spans without '%s'", name)
+		}
+	}
+	return spans
+}
+
+func assertSpanAttributes(t *testing.T, span sdktrace.ReadOnlySpan, expected ...attribute.KeyValue) {
+	attributes := make(map[attribute.Key]attribute.Value)
+	for _, attr := range span.Attributes() {
+		attributes[attr.Key] = attr.Value
+	}
+
+	for _, e := range expected {
+		if value, ok := attributes[e.Key]; !ok || value != e.Value {
+			t.Errorf("unexpected %s of the %s span: got '%v' want '%v'", e.Key, span.Name(), value.Emit(), e.Value.Emit())
+		}
+	}
+}
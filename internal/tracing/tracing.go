@@ -0,0 +1,62 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+const (
+	// ExporterOTLP exports the spans to an OTLP collector over HTTP, configured by the standard OTEL_EXPORTER_OTLP_*
+	// environment variables (e.g. OTEL_EXPORTER_OTLP_ENDPOINT).
+	ExporterOTLP = "otlp"
+	// ExporterStdout writes the spans as JSON.
+	ExporterStdout = "stdout"
+	// ExporterNone does not record the spans.
+	ExporterNone = "none"
+
+	serviceName = "packer"
+)
+
+// ValidExporter returns true if the exporter is ExporterOTLP, ExporterStdout or ExporterNone.
+func ValidExporter(exporter string) bool {
+	return exporter == ExporterOTLP || exporter == ExporterStdout || exporter == ExporterNone
+}
+
+// NewTracerProvider creates a provider of tracers whose spans are exported in batches by the exporter, ExporterStdout
+// writing them to w. The returned function flushes the spans and shuts the exporter down.
+func NewTracerProvider(ctx context.Context, exporter string, w io.Writer) (trace.TracerProvider, func(context.Context) error, error) {
+	var spanExporter sdktrace.SpanExporter
+	var err error
+	switch exporter {
+	case ExporterOTLP:
+		spanExporter, err = otlptracehttp.New(ctx)
+	case ExporterStdout:
+		spanExporter, err = stdouttrace.New(stdouttrace.WithWriter(w))
+	case ExporterNone:
+		return noop.NewTracerProvider(), func(context.Context) error { return nil }, nil
+	default:
+		return nil, nil, fmt.Errorf("unknown traces exporter: %s", exporter)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to create the %s traces exporter: %w", exporter, err)
+	}
+
+	// the service name can be overridden by the standard OTEL_SERVICE_NAME and OTEL_RESOURCE_ATTRIBUTES
+	res, err := resource.New(ctx, resource.WithSchemaURL(semconv.SchemaURL),
+		resource.WithAttributes(semconv.ServiceName(serviceName)), resource.WithFromEnv(), resource.WithTelemetrySDK())
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to create the traces resource: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(sdktrace.WithBatcher(spanExporter), sdktrace.WithResource(res))
+	return provider, provider.Shutdown, nil
+}
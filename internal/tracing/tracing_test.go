@@ -0,0 +1,63 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestNewTracerProvider_Stdout(t *testing.T) {
+	var buf bytes.Buffer
+	provider, shutdown, err := NewTracerProvider(context.Background(), ExporterStdout, &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, span := provider.Tracer("test").Start(context.Background(), "ComputePacks")
+	span.End()
+	if err = shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, expected := range []string{`"Name":"ComputePacks"`, `"Value":"packer"`} {
+		if !strings.Contains(buf.String(), expected) {
+			t.Errorf("unexpected exported spans without '%s': '%s'", expected, buf.String())
+		}
+	}
+}
+
+func TestNewTracerProvider_None(t *testing.T) {
+	var buf bytes.Buffer
+	provider, shutdown, err := NewTracerProvider(context.Background(), ExporterNone, &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, span := provider.Tracer("test").Start(context.Background(), "ComputePacks")
+	span.End()
+	if err = shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if span.IsRecording() || span.SpanContext().IsValid() {
+		t.Error("unexpected recorded span")
+	}
+	if buf.Len() != 0 {
+		t.Errorf("unexpected exported spans: '%s'", buf.String())
+	}
+}
+
+func TestNewTracerProvider_UnknownExporter(t *testing.T) {
+	if _, _, err := NewTracerProvider(context.Background(), "jaeger", &bytes.Buffer{}); err == nil {
+		t.Error("unexpected provider of an unknown exporter")
+	}
+}
+
+func TestValidExporter(t *testing.T) {
+	for exporter, expected := range map[string]bool{ExporterOTLP: true, ExporterStdout: true, ExporterNone: true, "": false, "jaeger": false} {
+		if ValidExporter(exporter) != expected {
+			t.Errorf("unexpected validity of '%s': got '%t' want '%t'", exporter, !expected, expected)
+		}
+	}
+}
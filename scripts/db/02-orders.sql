@@ -0,0 +1,7 @@
+CREATE TABLE orders (
+    id bigint GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    size bigint NOT NULL,
+    packs jsonb NOT NULL,
+    pack_sizes integer[] NOT NULL,
+    created_at timestamptz NOT NULL DEFAULT now()
+);
@@ -0,0 +1,7 @@
+-- Supports the stable (created_at, id) sort order and the keyset pagination of the orders list.
+CREATE INDEX orders_created_at_id_idx ON orders (created_at DESC, id DESC);
+
+CREATE INDEX orders_size_idx ON orders (size);
+
+-- Supports the pack size filter (packs @> '[{"size": 500}]').
+CREATE INDEX orders_packs_idx ON orders USING gin (packs jsonb_path_ops);
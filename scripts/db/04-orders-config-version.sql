@@ -0,0 +1,3 @@
+ALTER TABLE orders_config
+    ADD COLUMN version bigint NOT NULL DEFAULT 1,
+    ADD COLUMN updated_at timestamptz NOT NULL DEFAULT now();
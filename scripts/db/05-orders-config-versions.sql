@@ -0,0 +1,14 @@
+-- Every config change is kept as an immutable version, orders_config only points to the current one.
+CREATE TABLE orders_config_versions (
+    version bigint PRIMARY KEY,
+    pack_sizes integer[] NOT NULL,
+    created_at timestamptz NOT NULL DEFAULT now(),
+    author text NOT NULL,
+    reason text NOT NULL DEFAULT ''
+);
+
+INSERT INTO orders_config_versions (version, pack_sizes, created_at, author, reason)
+SELECT version, pack_sizes, updated_at, 'system', 'Initial config.' FROM orders_config;
+
+-- The config version each order was computed with, so that it can be reproduced.
+ALTER TABLE orders ADD COLUMN config_version bigint REFERENCES orders_config_versions (version);
@@ -0,0 +1,7 @@
+-- A version is in effect from effective_from until the next version (by effective_from, then version) takes effect,
+-- so that pack size changes can be scheduled.
+ALTER TABLE orders_config_versions ADD COLUMN effective_from timestamptz;
+UPDATE orders_config_versions SET effective_from = created_at;
+ALTER TABLE orders_config_versions ALTER COLUMN effective_from SET NOT NULL;
+
+CREATE INDEX orders_config_versions_effective_from_idx ON orders_config_versions (effective_from DESC, version DESC);
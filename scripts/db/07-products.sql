@@ -0,0 +1,30 @@
+-- Every product ships in its own pack sizes, so the config and its history are kept per product.
+-- The config that existed before products belongs to the default product.
+CREATE TABLE products (
+    sku text PRIMARY KEY,
+    name text NOT NULL,
+    created_at timestamptz NOT NULL DEFAULT now()
+);
+
+INSERT INTO products (sku, name) VALUES ('default', 'Default product');
+
+ALTER TABLE orders_config ADD COLUMN product_sku text NOT NULL DEFAULT 'default' REFERENCES products (sku) ON DELETE CASCADE;
+ALTER TABLE orders_config ALTER COLUMN product_sku DROP DEFAULT;
+ALTER TABLE orders_config ADD PRIMARY KEY (product_sku);
+
+-- Versions are numbered per product.
+ALTER TABLE orders DROP CONSTRAINT orders_config_version_fkey;
+ALTER TABLE orders_config_versions ADD COLUMN product_sku text NOT NULL DEFAULT 'default' REFERENCES products (sku) ON DELETE CASCADE;
+ALTER TABLE orders_config_versions ALTER COLUMN product_sku DROP DEFAULT;
+ALTER TABLE orders_config_versions DROP CONSTRAINT orders_config_versions_pkey;
+ALTER TABLE orders_config_versions ADD PRIMARY KEY (product_sku, version);
+
+DROP INDEX orders_config_versions_effective_from_idx;
+CREATE INDEX orders_config_versions_effective_from_idx ON orders_config_versions (product_sku, effective_from DESC, version DESC);
+
+-- Products with orders cannot be deleted.
+ALTER TABLE orders ADD COLUMN product_sku text NOT NULL DEFAULT 'default' REFERENCES products (sku);
+ALTER TABLE orders ALTER COLUMN product_sku DROP DEFAULT;
+ALTER TABLE orders ADD FOREIGN KEY (product_sku, config_version) REFERENCES orders_config_versions (product_sku, version);
+
+CREATE INDEX orders_product_sku_idx ON orders (product_sku);
@@ -0,0 +1,8 @@
+-- The stock of every pack size of a product. Pack sizes without a stock level are unlimited.
+CREATE TABLE inventory (
+    product_sku text NOT NULL REFERENCES products (sku) ON DELETE CASCADE,
+    pack_size integer NOT NULL CHECK (pack_size > 0),
+    quantity integer NOT NULL CHECK (quantity >= 0),
+    updated_at timestamptz NOT NULL DEFAULT now(),
+    PRIMARY KEY (product_sku, pack_size)
+);
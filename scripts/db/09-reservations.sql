@@ -0,0 +1,21 @@
+-- Orders are quotes that reserve their packs in stock until they are confirmed, cancelled or expired.
+-- The orders that existed before are confirmed.
+ALTER TABLE orders ADD COLUMN status text NOT NULL DEFAULT 'confirmed'
+    CHECK (status IN ('quoted', 'confirmed', 'cancelled', 'expired'));
+ALTER TABLE orders ALTER COLUMN status DROP DEFAULT;
+ALTER TABLE orders ADD COLUMN expires_at timestamptz;
+
+CREATE INDEX orders_quoted_expires_at_idx ON orders (expires_at) WHERE status = 'quoted';
+
+-- The packs reserved by the quoted orders. The packs available are the packs in stock minus the reserved ones.
+-- Deleting a stock level makes its pack size unlimited, so its reservations are deleted too.
+CREATE TABLE reservations (
+    order_id bigint NOT NULL REFERENCES orders (id) ON DELETE CASCADE,
+    product_sku text NOT NULL,
+    pack_size integer NOT NULL,
+    quantity integer NOT NULL CHECK (quantity > 0),
+    PRIMARY KEY (order_id, pack_size),
+    FOREIGN KEY (product_sku, pack_size) REFERENCES inventory (product_sku, pack_size) ON DELETE CASCADE
+);
+
+CREATE INDEX reservations_product_sku_pack_size_idx ON reservations (product_sku, pack_size);
@@ -0,0 +1,16 @@
+-- The unit cost of every pack size (a JSON object by pack size) and the handling fee per order, used by the cost
+-- objective. Configs without costs cannot be used with it.
+ALTER TABLE orders_config
+    ADD COLUMN pack_costs jsonb,
+    ADD COLUMN handling_fee integer NOT NULL DEFAULT 0 CHECK (handling_fee >= 0);
+ALTER TABLE orders_config_versions
+    ADD COLUMN pack_costs jsonb,
+    ADD COLUMN handling_fee integer NOT NULL DEFAULT 0 CHECK (handling_fee >= 0);
+
+-- The objective every order was computed with and its cost, if its config had costs.
+-- The orders that existed before were computed with the fewest items and then the fewest packs.
+ALTER TABLE orders
+    ADD COLUMN objective text NOT NULL DEFAULT 'items_then_packs'
+        CHECK (objective IN ('items_then_packs', 'cost', 'packs_then_items')),
+    ADD COLUMN cost bigint;
+ALTER TABLE orders ALTER COLUMN objective DROP DEFAULT;
@@ -0,0 +1,5 @@
+-- The solver every order was computed with. The orders that existed before were computed by the dynamic programming
+-- solver.
+ALTER TABLE orders
+    ADD COLUMN solver text NOT NULL DEFAULT 'dp' CHECK (solver IN ('dp', 'branch_and_bound', 'greedy'));
+ALTER TABLE orders ALTER COLUMN solver DROP DEFAULT;